@@ -0,0 +1,103 @@
+package substrate
+
+import "github.com/centrifuge/go-substrate-rpc-client/scale"
+
+// AccountInfo is the value of System.Account, the nonce and reference counters of an account and its balances
+type AccountInfo struct {
+	Nonce     uint32
+	Consumers uint32
+	Providers uint32
+	Data      AccountData
+}
+
+func (a *AccountInfo) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&a.Nonce)
+	if err != nil {
+		return err
+	}
+	err = decoder.Decode(&a.Consumers)
+	if err != nil {
+		return err
+	}
+	err = decoder.Decode(&a.Providers)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(&a.Data)
+}
+
+func (a AccountInfo) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(a.Nonce)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(a.Consumers)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(a.Providers)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(a.Data)
+}
+
+// AccountInfoV11 is the value of System.Account on substrate 2.0 runtimes, which have V11 metadata. They count the
+// references to an account in a single counter instead of consumers and providers.
+type AccountInfoV11 struct {
+	Nonce    uint32
+	RefCount uint32
+	Data     AccountData
+}
+
+func (a *AccountInfoV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&a.Nonce)
+	if err != nil {
+		return err
+	}
+	err = decoder.Decode(&a.RefCount)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(&a.Data)
+}
+
+func (a AccountInfoV11) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(a.Nonce)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(a.RefCount)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(a.Data)
+}
+
+// AccountData are the balances of an account, Free is the balance that can be transferred
+type AccountData struct {
+	Free       U128
+	Reserved   U128
+	MiscFrozen U128
+	FeeFrozen  U128
+}
+
+func (a *AccountData) Decode(decoder scale.Decoder) error {
+	for _, v := range []*U128{&a.Free, &a.Reserved, &a.MiscFrozen, &a.FeeFrozen} {
+		err := decoder.Decode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a AccountData) Encode(encoder scale.Encoder) error {
+	for _, v := range []U128{a.Free, a.Reserved, a.MiscFrozen, a.FeeFrozen} {
+		err := encoder.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
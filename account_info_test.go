@@ -0,0 +1,66 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountInfo_EncodeDecode(t *testing.T) {
+	// nonce 5, no consumers, 1 provider, 10^12 free and 1 reserved
+	b := hexutil.MustDecode("0x05000000" + "00000000" + "01000000" +
+		"0010a5d4e8000000000000000000000001000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000")
+
+	var info AccountInfo
+	err := scale.NewDecoder(bytes.NewReader(b)).Decode(&info)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(5), info.Nonce)
+	assert.Equal(t, uint32(0), info.Consumers)
+	assert.Equal(t, uint32(1), info.Providers)
+	assert.Equal(t, int64(1000000000000), info.Data.Free.Int64())
+	assert.Equal(t, int64(1), info.Data.Reserved.Int64())
+	assert.Equal(t, int64(0), info.Data.MiscFrozen.Int64())
+	assert.Equal(t, int64(0), info.Data.FeeFrozen.Int64())
+
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(info)
+	assert.NoError(t, err)
+	assert.Equal(t, b, buf.Bytes())
+
+	err = scale.NewDecoder(bytes.NewReader(b[:60])).Decode(&info)
+	assert.Error(t, err)
+
+	buf.Reset()
+	err = scale.NewEncoder(&buf).Encode(AccountInfo{Data: AccountData{Free: NewU128(big.NewInt(-1))}})
+	assert.Error(t, err)
+}
+
+func TestAccountInfoV11_EncodeDecode(t *testing.T) {
+	// nonce 5, 2 references, 10^12 free
+	b := hexutil.MustDecode("0x05000000" + "02000000" +
+		"0010a5d4e8000000000000000000000000000000000000000000000000000000" +
+		"0000000000000000000000000000000000000000000000000000000000000000")
+
+	var info AccountInfoV11
+	err := scale.NewDecoder(bytes.NewReader(b)).Decode(&info)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(5), info.Nonce)
+	assert.Equal(t, uint32(2), info.RefCount)
+	assert.Equal(t, int64(1000000000000), info.Data.Free.Int64())
+	assert.Equal(t, int64(0), info.Data.Reserved.Int64())
+
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(info)
+	assert.NoError(t, err)
+	assert.Equal(t, b, buf.Bytes())
+
+	err = scale.NewDecoder(bytes.NewReader(b[:68])).Decode(&info)
+	assert.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package substrate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// ApplyExtrinsicResult is the result of system_dryRun. An extrinsic is either rejected by the transaction validity
+// checks (IsValidityError), or it is included in a block and its dispatch succeeds or fails (DispatchOutcome).
+type ApplyExtrinsicResult struct {
+	IsDispatchOutcome bool
+	AsDispatchOutcome DispatchResult
+	IsValidityError   bool
+	AsValidityError   TransactionValidityError
+}
+
+func (a *ApplyExtrinsicResult) Decode(decoder scale.Decoder) error {
+	err := decoder.DecodeResult(&a.IsDispatchOutcome, &a.AsDispatchOutcome, &a.AsValidityError)
+	if err != nil {
+		return err
+	}
+	a.IsValidityError = !a.IsDispatchOutcome
+	return nil
+}
+
+func (a ApplyExtrinsicResult) Encode(encoder scale.Encoder) error {
+	if !a.IsDispatchOutcome && !a.IsValidityError {
+		return errors.New("apply extrinsic result not set")
+	}
+	return encoder.EncodeResult(a.IsDispatchOutcome, a.AsDispatchOutcome, a.AsValidityError)
+}
+
+// TransactionValidityError is the reason an extrinsic was rejected before it was dispatched
+type TransactionValidityError struct {
+	IsInvalid bool
+	AsInvalid InvalidTransaction
+	IsUnknown bool
+	AsUnknown UnknownTransaction
+}
+
+func (t *TransactionValidityError) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		t.IsInvalid = true
+		return decoder.Decode(&t.AsInvalid)
+	case 1:
+		t.IsUnknown = true
+		return decoder.Decode(&t.AsUnknown)
+	}
+	return fmt.Errorf("unknown transaction validity error %d", b)
+}
+
+func (t TransactionValidityError) Encode(encoder scale.Encoder) error {
+	switch {
+	case t.IsInvalid:
+		err := encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(t.AsInvalid)
+	case t.IsUnknown:
+		err := encoder.PushByte(1)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(t.AsUnknown)
+	}
+	return errors.New("transaction validity error not set")
+}
+
+// InvalidTransaction is the reason an extrinsic is invalid, eg: IsStale for an outdated nonce and IsPayment if the
+// fees can't be paid
+type InvalidTransaction struct {
+	IsCall              bool
+	IsPayment           bool
+	IsFuture            bool
+	IsStale             bool
+	IsBadProof          bool
+	IsAncientBirthBlock bool
+	IsExhaustsResources bool
+	IsCustom            bool
+	AsCustom            uint8
+	IsBadMandatory      bool
+	IsMandatoryDispatch bool
+}
+
+func (i *InvalidTransaction) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		i.IsCall = true
+	case 1:
+		i.IsPayment = true
+	case 2:
+		i.IsFuture = true
+	case 3:
+		i.IsStale = true
+	case 4:
+		i.IsBadProof = true
+	case 5:
+		i.IsAncientBirthBlock = true
+	case 6:
+		i.IsExhaustsResources = true
+	case 7:
+		i.IsCustom = true
+		return decoder.Decode(&i.AsCustom)
+	case 8:
+		i.IsBadMandatory = true
+	case 9:
+		i.IsMandatoryDispatch = true
+	default:
+		return fmt.Errorf("unknown invalid transaction %d", b)
+	}
+	return nil
+}
+
+func (i InvalidTransaction) Encode(encoder scale.Encoder) error {
+	switch {
+	case i.IsCall:
+		return encoder.PushByte(0)
+	case i.IsPayment:
+		return encoder.PushByte(1)
+	case i.IsFuture:
+		return encoder.PushByte(2)
+	case i.IsStale:
+		return encoder.PushByte(3)
+	case i.IsBadProof:
+		return encoder.PushByte(4)
+	case i.IsAncientBirthBlock:
+		return encoder.PushByte(5)
+	case i.IsExhaustsResources:
+		return encoder.PushByte(6)
+	case i.IsCustom:
+		return encoder.Write([]byte{7, i.AsCustom})
+	case i.IsBadMandatory:
+		return encoder.PushByte(8)
+	case i.IsMandatoryDispatch:
+		return encoder.PushByte(9)
+	}
+	return errors.New("invalid transaction not set")
+}
+
+// UnknownTransaction is the reason the validity of an extrinsic could not be determined
+type UnknownTransaction struct {
+	IsCannotLookup        bool
+	IsNoUnsignedValidator bool
+	IsCustom              bool
+	AsCustom              uint8
+}
+
+func (u *UnknownTransaction) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		u.IsCannotLookup = true
+	case 1:
+		u.IsNoUnsignedValidator = true
+	case 2:
+		u.IsCustom = true
+		return decoder.Decode(&u.AsCustom)
+	default:
+		return fmt.Errorf("unknown variant %d of UnknownTransaction", b)
+	}
+	return nil
+}
+
+func (u UnknownTransaction) Encode(encoder scale.Encoder) error {
+	switch {
+	case u.IsCannotLookup:
+		return encoder.PushByte(0)
+	case u.IsNoUnsignedValidator:
+		return encoder.PushByte(1)
+	case u.IsCustom:
+		return encoder.Write([]byte{2, u.AsCustom})
+	}
+	return errors.New("unknown transaction not set")
+}
@@ -0,0 +1,44 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyExtrinsicResult_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		result  ApplyExtrinsicResult
+		encoded []byte
+	}{
+		{ApplyExtrinsicResult{IsDispatchOutcome: true, AsDispatchOutcome: DispatchResult{IsOk: true}}, []byte{0, 0}},
+		{ApplyExtrinsicResult{IsDispatchOutcome: true, AsDispatchOutcome: DispatchResult{
+			AsError: DispatchError{IsModule: true, AsModule: ModuleError{Index: 5, Error: 2}}}}, []byte{0, 1, 3, 5, 2}},
+		{ApplyExtrinsicResult{IsValidityError: true, AsValidityError: TransactionValidityError{
+			IsInvalid: true, AsInvalid: InvalidTransaction{IsStale: true}}}, []byte{1, 0, 3}},
+		{ApplyExtrinsicResult{IsValidityError: true, AsValidityError: TransactionValidityError{
+			IsInvalid: true, AsInvalid: InvalidTransaction{IsCustom: true, AsCustom: 42}}}, []byte{1, 0, 7, 42}},
+		{ApplyExtrinsicResult{IsValidityError: true, AsValidityError: TransactionValidityError{
+			IsUnknown: true, AsUnknown: UnknownTransaction{IsNoUnsignedValidator: true}}}, []byte{1, 1, 1}},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.result)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, buf.Bytes())
+
+		var decoded ApplyExtrinsicResult
+		err = scale.NewDecoder(bytes.NewReader(test.encoded)).Decode(&decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, test.result, decoded)
+	}
+}
+
+func TestApplyExtrinsicResult_Decode_unknown(t *testing.T) {
+	var decoded ApplyExtrinsicResult
+	err := scale.NewDecoder(bytes.NewReader([]byte{1, 0, 10})).Decode(&decoded)
+	assert.EqualError(t, err, "unknown invalid transaction 10")
+}
@@ -2,30 +2,49 @@ package substrate
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
 	"os/exec"
+	"strings"
 
 	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
+// Error codes of author_submitExtrinsic, see jsonrpc.RPCError. The data of the error holds the reason, eg:
+// "Transaction is outdated" for an invalid transaction with a stale nonce.
+const (
+	ErrCodeInvalidTransaction = 1010
+	ErrCodeUnknownTransaction = 1011
+	// ErrCodeTemporarilyBanned is returned for extrinsics that were recently rejected or dropped from the pool
+	ErrCodeTemporarilyBanned = 1012
+	// ErrCodeAlreadyImported is returned if the same extrinsic is already in the pool
+	ErrCodeAlreadyImported = 1013
+	// ErrCodeTooLowPriority is returned if an extrinsic with the same nonce and a higher priority is in the pool
+	ErrCodeTooLowPriority = 1014
+)
+
+// extrinsicBitSigned is set in the version byte of signed extrinsics
+const extrinsicBitSigned = 0x80
+
 const (
 	Alice       = "//Alice"
 	AlicePubKey = "0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"
 )
 
-type MortalEra struct {
-	Period uint64
-	Phase  uint64
-}
-
 type ExtrinsicSignature struct {
 	SignatureOptional uint8
 	Signer            Address
 	Signature         Signature
 	Nonce             uint64
-	Era               uint8 // era enum
+	Era               ExtrinsicEra
+
+	// UseMultiAddress encodes and decodes the signer as MultiAddress, as newer runtimes expect it
+	UseMultiAddress bool
 }
 
 func NewExtrinsicSignature(signature Signature, Nonce uint64) ExtrinsicSignature {
@@ -38,9 +57,17 @@ func (e *ExtrinsicSignature) Decode(decoder scale.Decoder) error {
 		return err
 	}
 
-	b, _ := decoder.ReadOneByte()
-	// need to add other address representations from Address.decodeAddress
-	if b == 255 {
+	if e.UseMultiAddress {
+		var signer MultiAddress
+		err = decoder.Decode(&signer)
+		if err != nil {
+			return err
+		}
+		if !signer.IsID {
+			return errors.New("only account ids are supported as multi address signer")
+		}
+		e.Signer = Address{PubKey: signer.AsID}
+	} else {
 		e.Signer = Address{}
 		err = decoder.Decode(&e.Signer)
 		if err != nil {
@@ -53,8 +80,10 @@ func (e *ExtrinsicSignature) Decode(decoder scale.Decoder) error {
 	if err != nil {
 		return err
 	}
-	e.Nonce, _ = decoder.DecodeUintCompact()
-	// TODO assuming immortal for now
+	e.Nonce, err = decoder.DecodeUintCompact()
+	if err != nil {
+		return err
+	}
 	err = decoder.Decode(&e.Era)
 	if err != nil {
 		return err
@@ -66,17 +95,23 @@ func (e *ExtrinsicSignature) Decode(decoder scale.Decoder) error {
 func (e ExtrinsicSignature) Encode(encoder scale.Encoder) error {
 	// always signed
 	e.SignatureOptional = 129
-	// TODO remove hard coded accounts info
-	s, _ := hexutil.Decode(AlicePubKey)
-	e.Signer = *NewAddress(s)
-	e.Era = 0
+	// TODO remove hard coded accounts info, the subkey path always signs as Alice
+	if e.Signer == (Address{}) {
+		s, _ := hexutil.Decode(AlicePubKey)
+		e.Signer = *NewAddress(s)
+	}
+	e.Era = NewImmortalEra()
 
 	err := encoder.Encode(e.SignatureOptional)
 	if err != nil {
 		return err
 	}
 
-	err = encoder.Encode(&e.Signer)
+	if e.UseMultiAddress {
+		err = encoder.Encode(NewMultiAddressFromAccountID(e.Signer.PubKey[:]))
+	} else {
+		err = encoder.Encode(&e.Signer)
+	}
 	if err != nil {
 		return err
 	}
@@ -100,10 +135,9 @@ func (e ExtrinsicSignature) Encode(encoder scale.Encoder) error {
 }
 
 type SignaturePayload struct {
-	Nonce  uint64
-	Method Method
-	Era    uint8 // era enum
-	//ImmortalEra []byte
+	Nonce      uint64
+	Method     Method
+	Era        ExtrinsicEra
 	PriorBlock [32]byte
 }
 
@@ -120,7 +154,6 @@ func (e SignaturePayload) Encode(encoder scale.Encoder) error {
 	if err != nil {
 		return err
 	}
-	// encoder.Encode(e.ImmortalEra) // always immortal
 	err = encoder.Write(e.PriorBlock[:])
 	if err != nil {
 		return err
@@ -139,7 +172,56 @@ type Method struct {
 }
 
 func NewMethod(name string, a Args, metadata MetadataVersioned) Method {
-	return Method{CallIndex: metadata.Metadata.MethodIndex(name), Args: a}
+	return Method{CallIndex: metadata.MethodIndex(name), Args: a}
+}
+
+// DecodeCallArgs decodes the arguments of a call with raw arguments, eg: a call of an extrinsic returned by
+// Block.DecodeExtrinsics, into targets. The targets are pointers to the Go types of the arguments in the order the
+// metadata declares them, eg: *Address and *UCompact for Balances.transfer. It fails if the number of targets doesn't
+// match the arguments of the call or if not all bytes are consumed.
+func DecodeCallArgs(meta *MetadataVersioned, call Method, targets ...interface{}) error {
+	_, err := decodeCallArgs(meta, call, targets, true)
+	return err
+}
+
+// DecodeCallArgsPrefix decodes the first arguments of a call with raw arguments into targets, like DecodeCallArgs,
+// and returns the raw bytes of the remaining arguments. There may be fewer targets than arguments, eg: to decode the
+// known arguments of a call that gained arguments in a later runtime version and ignore the others.
+func DecodeCallArgsPrefix(meta *MetadataVersioned, call Method, targets ...interface{}) (Encoded, error) {
+	return decodeCallArgs(meta, call, targets, false)
+}
+
+// decodeCallArgs decodes the first arguments of the call into targets and returns the remaining bytes, all requires
+// a target for every argument and no remaining bytes
+func decodeCallArgs(meta *MetadataVersioned, call Method, targets []interface{}, all bool) (Encoded, error) {
+	name, args, err := meta.FindCallArgs(call.CallIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targets) > len(args) || (all && len(targets) != len(args)) {
+		return nil, fmt.Errorf("%v has %v arguments, got %v targets", name, len(args), len(targets))
+	}
+
+	raw, ok := call.Args.(Encoded)
+	if !ok {
+		return nil, fmt.Errorf("the arguments of %v are not raw, got %T", name, call.Args)
+	}
+
+	r := bytes.NewReader(raw)
+	decoder := scale.NewDecoder(r)
+	for i, target := range targets {
+		err = decoder.Decode(target)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode argument %v of type %v of %v: %v", args[i].Name, args[i].Type, name,
+				err)
+		}
+	}
+
+	if all && r.Len() > 0 {
+		return nil, fmt.Errorf("%v bytes left after decoding the arguments of %v", r.Len(), name)
+	}
+	return raw[len(raw)-r.Len():], nil
 }
 
 func (e *Method) Decode(decoder scale.Decoder) error {
@@ -168,50 +250,175 @@ func (m Method) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// Calls is a Vec<Call>, the argument of Utility.batch
+type Calls []Method
+
+func (c Calls) Encode(encoder scale.Encoder) error {
+	err := encoder.EncodeUintCompact(uint64(len(c)))
+	if err != nil {
+		return err
+	}
+
+	for _, m := range c {
+		err = encoder.Encode(m)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewBatchCall creates a Utility.batch call that dispatches the calls in order in a single extrinsic. The batch stops
+// at the first call that fails, the calls before it are not reverted.
+func NewBatchCall(meta MetadataVersioned, calls []Method) (Method, error) {
+	idx, err := meta.FindCall("Utility.batch")
+	if err != nil {
+		return Method{}, err
+	}
+
+	return Method{CallIndex: idx, Args: Calls(calls)}, nil
+}
+
 type Extrinsic struct {
 	subKeyCMD  string
 	subKeySign string
-	Nonce      uint64
+	// signer signs the extrinsic in process, the subkey command is used if it is nil
+	signer signature.KeyringPair
+	// unsigned extrinsics have neither signer nor subkey command, see NewUnsignedExtrinsic
+	unsigned bool
+	Nonce    uint64
 
 	GenesisBlock []byte
 	Signature    ExtrinsicSignature
 	Method       Method
+
+	// UseMultiAddress encodes and decodes the signer as MultiAddress instead of Address, see
+	// ExtrinsicSignature.UseMultiAddress
+	UseMultiAddress bool
+
+	// Version4 encodes and decodes the extrinsic in the version 4 format, it is signed as ExtrinsicPayloadV4 for the
+	// runtime of SpecVersion and TransactionVersion and the signature is SignatureV4. Runtimes with the
+	// CheckTxVersion signed extension only accept this format.
+	Version4           bool
+	SpecVersion        uint32
+	TransactionVersion uint32
+	Tip                UCompact
+	SignatureV4        ExtrinsicSignatureV4
+
+	// Version3 encodes and decodes the extrinsic in the version 3 format instead, it is signed as ExtrinsicPayloadV3
+	// for the runtime of SpecVersion and the signature is SignatureV3. Signed extensions are not supported for it.
+	Version3    bool
+	SignatureV3 ExtrinsicSignatureV3
+
+	// SignedExtensions and CustomSignedExtensions sign the version 4 format for the signed extensions of the
+	// runtime, see ExtrinsicPayloadV4.SignedExtensions
+	SignedExtensions       []string
+	CustomSignedExtensions map[string]SignedExtensionData
 }
 
 func NewExtrinsic(subKeyCMD string, subKeySign string, accountNonce uint64, genesisBlock []byte, method Method) *Extrinsic {
 	return &Extrinsic{subKeyCMD: subKeyCMD, subKeySign: subKeySign, Nonce: accountNonce, GenesisBlock: genesisBlock, Method: method}
 }
 
+// NewExtrinsicWithKey creates an extrinsic that is signed by the keyring pair when it is encoded. Only ed25519 and
+// sr25519 pairs are supported, as the signature is encoded as 64 bytes without the type of the key.
+func NewExtrinsicWithKey(signer signature.KeyringPair, accountNonce uint64, genesisBlock []byte,
+	method Method) *Extrinsic {
+	return &Extrinsic{signer: signer, Nonce: accountNonce, GenesisBlock: genesisBlock, Method: method}
+}
+
+// NewUnsignedExtrinsic creates an extrinsic without signature in the version 4 format, eg: an inherent such as
+// Timestamp.set. Set Version4 to false for the older format.
+func NewUnsignedExtrinsic(method Method) *Extrinsic {
+	return &Extrinsic{unsigned: true, Version4: true, Method: method}
+}
+
+// IsSigned returns false for unsigned extrinsics, see NewUnsignedExtrinsic
+func (e Extrinsic) IsSigned() bool {
+	return !e.unsigned
+}
+
+// version returns the version byte of unsigned extrinsics, signed extrinsics have extrinsicBitSigned set as well
+func (e Extrinsic) version() byte {
+	if e.Version4 {
+		return 4
+	}
+	if e.Version3 {
+		return 3
+	}
+	return 1
+}
+
 func (e *Extrinsic) Decode(decoder scale.Decoder) error {
-	// length (not used)
-	_, err := decoder.DecodeUintCompact()
+	l, err := decoder.DecodeLength()
 	if err != nil {
 		return err
 	}
 
-	e.Signature = ExtrinsicSignature{}
-	err = decoder.Decode(&e.Signature)
+	if l == 0 {
+		return errors.New("empty extrinsic")
+	}
+
+	b := make([]byte, l)
+	err = decoder.Read(b)
 	if err != nil {
 		return err
 	}
 
-	err = decoder.Decode(&e.Method)
+	// the version byte tells whether a signature follows
+	r := bytes.NewReader(b)
+	dec := scale.NewDecoder(r)
+	e.unsigned = b[0]&extrinsicBitSigned == 0
+	switch {
+	case e.unsigned:
+		e.Version4 = b[0] == 4
+		e.Version3 = b[0] == 3
+		_, err = dec.ReadOneByte()
+	case e.Version3:
+		err = dec.Decode(&e.SignatureV3)
+	case e.Version4:
+		e.SignatureV4 = ExtrinsicSignatureV4{UseMultiAddress: e.UseMultiAddress}
+		err = dec.Decode(&e.SignatureV4)
+	default:
+		e.Signature = ExtrinsicSignature{UseMultiAddress: e.UseMultiAddress}
+		err = dec.Decode(&e.Signature)
+	}
 	if err != nil {
 		return err
 	}
 
+	if e.Method.Args != nil {
+		return dec.Decode(&e.Method)
+	}
+
+	err = dec.Decode(&e.Method.CallIndex)
+	if err != nil {
+		return err
+	}
+	// the type of the arguments is unknown, they are kept encoded
+	e.Method.Args = Encoded(b[len(b)-r.Len():])
 	return nil
 }
 
 func (e Extrinsic) Encode(encoder scale.Encoder) error {
+	if e.unsigned {
+		return e.encodeUnsigned(encoder)
+	}
+	if e.Version4 {
+		return e.encodeV4(encoder)
+	}
+	if e.Version3 {
+		return e.encodeV3(encoder)
+	}
+
 	bb := new(bytes.Buffer)
 	tempEnc := scale.NewEncoder(bb)
 
 	sigPay := SignaturePayload{
 		Nonce:  e.Nonce,
 		Method: e.Method,
-		// Immortal
-		Era: 0,
+		// immortal, so the prior block is the genesis block
+		Era: NewImmortalEra(),
 	}
 	copy(sigPay.PriorBlock[:], e.GenesisBlock)
 	err := tempEnc.Encode(sigPay)
@@ -219,19 +426,23 @@ func (e Extrinsic) Encode(encoder scale.Encoder) error {
 		return err
 	}
 	bbb := bb.Bytes()
-	encoded := hex.EncodeToString(bbb)
 
-	// use "subKey" command for signature
-	out, err := exec.Command(e.subKeyCMD, e.subKeySign, encoded, Alice).Output()
-	// fmt.Println(SubKeyCmd, SubKeySign, encoded, Alice)
-	if err != nil {
-		log.Fatal(err.Error())
-	}
-
-	v := string(out)
-	vs, err := hex.DecodeString(v)
+	if e.signer != nil {
+		sig, err := signPayload(e.signer, bbb)
+		if err != nil {
+			return err
+		}
+		e.Signature = NewExtrinsicSignature(sig, e.Nonce)
+		e.Signature.Signer = *NewAddress(e.signer.PublicKey())
+	} else {
+		vs, err := signWithSubKey(e.subKeyCMD, e.subKeySign, bbb)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
 
-	e.Signature = NewExtrinsicSignature(*NewSignature(vs), e.Nonce)
+		e.Signature = NewExtrinsicSignature(*NewSignature(vs), e.Nonce)
+	}
+	e.Signature.UseMultiAddress = e.UseMultiAddress
 
 	bb = new(bytes.Buffer)
 	tempEnc = scale.NewEncoder(bb)
@@ -257,37 +468,400 @@ func (e Extrinsic) Encode(encoder scale.Encoder) error {
 	return nil
 }
 
+// Hash returns the blake2b-256 hash of the encoded extrinsic. The extrinsic is signed while it is encoded and sr25519
+// signatures are randomized, so for sr25519 keys it is not the hash of another encoding of the extrinsic. Use
+// EncodeWithHash to get the hash of the encoding that is submitted.
+func (e Extrinsic) Hash() (Hash, error) {
+	_, h, err := e.EncodeWithHash()
+	return h, err
+}
+
+// EncodeWithHash signs and encodes the extrinsic once and returns the encoding with its blake2b-256 hash. The node
+// returns the same hash when the encoding is submitted, eg: hex encoded with Author.SubmitEncodedExtrinsic, so the
+// extrinsic can be tracked before it is submitted.
+func (e Extrinsic) EncodeWithHash() ([]byte, Hash, error) {
+	bb := new(bytes.Buffer)
+	err := scale.NewEncoder(bb).Encode(e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return bb.Bytes(), Blake2_256(bb.Bytes()), nil
+}
+
+// encodeUnsigned encodes the version byte and the method, without signature
+func (e Extrinsic) encodeUnsigned(encoder scale.Encoder) error {
+	bb := new(bytes.Buffer)
+	tempEnc := scale.NewEncoder(bb)
+	err := tempEnc.PushByte(e.version())
+	if err != nil {
+		return err
+	}
+	err = tempEnc.Encode(e.Method)
+	if err != nil {
+		return err
+	}
+
+	// encode with length prefix
+	eb := bb.Bytes()
+	err = encoder.EncodeUintCompact(uint64(len(eb)))
+	if err != nil {
+		return err
+	}
+	return encoder.Write(eb)
+}
+
+// encodeV4 signs the extrinsic as ExtrinsicPayloadV4 and encodes it in the version 4 format
+func (e Extrinsic) encodeV4(encoder scale.Encoder) error {
+	payload := ExtrinsicPayloadV4{
+		Method:                 e.Method,
+		Era:                    NewImmortalEra(),
+		Nonce:                  e.Nonce,
+		Tip:                    e.Tip,
+		SpecVersion:            e.SpecVersion,
+		TransactionVersion:     e.TransactionVersion,
+		SignedExtensions:       e.SignedExtensions,
+		CustomSignedExtensions: e.CustomSignedExtensions,
+	}
+	copy(payload.GenesisHash[:], e.GenesisBlock)
+	// immortal, so the era starts at the genesis block
+	payload.BlockHash = payload.GenesisHash
+
+	bb := new(bytes.Buffer)
+	err := scale.NewEncoder(bb).Encode(payload)
+	if err != nil {
+		return err
+	}
+
+	e.SignatureV4 = ExtrinsicSignatureV4{Era: payload.Era, Nonce: e.Nonce, Tip: e.Tip, UseMultiAddress: e.UseMultiAddress}
+	if e.SignedExtensions != nil {
+		extra, _, err := payload.signedExtensions()
+		if err != nil {
+			return err
+		}
+		e.SignatureV4.Extra = append(Encoded{}, extra...)
+	}
+	if e.signer != nil {
+		e.SignatureV4.Signature, err = signMultiSignature(e.signer, bb.Bytes())
+		if err != nil {
+			return err
+		}
+		e.SignatureV4.Signer = *NewAddress(e.signer.PublicKey())
+	} else {
+		// subkey signs as Alice, who has an sr25519 key
+		payload := bb.Bytes()
+		if len(payload) > 256 {
+			payload = Blake2_256(payload)
+		}
+		sig, err := signWithSubKey(e.subKeyCMD, e.subKeySign, payload)
+		if err != nil {
+			return err
+		}
+		e.SignatureV4.Signature = signature.MultiSignature{IsSr25519: true}
+		copy(e.SignatureV4.Signature.AsSr25519[:], sig)
+		e.SignatureV4.Signer = *NewAddress(hexutil.MustDecode(AlicePubKey))
+	}
+
+	bb = new(bytes.Buffer)
+	tempEnc := scale.NewEncoder(bb)
+	err = tempEnc.Encode(e.SignatureV4)
+	if err != nil {
+		return err
+	}
+	err = tempEnc.Encode(e.Method)
+	if err != nil {
+		return err
+	}
+
+	// encode with length prefix
+	eb := bb.Bytes()
+	err = encoder.EncodeUintCompact(uint64(len(eb)))
+	if err != nil {
+		return err
+	}
+	return encoder.Write(eb)
+}
+
+// encodeV3 signs the extrinsic as ExtrinsicPayloadV3 and encodes it in the version 3 format
+func (e Extrinsic) encodeV3(encoder scale.Encoder) error {
+	payload := ExtrinsicPayloadV3{
+		Method:      e.Method,
+		Era:         NewImmortalEra(),
+		Nonce:       e.Nonce,
+		Tip:         e.Tip,
+		SpecVersion: e.SpecVersion,
+	}
+	copy(payload.GenesisHash[:], e.GenesisBlock)
+	// immortal, so the era starts at the genesis block
+	payload.BlockHash = payload.GenesisHash
+
+	bb := new(bytes.Buffer)
+	err := scale.NewEncoder(bb).Encode(payload)
+	if err != nil {
+		return err
+	}
+
+	e.SignatureV3 = ExtrinsicSignatureV3{Era: payload.Era, Nonce: e.Nonce, Tip: e.Tip}
+	if e.signer != nil {
+		e.SignatureV3.Signature, err = signPayload(e.signer, bb.Bytes())
+		if err != nil {
+			return err
+		}
+		e.SignatureV3.Signer = *NewAddress(e.signer.PublicKey())
+	} else {
+		// subkey signs as Alice
+		payload := bb.Bytes()
+		if len(payload) > 256 {
+			payload = Blake2_256(payload)
+		}
+		sig, err := signWithSubKey(e.subKeyCMD, e.subKeySign, payload)
+		if err != nil {
+			return err
+		}
+		e.SignatureV3.Signature = *NewSignature(sig)
+		e.SignatureV3.Signer = *NewAddress(hexutil.MustDecode(AlicePubKey))
+	}
+
+	bb = new(bytes.Buffer)
+	tempEnc := scale.NewEncoder(bb)
+	err = tempEnc.Encode(e.SignatureV3)
+	if err != nil {
+		return err
+	}
+	err = tempEnc.Encode(e.Method)
+	if err != nil {
+		return err
+	}
+
+	// encode with length prefix
+	eb := bb.Bytes()
+	err = encoder.EncodeUintCompact(uint64(len(eb)))
+	if err != nil {
+		return err
+	}
+	return encoder.Write(eb)
+}
+
+// signWithSubKey signs the payload as Alice with the subkey command
+func signWithSubKey(subKeyCMD, subKeySign string, payload []byte) ([]byte, error) {
+	out, err := exec.Command(subKeyCMD, subKeySign, hex.EncodeToString(payload), Alice).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(strings.TrimSpace(string(out)))
+}
+
 type Author struct {
 	client       Client
 	genesisBlock []byte
 
 	subKeyCMD  string
 	subKeySign string
+
+	// keyringPair signs the extrinsics in process instead of the subkey command, see NewAuthorRPCWithKey
+	keyringPair signature.KeyringPair
+
+	// UseMultiAddress makes the submitted extrinsics use MultiAddress for the signer, set it for runtimes that
+	// replaced Address with MultiAddress
+	UseMultiAddress bool
+
+	// RuntimeVersion makes the submitted extrinsics use the version 4 format, signed for the spec and transaction
+	// version of the runtime, see State.GetRuntimeVersion. It must be updated after runtime upgrades. Runtimes whose
+	// metadata gives extrinsic version 3 get the version 3 format instead, see MetadataVersioned.ExtrinsicVersion.
+	RuntimeVersion *RuntimeVersion
+
+	// Tip is paid to the block author on top of the fees, in the smallest unit of the balance. It is only submitted
+	// in the version 4 format, see RuntimeVersion.
+	Tip UCompact
+
+	// SignedExtensions and CustomSignedExtensions are the signed extensions of the runtime, see
+	// ExtrinsicPayloadV4.SignedExtensions. They only apply to the version 4 format.
+	SignedExtensions       []string
+	CustomSignedExtensions map[string]SignedExtensionData
 }
 
 func NewAuthorRPC(client Client, genesisBlock []byte, subKeyCMD, SubKeySign string) *Author {
-	return &Author{client, genesisBlock, subKeyCMD, SubKeySign}
+	return &Author{client: client, genesisBlock: genesisBlock, subKeyCMD: subKeyCMD, subKeySign: SubKeySign}
+}
+
+// NewAuthorRPCWithKey creates an author that signs the submitted extrinsics with the keyring pair, without calling
+// subkey. The pair must be an ed25519 or sr25519 pair, eg: from signature.KeyringPairFromMnemonic.
+func NewAuthorRPCWithKey(client Client, genesisBlock []byte, pair signature.KeyringPair) *Author {
+	return &Author{client: client, genesisBlock: genesisBlock, keyringPair: pair}
+}
+
+// NewAuthorRPCFromChain creates an author like NewAuthorRPCWithKey, with the genesis hash and the runtime version
+// fetched from the node, so the extrinsics are signed for the chain the client is connected to. The nonce of the
+// account must still be passed to each submission, eg: from system.AccountNonce.
+func NewAuthorRPCFromChain(client Client, pair signature.KeyringPair) (*Author, error) {
+	genesis, err := NewChainRPC(client).GetBlockHash(0)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := NewStateRPC(client).GetRuntimeVersion(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	a := NewAuthorRPCWithKey(client, genesis, pair)
+	a.RuntimeVersion = version
+	return a, nil
 }
 
 func (a *Author) SubmitExtrinsic(accountNonce uint64, method string, args Args) (string, error) {
-	m, err := a.client.MetaData(true)
+	return a.SubmitExtrinsicContext(context.Background(), accountNonce, method, args)
+}
+
+// SubmitExtrinsicContext is like SubmitExtrinsic, but returns ctx.Err() once the context is done without waiting for
+// the response. The extrinsic may have been submitted anyway.
+func (a *Author) SubmitExtrinsicContext(ctx context.Context, accountNonce uint64, method string, args Args) (string,
+	error) {
+	eb, err := a.encodeExtrinsic(accountNonce, method, args)
 	if err != nil {
 		return "", err
 	}
-	e := NewExtrinsic(a.subKeyCMD, a.subKeySign, accountNonce, a.genesisBlock, NewMethod(method, args, *m))
-	bbb := new(bytes.Buffer)
-	tempEnc := scale.NewEncoder(bbb)
-	err = tempEnc.Encode(&e)
+
+	var res string
+	err = a.client.CallContext(ctx, &res, "author_submitExtrinsic", eb)
 	if err != nil {
 		return "", err
 	}
 
-	eb := hexutil.Encode(bbb.Bytes())
+	return res, nil
+}
+
+// SignExtrinsic signs the extrinsic like SubmitExtrinsic, but returns it hex encoded with its hash instead of
+// submitting it. Submit it with SubmitEncodedExtrinsic, the node returns the same hash.
+func (a *Author) SignExtrinsic(accountNonce uint64, method string, args Args) (string, Hash, error) {
+	m, err := a.client.MetaData(true)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b, h, err := a.newExtrinsic(m, accountNonce, method, args).EncodeWithHash()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return hexutil.Encode(b), h, nil
+}
+
+// SubmitEncodedExtrinsic submits an extrinsic that is signed and hex encoded already, eg: by SignExtrinsic or
+// SignExtrinsicOffline. It returns the hash of the extrinsic like SubmitExtrinsic.
+func (a *Author) SubmitEncodedExtrinsic(extrinsic string) (string, error) {
 	var res string
-	err = a.client.Call(&res, "author_submitExtrinsic", eb)
+	err := a.client.Call(&res, "author_submitExtrinsic", extrinsic)
 	if err != nil {
 		return "", err
 	}
 
 	return res, nil
 }
+
+// DryRun applies the extrinsic on top of the given block, or the best block if at is nil, without submitting it. The
+// result tells whether the extrinsic would be rejected, eg: because of a stale nonce, or whether its dispatch would
+// fail.
+func (a *Author) DryRun(accountNonce uint64, method string, args Args, at *Hash) (*ApplyExtrinsicResult, error) {
+	eb, err := a.encodeExtrinsic(accountNonce, method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var res string
+	if at != nil {
+		err = a.client.Call(&res, "system_dryRun", eb, at.String())
+	} else {
+		err = a.client.Call(&res, "system_dryRun", eb)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := hexutil.Decode(res)
+	if err != nil {
+		return nil, err
+	}
+
+	var r ApplyExtrinsicResult
+	err = scale.NewDecoder(bytes.NewReader(b)).Decode(&r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// PendingExtrinsics returns the extrinsics in the transaction pool of the node, their arguments are Encoded. The
+// signers are decoded as MultiAddress if UseMultiAddress is set.
+func (a *Author) PendingExtrinsics() ([]Extrinsic, error) {
+	var res []hexutil.Bytes
+	err := a.client.Call(&res, "author_pendingExtrinsics")
+	if err != nil {
+		return nil, err
+	}
+
+	return Block{Extrinsics: res}.DecodeExtrinsics(a.UseMultiAddress)
+}
+
+// newExtrinsic creates the extrinsic in the format of the runtime with the metadata m, see RuntimeVersion
+func (a *Author) newExtrinsic(m *MetadataVersioned, accountNonce uint64, method string, args Args) *Extrinsic {
+	var e *Extrinsic
+	if a.keyringPair != nil {
+		e = NewExtrinsicWithKey(a.keyringPair, accountNonce, a.genesisBlock, NewMethod(method, args, *m))
+	} else {
+		e = NewExtrinsic(a.subKeyCMD, a.subKeySign, accountNonce, a.genesisBlock, NewMethod(method, args, *m))
+	}
+	e.UseMultiAddress = a.UseMultiAddress
+	if a.RuntimeVersion == nil {
+		return e
+	}
+
+	e.SpecVersion = a.RuntimeVersion.SpecVersion
+	e.Tip = a.Tip
+	if m.ExtrinsicVersion() == 3 {
+		e.Version3 = true
+		return e
+	}
+
+	e.Version4 = true
+	e.TransactionVersion = a.RuntimeVersion.TransactionVersion
+	e.SignedExtensions = a.SignedExtensions
+	e.CustomSignedExtensions = a.CustomSignedExtensions
+	return e
+}
+
+// encodeExtrinsic signs the extrinsic and returns it hex encoded
+func (a *Author) encodeExtrinsic(accountNonce uint64, method string, args Args) (string, error) {
+	eb, _, err := a.SignExtrinsic(accountNonce, method, args)
+	return eb, err
+}
+
+// signPayload signs the encoded signature payload with the pair, see signMultiSignature. The signature is returned
+// without its crypto scheme.
+func signPayload(pair signature.KeyringPair, payload []byte) (Signature, error) {
+	sig, err := signMultiSignature(pair, payload)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	switch {
+	case sig.IsSr25519:
+		return Signature{Hash: sig.AsSr25519}, nil
+	case sig.IsEd25519:
+		return Signature{Hash: sig.AsEd25519}, nil
+	default:
+		return Signature{}, fmt.Errorf("cannot sign extrinsics with %s keys", pair.Type())
+	}
+}
+
+// signMultiSignature signs the encoded signature payload with the pair, payloads longer than 256 bytes are signed as
+// their blake2b-256 hash as the runtime expects
+func signMultiSignature(pair signature.KeyringPair, payload []byte) (signature.MultiSignature, error) {
+	if len(payload) > 256 {
+		payload = Blake2_256(payload)
+	}
+
+	return pair.Sign(payload)
+}
@@ -0,0 +1,370 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/minio/blake2b-simd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtrinsicSignature_EncodeDecode_multiAddress(t *testing.T) {
+	sig := NewExtrinsicSignature(*NewSignature(bytes.Repeat([]byte{0x11}, 64)), 5)
+	sig.UseMultiAddress = true
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(sig)
+	assert.NoError(t, err)
+	// signed, signer as MultiAddress::Id, signature, nonce and immortal era
+	assert.Equal(t, "0x8100"+AlicePubKey[2:]+string(bytes.Repeat([]byte("11"), 64))+"1400",
+		hexutil.Encode(buf.Bytes()))
+
+	decoded := ExtrinsicSignature{UseMultiAddress: true}
+	err = scale.NewDecoder(&buf).Decode(&decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, AlicePubKey, hexutil.Encode(decoded.Signer.PubKey[:]))
+	assert.Equal(t, sig.Signature, decoded.Signature)
+	assert.Equal(t, uint64(5), decoded.Nonce)
+	assert.Equal(t, NewImmortalEra(), decoded.Era)
+
+	// the legacy address is prefixed with 0xff
+	sig.UseMultiAddress = false
+	buf.Reset()
+	err = scale.NewEncoder(&buf).Encode(sig)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x81ff"+AlicePubKey[2:], hexutil.Encode(buf.Bytes()[:34]))
+
+	err = scale.NewDecoder(bytes.NewReader(append([]byte{0x81, 0x02, 0x00}, buf.Bytes()[34:]...))).Decode(&decoded)
+	assert.EqualError(t, err, "only account ids are supported as multi address signer")
+}
+
+func TestExtrinsic_Encode_withKey(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x01}, 32)
+	pair, err := signature.NewKeyringPairFromSeed(seed, signature.ED25519, ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	genesis := bytes.Repeat([]byte{0x02}, 32)
+	method := Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))}
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(NewExtrinsicWithKey(pair, 7, genesis, method))
+	assert.NoError(t, err)
+
+	decoded := Extrinsic{Method: Method{Args: &UCompact{}}}
+	err = scale.NewDecoder(&buf).Decode(&decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, pair.PublicKey(), decoded.Signature.Signer.PubKey[:])
+	assert.Equal(t, uint64(7), decoded.Signature.Nonce)
+	assert.Equal(t, MethodIDX{1, 0}, decoded.Method.CallIndex)
+
+	// the signature covers the nonce, the method, the era and the genesis block
+	payload := SignaturePayload{Nonce: 7, Method: method, Era: NewImmortalEra()}
+	copy(payload.PriorBlock[:], genesis)
+	var pbuf bytes.Buffer
+	err = scale.NewEncoder(&pbuf).Encode(payload)
+	assert.NoError(t, err)
+	sig := signature.MultiSignature{IsEd25519: true, AsEd25519: decoded.Signature.Signature.Hash}
+	assert.True(t, pair.Verify(pbuf.Bytes(), sig))
+}
+
+func TestSignPayload(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	// long payloads are signed as their hash
+	payload := bytes.Repeat([]byte{0x03}, 257)
+	sig, err := signPayload(pair, payload)
+	assert.NoError(t, err)
+	h := blake2b.Sum256(payload)
+	assert.True(t, pair.Verify(h[:], signature.MultiSignature{IsEd25519: true, AsEd25519: sig.Hash}))
+
+	pair, err = signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ECDSA,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	_, err = signPayload(pair, payload)
+	assert.EqualError(t, err, "cannot sign extrinsics with ecdsa keys")
+}
+
+func TestExtrinsic_Hash(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	method := Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))}
+	e := NewExtrinsicWithKey(pair, 7, bytes.Repeat([]byte{0x02}, 32), method)
+	h, err := e.Hash()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(e)
+	assert.NoError(t, err)
+	expected := blake2b.Sum256(buf.Bytes())
+	assert.Equal(t, Hash(expected[:]), h)
+	assert.Equal(t, "0x0438106bbab407c3b9a3fe9d07f420bfc175bb45d97a85c1f5e160401615d925", h.String())
+
+	b, h, err := e.EncodeWithHash()
+	assert.NoError(t, err)
+	assert.Equal(t, buf.Bytes(), b)
+	assert.Equal(t, Hash(expected[:]), h)
+}
+
+func TestNewBatchCall(t *testing.T) {
+	calls := []Method{
+		{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1))},
+		{CallIndex: MethodIDX{2, 3}, Args: NewUCompact(big.NewInt(2))},
+	}
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(Method{CallIndex: MethodIDX{5, 0}, Args: Calls(calls)})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{5, 0, 8, 1, 0, 4, 2, 3, 8}, buf.Bytes())
+
+	// the test metadata has no utility module
+	_, err = NewBatchCall(*decodeTestMetadataV11(t), calls)
+	assert.EqualError(t, err, "module Utility not found")
+}
+
+func TestDecodeCallArgs(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	idx, err := m.FindCall("Balances.transfer")
+	assert.NoError(t, err)
+
+	name, args, err := m.FindCallArgs(idx)
+	assert.NoError(t, err)
+	assert.Equal(t, "Balances.transfer", name)
+	assert.Len(t, args, 2)
+	assert.Equal(t, "dest", args[0].Name)
+	assert.Equal(t, "Compact<T::Balance>", args[1].Type)
+
+	// Balances.transfer to Alice of 1000
+	dest := *NewAddress(hexutil.MustDecode(AlicePubKey))
+	raw, err := scale.EncodeToBytes(dest)
+	assert.NoError(t, err)
+	call := Method{CallIndex: idx, Args: Encoded(append(raw, 0xa1, 0x0f))}
+
+	var decodedDest Address
+	var value UCompact
+	err = DecodeCallArgs(m, call, &decodedDest, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, dest, decodedDest)
+	assert.Equal(t, int64(1000), value.Int64())
+
+	err = DecodeCallArgs(m, call, &decodedDest)
+	assert.EqualError(t, err, "Balances.transfer has 2 arguments, got 1 targets")
+	err = DecodeCallArgs(m, Method{CallIndex: idx, Args: Encoded(raw)}, &decodedDest, &value)
+	assert.Error(t, err)
+	err = DecodeCallArgs(m, Method{CallIndex: idx, Args: Encoded(append(raw, 0xa1, 0x0f, 0x00))}, &decodedDest, &value)
+	assert.EqualError(t, err, "1 bytes left after decoding the arguments of Balances.transfer")
+	err = DecodeCallArgs(m, Method{CallIndex: MethodIDX{99, 0}}, &decodedDest, &value)
+	assert.EqualError(t, err, "module index 99 out of range")
+}
+
+func TestDecodeCallArgsPrefix(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	idx, err := m.FindCall("Balances.transfer")
+	assert.NoError(t, err)
+
+	// Balances.transfer to Alice of 1000, only the destination is decoded
+	dest := *NewAddress(hexutil.MustDecode(AlicePubKey))
+	raw, err := scale.EncodeToBytes(dest)
+	assert.NoError(t, err)
+	call := Method{CallIndex: idx, Args: Encoded(append(raw, 0xa1, 0x0f))}
+
+	var decodedDest Address
+	rest, err := DecodeCallArgsPrefix(m, call, &decodedDest)
+	assert.NoError(t, err)
+	assert.Equal(t, dest, decodedDest)
+	assert.Equal(t, Encoded{0xa1, 0x0f}, rest)
+
+	// the rest can be decoded later on
+	var value UCompact
+	err = scale.DecodeFromBytes(rest, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), value.Int64())
+
+	rest, err = DecodeCallArgsPrefix(m, call, &decodedDest, &value)
+	assert.NoError(t, err)
+	assert.Empty(t, rest)
+
+	// an argument a later runtime version appended is left over
+	call.Args = Encoded(append(raw, 0xa1, 0x0f, 0x01))
+	rest, err = DecodeCallArgsPrefix(m, call, &decodedDest, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, Encoded{0x01}, rest)
+
+	rest, err = DecodeCallArgsPrefix(m, call)
+	assert.NoError(t, err)
+	assert.Equal(t, call.Args, rest)
+
+	var extra bool
+	_, err = DecodeCallArgsPrefix(m, call, &decodedDest, &value, &extra)
+	assert.EqualError(t, err, "Balances.transfer has 2 arguments, got 3 targets")
+	_, err = DecodeCallArgsPrefix(m, Method{CallIndex: idx, Args: Encoded(raw[:10])}, &decodedDest)
+	assert.Error(t, err)
+}
+
+func TestExtrinsic_Unsigned_EncodeDecode(t *testing.T) {
+	// Timestamp.set(1000)
+	e := NewUnsignedExtrinsic(Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))})
+	assert.False(t, e.IsSigned())
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(e)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x14, 0x04, 0x01, 0x00, 0xa1, 0x0f}, buf.Bytes())
+
+	decoded := Extrinsic{Version4: true, Method: Method{Args: &UCompact{}}}
+	err = scale.NewDecoder(&buf).Decode(&decoded)
+	assert.NoError(t, err)
+	assert.False(t, decoded.IsSigned())
+	assert.Equal(t, e.Method.CallIndex, decoded.Method.CallIndex)
+	assert.Equal(t, int64(1000), decoded.Method.Args.(*UCompact).Int64())
+
+	e.Version4 = false
+	buf.Reset()
+	err = scale.NewEncoder(&buf).Encode(e)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x14, 0x01, 0x01, 0x00, 0xa1, 0x0f}, buf.Bytes())
+
+	assert.True(t, NewExtrinsic("", "", 0, nil, Method{}).IsSigned())
+
+	err = scale.NewDecoder(bytes.NewReader([]byte{0x00})).Decode(&decoded)
+	assert.EqualError(t, err, "empty extrinsic")
+}
+
+func TestAuthor_NewAuthorRPCFromChain(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	genesis := bytes.Repeat([]byte{0x02}, 32)
+	testServer.AddBlockHash(0, hexutil.Encode(genesis))
+	testServer.SetRuntimeVersion(testRuntimeVersion)
+
+	a, err := NewAuthorRPCFromChain(testClient, pair)
+	assert.NoError(t, err)
+	assert.Equal(t, genesis, a.genesisBlock)
+	assert.Equal(t, uint32(2026), a.RuntimeVersion.SpecVersion)
+	assert.Equal(t, uint32(4), a.RuntimeVersion.TransactionVersion)
+}
+
+func TestAuthor_PendingExtrinsics(t *testing.T) {
+	a := NewAuthorRPC(testClient, nil, "", "")
+	pending, err := a.PendingExtrinsics()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 0)
+
+	testServer.SetPendingExtrinsics("0x280402000b10449e516c01")
+	defer testServer.SetPendingExtrinsics()
+	pending, err = a.PendingExtrinsics()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.False(t, pending[0].IsSigned())
+	assert.Equal(t, MethodIDX{2, 0}, pending[0].Method.CallIndex)
+}
+
+func TestAuthor_SubmitEncodedExtrinsic(t *testing.T) {
+	a := NewAuthorRPC(testClient, nil, "", "")
+	res, err := a.SubmitEncodedExtrinsic("0x280402000b10449e516c01")
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Encode(Blake2_256(hexutil.MustDecode("0x280402000b10449e516c01"))), res)
+}
+
+func TestAuthor_SignExtrinsic(t *testing.T) {
+	// sr25519 signatures are randomized, each encoding of the extrinsic has another hash
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.SR25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	a := NewAuthorRPCWithKey(testClient, bytes.Repeat([]byte{0x02}, 32), pair)
+
+	eb, h, err := a.SignExtrinsic(7, "Balances.transfer", NewUCompact(big.NewInt(1)))
+	assert.NoError(t, err)
+	other, _, err := a.SignExtrinsic(7, "Balances.transfer", NewUCompact(big.NewInt(1)))
+	assert.NoError(t, err)
+	assert.NotEqual(t, eb, other)
+
+	// the hash is the one of the submitted encoding
+	res, err := a.SubmitEncodedExtrinsic(eb)
+	assert.NoError(t, err)
+	assert.Equal(t, h.String(), res)
+	submitted := testServer.SubmittedExtrinsics()
+	assert.Equal(t, eb, submitted[len(submitted)-1])
+}
+
+func TestAuthor_DryRun(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	a := NewAuthorRPCWithKey(testClient, bytes.Repeat([]byte{0x02}, 32), pair)
+	defer testServer.SetDryRun("")
+
+	// the dispatch succeeds
+	testServer.SetDryRun("0x0000")
+	res, err := a.DryRun(7, "Balances.transfer", NewUCompact(big.NewInt(1)), nil)
+	assert.NoError(t, err)
+	assert.True(t, res.IsDispatchOutcome)
+	assert.True(t, res.AsDispatchOutcome.IsOk)
+
+	// the extrinsic is rejected because of a stale nonce
+	testServer.SetDryRun("0x010003")
+	at := Hash(bytes.Repeat([]byte{0x03}, 32))
+	res, err = a.DryRun(7, "Balances.transfer", NewUCompact(big.NewInt(1)), &at)
+	assert.NoError(t, err)
+	assert.True(t, res.IsValidityError)
+	assert.True(t, res.AsValidityError.IsInvalid)
+	assert.True(t, res.AsValidityError.AsInvalid.IsStale)
+}
+
+func TestAuthor_newExtrinsic(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	m := decodeTestMetadataV11(t)
+	m.BuildCallIndex()
+	args := NewUCompact(big.NewInt(1))
+	version := &RuntimeVersion{SpecVersion: 2026, TransactionVersion: 4}
+	for _, test := range []struct {
+		runtimeVersion   *RuntimeVersion
+		extrinsicVersion uint8
+		version3         bool
+		version4         bool
+	}{
+		// the old format without runtime version
+		{nil, 4, false, false},
+		{nil, 3, false, false},
+		{version, 4, false, true},
+		{version, 3, true, false},
+	} {
+		m.MetadataV11.Extrinsic.Version = test.extrinsicVersion
+		a := NewAuthorRPCWithKey(nil, bytes.Repeat([]byte{0x02}, 32), pair)
+		a.RuntimeVersion = test.runtimeVersion
+		e := a.newExtrinsic(m, 7, "Balances.transfer", args)
+		assert.Equal(t, test.version3, e.Version3)
+		assert.Equal(t, test.version4, e.Version4)
+
+		// the encoding has the version byte of the format
+		raw, err := scale.EncodeToBytes(e)
+		assert.NoError(t, err)
+		switch {
+		case test.version3:
+			assert.Equal(t, byte(ExtrinsicVersion3), extrinsicVersion(raw))
+			assert.Equal(t, uint32(2026), e.SpecVersion)
+		case test.version4:
+			assert.Equal(t, byte(ExtrinsicVersion4), extrinsicVersion(raw))
+			assert.Equal(t, uint32(4), e.TransactionVersion)
+		default:
+			assert.Equal(t, byte(0x81), extrinsicVersion(raw))
+		}
+	}
+
+	// metadata before v11 doesn't describe the extrinsic version
+	assert.Equal(t, uint8(0), (&MetadataVersioned{Version: 4}).ExtrinsicVersion())
+}
@@ -0,0 +1,61 @@
+package balances
+
+import (
+	"math/big"
+
+	"github.com/centrifuge/go-substrate-rpc-client"
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/system"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// transferArgs are the arguments of Balances.transfer
+type transferArgs struct {
+	dest   substrate.Address
+	amount substrate.UCompact
+}
+
+func (a transferArgs) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(a.dest)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Encode(a.amount)
+}
+
+// Transfer transfers amount, in the smallest unit of the balance, from the account of the pair to dest and returns
+// the hash of the submitted extrinsic. The nonce, the genesis hash and the runtime version are fetched from the node.
+// The pair must be an ed25519 or sr25519 pair, see substrate.NewAuthorRPCFromChain. Runtimes that replaced Address
+// with MultiAddress are not supported.
+func Transfer(client substrate.Client, pair signature.KeyringPair, dest substrate.Address, amount *big.Int) (
+	substrate.Hash, error) {
+	m, err := client.MetaData(true)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := system.AccountNonce(client, pair.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	author, err := substrate.NewAuthorRPCFromChain(client, pair)
+	if err != nil {
+		return nil, err
+	}
+
+	// modules are named in lower case in metadata v4
+	call := "Balances.transfer"
+	if m.Version != 11 {
+		call = "balances.transfer"
+	}
+
+	res, err := author.SubmitExtrinsic(nonce, call, transferArgs{dest: dest, amount: substrate.NewUCompact(amount)})
+	if err != nil {
+		return nil, err
+	}
+
+	return hexutil.Decode(res)
+}
@@ -0,0 +1,72 @@
+// +build tests
+
+package balances
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client"
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/centrifuge/go-substrate-rpc-client/testrpc"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var testServer *testrpc.Server
+var testClient substrate.Client
+var rpcURL string
+
+func TestMain(m *testing.M) {
+	testServer = new(testrpc.Server)
+	var err error
+	if rpcURL == "" {
+		rpcURL, err = testServer.Init(testrpc.GetTestMetaData(), nil)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	testClient, err = substrate.Connect(rpcURL)
+	if err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+func TestTransfer(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	m, err := testClient.MetaData(true)
+	assert.NoError(t, err)
+	nonceKey, err := substrate.NewStorageKey(*m, "System", "AccountNonce", pair.PublicKey())
+	assert.NoError(t, err)
+	testServer.AddStorageKey(hexutil.Encode(nonceKey), "0x0700000000000000")
+	testServer.AddBlockHash(0, "0xa8e78ad25e03ac0281ec709fd3f128efb7e112239d0a7c3e1c86375109bff338")
+	testServer.SetRuntimeVersion(`{"apis":[],"authoringVersion":2,"implName":"test","implVersion":0,` +
+		`"specName":"test","specVersion":2026,"transactionVersion":4}`)
+
+	alice := substrate.NewAddress(hexutil.MustDecode(substrate.AlicePubKey))
+	res, err := Transfer(testClient, pair, *alice, big.NewInt(1000))
+	assert.NoError(t, err)
+
+	// the hash is the one of the submitted extrinsic
+	submitted := testServer.SubmittedExtrinsics()
+	assert.Len(t, submitted, 1)
+	b := hexutil.MustDecode(submitted[0])
+	assert.Equal(t, substrate.Hash(substrate.Blake2_256(b)), res)
+
+	e := substrate.Extrinsic{Version4: true, Method: substrate.Method{Args: &substrate.Encoded{}}}
+	err = scale.DecodeFromBytes(b, &e)
+	assert.NoError(t, err)
+	assert.Equal(t, pair.PublicKey(), e.SignatureV4.Signer.PubKey[:])
+	assert.Equal(t, uint64(7), e.SignatureV4.Nonce)
+	assert.Equal(t, m.MethodIndex("balances.transfer"), e.Method.CallIndex)
+	args := append(append([]byte{0xff}, alice.PubKey[:]...), 0xa1, 0x0f)
+	assert.Equal(t, args, []byte(*e.Method.Args.(*substrate.Encoded)))
+}
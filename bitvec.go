@@ -0,0 +1,70 @@
+package substrate
+
+import (
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// BitVec is a bitvec::BitVec<Lsb0, u8>, it is SCALE encoded as the compact number of bits followed by the bits
+// packed into bytes, least significant bit first. Unused bits of the last byte are zero.
+type BitVec struct {
+	bits []byte
+	len  int
+}
+
+// NewBitVec creates a BitVec holding the given bits
+func NewBitVec(bits ...bool) BitVec {
+	v := BitVec{bits: make([]byte, (len(bits)+7)/8), len: len(bits)}
+	for i, b := range bits {
+		if b {
+			v.bits[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return v
+}
+
+// Len returns the number of bits
+func (v BitVec) Len() int {
+	return v.len
+}
+
+// Get returns the bit at index i, it panics if i is out of range
+func (v BitVec) Get(i int) bool {
+	if i < 0 || i >= v.len {
+		panic("bitvec index out of range")
+	}
+	return v.bits[i/8]&(1<<uint(i%8)) != 0
+}
+
+func (v *BitVec) Decode(decoder scale.Decoder) error {
+	l, err := decoder.DecodeUintCompact()
+	if err != nil {
+		return err
+	}
+
+	// the length is the number of bits, it can't exceed the remaining bytes when packed
+	n := (l + 7) / 8
+	if r, ok := decoder.Remaining(); ok && n > uint64(r) {
+		return fmt.Errorf("BitVec of %v bits exceeds the %v remaining bytes", l, r)
+	}
+
+	b := make([]byte, n)
+	err = decoder.Read(b)
+	if err != nil {
+		return err
+	}
+
+	v.bits = b
+	v.len = int(l)
+	return nil
+}
+
+func (v BitVec) Encode(encoder scale.Encoder) error {
+	err := encoder.EncodeUintCompact(uint64(v.len))
+	if err != nil {
+		return err
+	}
+
+	return encoder.Write(v.bits)
+}
@@ -0,0 +1,37 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitVec_EncodeDecode(t *testing.T) {
+	bits := []bool{true, false, true, true, false, false, false, false, true, false, false, false, true}
+	v := NewBitVec(bits...)
+	assert.Equal(t, 13, v.Len())
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(v)
+	assert.NoError(t, err)
+	// 13 bits, 0b00001101 and 0b00010001 with the 3 unused bits zeroed
+	assert.Equal(t, []byte{0x34, 0x0d, 0x11}, buf.Bytes())
+
+	var dec BitVec
+	err = scale.NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&dec)
+	assert.NoError(t, err)
+	assert.Equal(t, v, dec)
+	for i, b := range bits {
+		assert.Equal(t, b, dec.Get(i))
+	}
+	assert.Panics(t, func() { dec.Get(13) })
+
+	err = scale.NewDecoder(bytes.NewReader([]byte{0x34, 0x0d})).Decode(&dec)
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, NewBitVec().Len())
+}
@@ -1 +1,343 @@
 package substrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/jsonrpc"
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// BlockNumber is the number of a block. Over RPC it is transmitted as a hex string.
+type BlockNumber uint64
+
+func (b *BlockNumber) UnmarshalJSON(data []byte) error {
+	// older nodes send the number as a plain JSON number
+	if len(data) > 0 && data[0] != '"' {
+		var n uint64
+		err := json.Unmarshal(data, &n)
+		if err != nil {
+			return err
+		}
+		*b = BlockNumber(n)
+		return nil
+	}
+
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("block number %s is not a hex string", s)
+	}
+
+	n, err := strconv.ParseUint(s[2:], 16, 64)
+	if err != nil {
+		return err
+	}
+	*b = BlockNumber(n)
+	return nil
+}
+
+func (b BlockNumber) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hexutil.EncodeUint64(uint64(b)))
+}
+
+// Decode decodes a compact encoded block number, as it is contained in SCALE encoded headers
+func (b *BlockNumber) Decode(decoder scale.Decoder) error {
+	n, err := decoder.DecodeUintCompact()
+	if err != nil {
+		return err
+	}
+	*b = BlockNumber(n)
+	return nil
+}
+
+func (b BlockNumber) Encode(encoder scale.Encoder) error {
+	return encoder.EncodeUintCompact(uint64(b))
+}
+
+// Header is the header of a block. It is JSON encoded in RPC results and SCALE encoded inside blocks.
+type Header struct {
+	ParentHash     Hash        `json:"parentHash"`
+	Number         BlockNumber `json:"number"`
+	StateRoot      Hash        `json:"stateRoot"`
+	ExtrinsicsRoot Hash        `json:"extrinsicsRoot"`
+	Digest         Digest      `json:"digest"`
+}
+
+func (h *Header) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&h.ParentHash)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&h.Number)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&h.StateRoot)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&h.ExtrinsicsRoot)
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(&h.Digest)
+}
+
+func (h Header) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(h.ParentHash)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(h.Number)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(h.StateRoot)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(h.ExtrinsicsRoot)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Encode(h.Digest)
+}
+
+type Block struct {
+	Header Header `json:"header"`
+	// Extrinsics are the SCALE encoded extrinsics of the block
+	Extrinsics []hexutil.Bytes `json:"extrinsics"`
+}
+
+// DecodeExtrinsics decodes the extrinsics of the block, their arguments are Encoded. The format of each extrinsic is
+// detected from its version byte. Set useMultiAddress for runtimes that encode the signer as MultiAddress, see
+// Extrinsic.UseMultiAddress.
+func (b Block) DecodeExtrinsics(useMultiAddress bool) ([]Extrinsic, error) {
+	extrinsics := make([]Extrinsic, len(b.Extrinsics))
+	for i, raw := range b.Extrinsics {
+		err := decodeExtrinsic(raw, useMultiAddress, &extrinsics[i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode extrinsic #%v: %v", i, err)
+		}
+	}
+
+	return extrinsics, nil
+}
+
+// NewExtrinsicFromHex decodes a hex encoded extrinsic as returned in the extrinsics of chain_getBlock, including its
+// compact length prefix. Its arguments are Encoded and the signer is decoded as Address, use Block.DecodeExtrinsics
+// for runtimes with MultiAddress.
+func NewExtrinsicFromHex(s string) (Extrinsic, error) {
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return Extrinsic{}, err
+	}
+
+	var e Extrinsic
+	err = decodeExtrinsic(raw, false, &e)
+	if err != nil {
+		return Extrinsic{}, err
+	}
+	return e, nil
+}
+
+// decodeExtrinsic decodes the length prefixed extrinsic into e in the format given by its version byte
+func decodeExtrinsic(raw []byte, useMultiAddress bool, e *Extrinsic) error {
+	e.UseMultiAddress = useMultiAddress
+	version := extrinsicVersion(raw)
+	e.Version4 = version == ExtrinsicVersion4
+	e.Version3 = version == ExtrinsicVersion3
+	return scale.NewDecoder(bytes.NewReader(raw)).Decode(e)
+}
+
+// extrinsicVersion returns the version byte of the length prefixed extrinsic, or 0 if it is malformed
+func extrinsicVersion(raw []byte) byte {
+	decoder := scale.NewDecoder(bytes.NewReader(raw))
+	_, err := decoder.DecodeUintCompact()
+	if err != nil {
+		return 0
+	}
+
+	version, err := decoder.ReadOneByte()
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// SignedBlock is a block with its justification, as returned by chain_getBlock
+type SignedBlock struct {
+	Block Block `json:"block"`
+	// Justification is nil if the block was not finalized with a justification
+	Justification *hexutil.Bytes `json:"justification"`
+}
+
+type Chain struct {
+	client Client
+}
+
+func NewChainRPC(client Client) *Chain {
+	return &Chain{client: client}
+}
+
+// GetHeader returns the header of the block with the given hash, or of the latest block if blockHash is nil
+func (c *Chain) GetHeader(blockHash *Hash) (*Header, error) {
+	var h *Header
+	err := c.call(&h, "chain_getHeader", blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if h == nil {
+		return nil, errors.New("header not found")
+	}
+
+	return h, nil
+}
+
+// GetBlock returns the block with the given hash, or the latest block if blockHash is nil
+func (c *Chain) GetBlock(blockHash *Hash) (*SignedBlock, error) {
+	var b *SignedBlock
+	err := c.call(&b, "chain_getBlock", blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if b == nil {
+		return nil, errors.New("block not found")
+	}
+
+	return b, nil
+}
+
+// GetBlockHash returns the hash of the block with the given number
+func (c *Chain) GetBlockHash(number uint64) (Hash, error) {
+	var h Hash
+	err := c.client.Call(&h, "chain_getBlockHash", number)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(h) == 0 {
+		return nil, fmt.Errorf("block #%v not found", number)
+	}
+
+	return h, nil
+}
+
+// MaxBlockHashes is the maximum number of hashes requested by a single GetBlockHashes call
+const MaxBlockHashes = 10000
+
+// GetBlockHashes returns the hashes of the blocks from and to, inclusive, in the order of their numbers. All hashes are
+// requested in a single batch, see Client.CallBatch. Ranges of more than MaxBlockHashes blocks are rejected.
+func (c *Chain) GetBlockHashes(from, to uint64) ([]Hash, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %v to %v", from, to)
+	}
+	if to-from >= MaxBlockHashes {
+		return nil, fmt.Errorf("block range %v to %v exceeds the maximum of %v blocks", from, to, MaxBlockHashes)
+	}
+
+	requests := make([]jsonrpc.Request, 0, to-from+1)
+	for n := from; ; n++ {
+		requests = append(requests, jsonrpc.Request{Method: "chain_getBlockHash", Args: []interface{}{n}})
+		if n == to {
+			break
+		}
+	}
+
+	responses, err := c.client.CallBatch(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]Hash, len(responses))
+	for i, resp := range responses {
+		number := from + uint64(i)
+		err = resp.Decode(&hashes[i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the hash of block #%v: %v", number, err)
+		}
+		if len(hashes[i]) == 0 {
+			return nil, fmt.Errorf("block #%v not found", number)
+		}
+	}
+
+	return hashes, nil
+}
+
+// GetFinalizedHead returns the hash of the last finalized block
+func (c *Chain) GetFinalizedHead() (Hash, error) {
+	var h Hash
+	err := c.client.Call(&h, "chain_getFinalizedHead")
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (c *Chain) call(result interface{}, method string, blockHash *Hash) error {
+	if blockHash == nil {
+		return c.client.Call(result, method)
+	}
+
+	return c.client.Call(result, method, blockHash.String())
+}
+
+// HeadSubscription delivers the headers of new blocks
+type HeadSubscription struct {
+	sub     *jsonrpc.Subscription
+	channel chan Header
+}
+
+// Chan returns the channel the new headers are delivered to, it is closed once the subscription ends
+func (s *HeadSubscription) Chan() <-chan Header {
+	return s.channel
+}
+
+// Err returns a channel that receives the error that ended the subscription, if any
+func (s *HeadSubscription) Err() <-chan error {
+	return s.sub.Err()
+}
+
+// Resubscribed returns a channel that is signalled after the subscription was renewed on a reconnect, headers of
+// blocks imported in the meantime are missing, see jsonrpc.Subscription.Resubscribed
+func (s *HeadSubscription) Resubscribed() <-chan struct{} {
+	return s.sub.Resubscribed()
+}
+
+// Unsubscribe ends the subscription
+func (s *HeadSubscription) Unsubscribe() {
+	s.sub.Unsubscribe()
+}
+
+// SubscribeNewHeads subscribes to the headers of new blocks
+func (c *Chain) SubscribeNewHeads() (*HeadSubscription, error) {
+	ch := make(chan Header)
+	sub, err := c.client.Subscribe(context.Background(), "chain_subscribeNewHeads", "chain_unsubscribeNewHeads", ch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HeadSubscription{sub: sub, channel: ch}, nil
+}
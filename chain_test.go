@@ -0,0 +1,288 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testBlockHash  = "0x7f0d1f4a2d7b3c4e9d9ae0e4f1b5a7d6f3a0c8e2b1d4f6a8c0e2b4d6f8a0c2e4"
+	testParentHash = "0x1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f809"
+	testHeader     = `{"parentHash":"0x1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f809","number":"0x1a4","stateRoot":"0x2e1a3f6c9b0d4e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f","extrinsicsRoot":"0x03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314","digest":{"logs":["0x0661757261201e4e8f0f00000000"]}}`
+	testBlock      = `{"block":{"header":` + testHeader + `,"extrinsics":["0x280402000b10449e516c01"]},"justification":null}`
+)
+
+func TestHeader_UnmarshalJSON(t *testing.T) {
+	var h Header
+	err := json.Unmarshal([]byte(testHeader), &h)
+	assert.NoError(t, err)
+	assert.Equal(t, testParentHash, hexutil.Encode(h.ParentHash))
+	assert.Equal(t, BlockNumber(420), h.Number)
+	assert.Equal(t, "0x03170a2e7597b7b7e3d84c05391d139a62b157e78786d8c082f29dcf4c111314", hexutil.Encode(h.ExtrinsicsRoot))
+	assert.Len(t, h.Digest.Logs, 1)
+}
+
+// testPolkadotHeader is the chain_getHeader result for Polkadot block #1089328, its digest contains the BABE
+// pre-runtime digest of a primary slot claim and the seal of the block author
+const (
+	testPolkadotHeader = `{"parentHash":"0x21dc35454805411be396debf3e1d5aad8d6e9d0d7679cce0cc632ba8a647d07c",` +
+		`"number":"0x109f30",` +
+		`"stateRoot":"0x257b1a7f6bc0287fcbf50676dd29817f2f7ae193cb65b31962e351917406fa23",` +
+		`"extrinsicsRoot":"0x950173af1d9fdcd0be5428fc3eaf05d5f34376bd3882d9a61b348fa2dc641012",` +
+		`"digest":{"logs":[` +
+		`"0x0642414245b501017b000000428edd0f00000000c4fd75c7535d8eec375d70d21cc62262247b599aa67d8a9cf2f7d1b8cb` +
+		`93cd1f9539f04902c33d4c0fe47f723dfed8505d31de1c04d0036a9df233ff902fce0d70060908faa4b3f481e54cbd6a52df` +
+		`c20c3faac82f746d84dc03c2f824a89a0d",` +
+		`"0x0542414245010122041949669a56c8f11b3e3e7c803e477ad24a71ed887bc81c956b59ea8f2b30122e6042494aab60a75e` +
+		`0db8fdff45951e456e6053bd64eb5722600e4a13038b"]}}`
+	testPolkadotHeaderSCALE = "0x21dc35454805411be396debf3e1d5aad8d6e9d0d7679cce0cc632ba8a647d07c" + "c27c4200" +
+		"257b1a7f6bc0287fcbf50676dd29817f2f7ae193cb65b31962e351917406fa23" +
+		"950173af1d9fdcd0be5428fc3eaf05d5f34376bd3882d9a61b348fa2dc641012" +
+		"08" +
+		"0642414245b501017b000000428edd0f00000000c4fd75c7535d8eec375d70d21cc62262247b599aa67d8a9cf2f7d1b8cb93" +
+		"cd1f9539f04902c33d4c0fe47f723dfed8505d31de1c04d0036a9df233ff902fce0d70060908faa4b3f481e54cbd6a52dfc2" +
+		"0c3faac82f746d84dc03c2f824a89a0d" +
+		"0542414245010122041949669a56c8f11b3e3e7c803e477ad24a71ed887bc81c956b59ea8f2b30122e6042494aab60a75e0d" +
+		"b8fdff45951e456e6053bd64eb5722600e4a13038b"
+)
+
+func TestHeader_EncodeDecode(t *testing.T) {
+	var h Header
+	err := json.Unmarshal([]byte(testPolkadotHeader), &h)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x21dc35454805411be396debf3e1d5aad8d6e9d0d7679cce0cc632ba8a647d07c", hexutil.Encode(h.ParentHash))
+	assert.Equal(t, BlockNumber(1089328), h.Number)
+	assert.Equal(t, "0x257b1a7f6bc0287fcbf50676dd29817f2f7ae193cb65b31962e351917406fa23", hexutil.Encode(h.StateRoot))
+	assert.Equal(t, "0x950173af1d9fdcd0be5428fc3eaf05d5f34376bd3882d9a61b348fa2dc641012",
+		hexutil.Encode(h.ExtrinsicsRoot))
+	assert.Equal(t, []hexutil.Bytes{
+		hexutil.MustDecode("0x" + "0642414245b501017b000000428edd0f00000000c4fd75c7535d8eec375d" +
+			"70d21cc62262247b599aa67d8a9cf2f7d1b8cb93cd1f9539f04902c33d4c0fe47f723dfed8505d31de1c04d0036a9df233ff" +
+			"902fce0d70060908faa4b3f481e54cbd6a52dfc20c3faac82f746d84dc03c2f824a89a0d"),
+		hexutil.MustDecode("0x" + "0542414245010122041949669a56c8f11b3e3e7c803e477ad24a71ed887b" +
+			"c81c956b59ea8f2b30122e6042494aab60a75e0db8fdff45951e456e6053bd64eb5722600e4a13038b"),
+	}, h.Digest.Logs)
+
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(h)
+	assert.NoError(t, err)
+	assert.Equal(t, testPolkadotHeaderSCALE, hexutil.Encode(buf.Bytes()))
+
+	var dec Header
+	err = scale.NewDecoder(&buf).Decode(&dec)
+	assert.NoError(t, err)
+	assert.Equal(t, h, dec)
+}
+
+func TestDigest_Decode(t *testing.T) {
+	// other, changes trie root and a changes trie signal with a configuration
+	b := hexutil.MustDecode("0x0c" + "000c010203" + "02" + strings.Repeat("ab", 32) + "070001" +
+		"0200000003000000")
+
+	var d Digest
+	err := scale.NewDecoder(bytes.NewReader(b)).Decode(&d)
+	assert.NoError(t, err)
+	assert.Equal(t, []hexutil.Bytes{
+		hexutil.MustDecode("0x000c010203"),
+		hexutil.MustDecode("0x02" + strings.Repeat("ab", 32)),
+		hexutil.MustDecode("0x0700010200000003000000"),
+	}, d.Logs)
+
+	err = scale.NewDecoder(bytes.NewReader([]byte{0x04, 0x01})).Decode(&d)
+	assert.EqualError(t, err, "unable to decode log #0: unknown digest item 1")
+}
+
+func TestBlockNumber_UnmarshalJSON(t *testing.T) {
+	var n BlockNumber
+	assert.NoError(t, json.Unmarshal([]byte(`"0x0"`), &n))
+	assert.Equal(t, BlockNumber(0), n)
+	assert.NoError(t, json.Unmarshal([]byte(`"0xffffffff"`), &n))
+	assert.Equal(t, BlockNumber(0xffffffff), n)
+	assert.NoError(t, json.Unmarshal([]byte(`42`), &n))
+	assert.Equal(t, BlockNumber(42), n)
+	assert.Error(t, json.Unmarshal([]byte(`"42"`), &n))
+
+	b, err := json.Marshal(BlockNumber(420))
+	assert.NoError(t, err)
+	assert.Equal(t, `"0x1a4"`, string(b))
+}
+
+func TestBlock_DecodeExtrinsics(t *testing.T) {
+	var b SignedBlock
+	err := json.Unmarshal([]byte(testBlock), &b)
+	assert.NoError(t, err)
+
+	extrinsics, err := b.Block.DecodeExtrinsics(false)
+	assert.NoError(t, err)
+	assert.Len(t, extrinsics, 1)
+	assert.False(t, extrinsics[0].IsSigned())
+	assert.True(t, extrinsics[0].Version4)
+	assert.Equal(t, MethodIDX{2, 0}, extrinsics[0].Method.CallIndex)
+	assert.Equal(t, Encoded{0x0b, 0x10, 0x44, 0x9e, 0x51, 0x6c, 0x01}, extrinsics[0].Method.Args)
+
+	// the raw arguments are encoded as they were
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(extrinsics[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(b.Block.Extrinsics[0]), buf.Bytes())
+
+	b.Block.Extrinsics = append(b.Block.Extrinsics, hexutil.Bytes{0x08, 0x84})
+	_, err = b.Block.DecodeExtrinsics(false)
+	assert.EqualError(t, err, "unable to decode extrinsic #1: Encoded length 2 exceeds the 1 remaining bytes")
+}
+
+func TestNewExtrinsicFromHex(t *testing.T) {
+	// the Timestamp.set inherent of testBlock, prefixed with its compact length
+	e, err := NewExtrinsicFromHex("0x280402000b10449e516c01")
+	assert.NoError(t, err)
+	assert.False(t, e.IsSigned())
+	assert.True(t, e.Version4)
+	assert.Equal(t, MethodIDX{2, 0}, e.Method.CallIndex)
+	assert.Equal(t, Encoded{0x0b, 0x10, 0x44, 0x9e, 0x51, 0x6c, 0x01}, e.Method.Args)
+
+	enc, err := scale.EncodeToBytes(e)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x280402000b10449e516c01", hexutil.Encode(enc))
+
+	_, err = NewExtrinsicFromHex("0x0884")
+	assert.EqualError(t, err, "Encoded length 2 exceeds the 1 remaining bytes")
+
+	_, err = NewExtrinsicFromHex("0402")
+	assert.Error(t, err)
+}
+
+func TestChain_GetHeader(t *testing.T) {
+	testServer.AddBlock(testBlockHash, testHeader, testBlock)
+	c := NewChainRPC(testClient)
+
+	h, err := c.GetHeader(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, testParentHash, hexutil.Encode(h.ParentHash))
+
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	h, err = c.GetHeader(&hash)
+	assert.NoError(t, err)
+	assert.Equal(t, BlockNumber(420), h.Number)
+
+	unknown := Hash(hexutil.MustDecode(testParentHash))
+	_, err = c.GetHeader(&unknown)
+	assert.Error(t, err)
+}
+
+func TestChain_GetBlock(t *testing.T) {
+	testServer.AddBlock(testBlockHash, testHeader, testBlock)
+	c := NewChainRPC(testClient)
+
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	b, err := c.GetBlock(&hash)
+	assert.NoError(t, err)
+	assert.Equal(t, BlockNumber(420), b.Block.Header.Number)
+	assert.Len(t, b.Block.Extrinsics, 1)
+	assert.Nil(t, b.Justification)
+}
+
+func TestChain_GetFinalizedHead(t *testing.T) {
+	testServer.SetFinalizedHead(testBlockHash)
+	h, err := NewChainRPC(testClient).GetFinalizedHead()
+	assert.NoError(t, err)
+	assert.Equal(t, testBlockHash, hexutil.Encode(h))
+}
+
+func TestChain_GetBlockHashes(t *testing.T) {
+	for i := uint64(0); i < 3; i++ {
+		testServer.AddBlockHash(i, hexutil.Encode(bytes.Repeat([]byte{byte(i + 1)}, 32)))
+	}
+	c := NewChainRPC(testClient)
+
+	h, err := c.GetBlockHash(1)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash(bytes.Repeat([]byte{2}, 32)), h)
+
+	hashes, err := c.GetBlockHashes(0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 3)
+	for i, h := range hashes {
+		assert.Equal(t, Hash(bytes.Repeat([]byte{byte(i + 1)}, 32)), h)
+	}
+
+	hashes, err = c.GetBlockHashes(2, 2)
+	assert.NoError(t, err)
+	assert.Len(t, hashes, 1)
+
+	_, err = c.GetBlockHash(1000)
+	assert.EqualError(t, err, "block #1000 not found")
+	_, err = c.GetBlockHashes(1, 1000)
+	assert.EqualError(t, err, "block #3 not found")
+	_, err = c.GetBlockHashes(2, 1)
+	assert.EqualError(t, err, "invalid block range 2 to 1")
+	_, err = c.GetBlockHashes(0, MaxBlockHashes)
+	assert.EqualError(t, err, "block range 0 to 10000 exceeds the maximum of 10000 blocks")
+	_, err = c.GetBlockHashes(0, math.MaxUint64)
+	assert.EqualError(t, err, "block range 0 to 18446744073709551615 exceeds the maximum of 10000 blocks")
+}
+
+func TestBlock_DecodeExtrinsics_ecdsa(t *testing.T) {
+	sig := ExtrinsicSignatureV4{Signer: *NewAddress(hexutil.MustDecode(AlicePubKey)), Nonce: 3}
+	sig.Signature.IsEcdsa = true
+	copy(sig.Signature.AsEcdsa[:], bytes.Repeat([]byte{0xab}, 65))
+	body, err := scale.EncodeToBytes(sig)
+	assert.NoError(t, err)
+	raw, err := scale.EncodeToBytes(NewBytes(append(body, 0x01, 0x00, 0xa1, 0x0f)))
+	assert.NoError(t, err)
+
+	b := Block{Extrinsics: []hexutil.Bytes{raw}}
+	extrinsics, err := b.DecodeExtrinsics(false)
+	assert.NoError(t, err)
+	assert.Len(t, extrinsics, 1)
+	assert.True(t, extrinsics[0].IsSigned())
+	assert.Equal(t, sig.Signature, extrinsics[0].SignatureV4.Signature)
+	assert.Equal(t, sig.Signer, extrinsics[0].SignatureV4.Signer)
+	assert.Equal(t, uint64(3), extrinsics[0].SignatureV4.Nonce)
+	assert.Equal(t, MethodIDX{1, 0}, extrinsics[0].Method.CallIndex)
+	assert.Equal(t, Encoded{0xa1, 0x0f}, extrinsics[0].Method.Args)
+}
+
+func TestBlock_DecodeTruncated(t *testing.T) {
+	sig := ExtrinsicSignatureV4{Signer: *NewAddress(hexutil.MustDecode(AlicePubKey)), Nonce: 3}
+	sig.Signature.IsSr25519 = true
+	body, err := scale.EncodeToBytes(sig)
+	assert.NoError(t, err)
+	signed, err := scale.EncodeToBytes(NewBytes(append(body, 0x01, 0x00, 0xa1, 0x0f)))
+	assert.NoError(t, err)
+
+	// every prefix of a header or an extrinsic, as contained in chain_getBlock results, fails to decode
+	header := hexutil.MustDecode(testPolkadotHeaderSCALE)
+	for i := 0; i < len(header); i++ {
+		var h Header
+		err := scale.NewDecoder(bytes.NewReader(header[:i])).Decode(&h)
+		assert.Error(t, err, fmt.Sprintf("prefix of %v bytes of the header", i))
+	}
+	for _, raw := range [][]byte{hexutil.MustDecode("0x280402000b10449e516c01"), signed} {
+		for i := 0; i < len(raw); i++ {
+			_, err := Block{Extrinsics: []hexutil.Bytes{raw[:i]}}.DecodeExtrinsics(false)
+			assert.Error(t, err, fmt.Sprintf("prefix of %v bytes of %#x", i, raw))
+		}
+	}
+
+	// lengths that claim more than there is are rejected before anything is allocated for them
+	huge := []byte{0x03, 0xff, 0xff, 0xff, 0xff}
+	var e Extrinsic
+	err = scale.NewDecoder(bytes.NewReader(huge)).Decode(&e)
+	assert.EqualError(t, err, "Encoded length 4294967295 exceeds the 0 remaining bytes")
+	var d Digest
+	err = scale.NewDecoder(bytes.NewReader(huge)).Decode(&d)
+	assert.EqualError(t, err, "Encoded length 4294967295 exceeds the 0 remaining bytes")
+	var v BitVec
+	err = scale.NewDecoder(bytes.NewReader(huge)).Decode(&v)
+	assert.EqualError(t, err, "BitVec of 4294967295 bits exceeds the 0 remaining bytes")
+}
@@ -1,7 +1,15 @@
 package substrate
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+
 	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 )
 
@@ -13,14 +21,232 @@ func (h *Hash) String() string {
 	return hexutil.Encode(b[:])
 }
 
-/**
-const PREFIX_1BYTE = 0xef;
-const PREFIX_2BYTE = 0xfc;
-const PREFIX_4BYTE = 0xfd;
-const PREFIX_8BYTE = 0xfe;
-*/
+// UnmarshalJSON decodes a hex encoded hash, null is decoded as an empty hash
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return err
+	}
+
+	*h = b
+	return nil
+}
+
+func (h Hash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hexutil.Encode(h))
+}
+
+// Decode reads a 32 byte hash, hashes are fixed size and SCALE encoded without a length prefix
+func (h *Hash) Decode(decoder scale.Decoder) error {
+	b := make([]byte, 32)
+	err := decoder.Read(b)
+	if err != nil {
+		return err
+	}
+
+	*h = b
+	return nil
+}
+
+func (h Hash) Encode(encoder scale.Encoder) error {
+	return encoder.Write(h)
+}
+
+// DecodeHashes decodes a SCALE encoded Vec<Hash>, eg: the ancestry of a GRANDPA justification. It fails if bytes are
+// left after the hashes.
+func DecodeHashes(raw []byte) ([]Hash, error) {
+	r := bytes.NewReader(raw)
+	var hashes []Hash
+	err := scale.NewDecoder(r).Decode(&hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("%v bytes left after decoding the hashes", r.Len())
+	}
+	return hashes, nil
+}
+
+// Bytes is a Vec<u8>, it is SCALE encoded with a compact length prefix. Fixed size values such as a [u8; 32] have no
+// prefix and must be written with Encoder.Write instead, see Hash and AccountID.
+type Bytes []byte
+
+func NewBytes(b []byte) Bytes {
+	return Bytes(b)
+}
+
+func (b *Bytes) Decode(decoder scale.Decoder) error {
+	l, err := decoder.DecodeLength()
+	if err != nil {
+		return err
+	}
+
+	v := make([]byte, l)
+	err = decoder.Read(v)
+	if err != nil {
+		return err
+	}
+
+	*b = v
+	return nil
+}
+
+func (b Bytes) Encode(encoder scale.Encoder) error {
+	err := encoder.EncodeUintCompact(uint64(len(b)))
+	if err != nil {
+		return err
+	}
+
+	return encoder.Write(b)
+}
+
+// Text is a Vec<u8> that holds UTF-8 text, such as the names and docs in the metadata. It is encoded as Bytes.
+type Text string
+
+func NewText(s string) Text {
+	return Text(s)
+}
+
+func (t *Text) Decode(decoder scale.Decoder) error {
+	var b Bytes
+	err := decoder.Decode(&b)
+	if err != nil {
+		return err
+	}
+
+	if !utf8.Valid(b) {
+		return errors.New("text is not valid UTF-8")
+	}
+
+	*t = Text(b)
+	return nil
+}
+
+func (t Text) Encode(encoder scale.Encoder) error {
+	return encoder.Encode(Bytes(t))
+}
+
+// Encoded are bytes that are already SCALE encoded, eg: call arguments produced elsewhere. They are written as they
+// are, without a length prefix. Decode reads as many bytes as Encoded holds, or the rest of the input if it is empty.
+type Encoded []byte
+
+func (e *Encoded) Decode(decoder scale.Decoder) error {
+	if len(*e) > 0 {
+		return decoder.Read(*e)
+	}
+
+	b, err := decoder.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	*e = b
+	return nil
+}
+
+func (e Encoded) Encode(encoder scale.Encoder) error {
+	return encoder.Write(e)
+}
+
+// H160 is a 20 byte hash, eg: an Ethereum address on EVM compatible chains
+type H160 [20]byte
+
+// NewH160FromHex decodes a hex encoded 20 byte hash, eg: an Ethereum address
+func NewH160FromHex(s string) (H160, error) {
+	var h H160
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return h, err
+	}
+	if len(b) != len(h) {
+		return h, fmt.Errorf("expected %d bytes, got %d", len(h), len(b))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// Hex returns the hash hex encoded with 0x prefix
+func (h H160) Hex() string {
+	return hexutil.Encode(h[:])
+}
+
+func (h *H160) Decode(decoder scale.Decoder) error {
+	return decoder.Read(h[:])
+}
+
+func (h H160) Encode(encoder scale.Encoder) error {
+	return encoder.Write(h[:])
+}
+
+// H512 is a 64 byte hash
+type H512 [64]byte
+
+// NewH512FromHex decodes a hex encoded 64 byte hash
+func NewH512FromHex(s string) (H512, error) {
+	var h H512
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return h, err
+	}
+	if len(b) != len(h) {
+		return h, fmt.Errorf("expected %d bytes, got %d", len(h), len(b))
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// Hex returns the hash hex encoded with 0x prefix
+func (h H512) Hex() string {
+	return hexutil.Encode(h[:])
+}
+
+func (h *H512) Decode(decoder scale.Decoder) error {
+	return decoder.Read(h[:])
+}
+
+func (h H512) Encode(encoder scale.Encoder) error {
+	return encoder.Write(h[:])
+}
+
+// AccountID is the 32 byte public key of an account
+type AccountID [32]byte
+
+// ToSS58 returns the SS58 address of the account for the given network prefix, eg: ss58.SubstratePrefix
+func (a AccountID) ToSS58(networkPrefix uint8) string {
+	return ss58.Encode(a[:], networkPrefix)
+}
+
+func (a *AccountID) Decode(decoder scale.Decoder) error {
+	return decoder.Read(a[:])
+}
+
+func (a AccountID) Encode(encoder scale.Encoder) error {
+	return encoder.Write(a[:])
+}
+
+// Address is the address of an account in runtimes without MultiAddress, either its public key or its account index
+// from the indices module. Indexes are compressed: those below 0xf0 take a single byte, larger ones are prefixed
+// with 0xfc for a u16 or 0xfd for a u32. Public keys are prefixed with 0xff.
 type Address struct {
-	PubKey [32]byte
+	PubKey         [32]byte
+	IsAccountIndex bool
+	AsAccountIndex uint32
+}
+
+// NewAddressFromAccountIndex creates an address that points to an account by its index
+func NewAddressFromAccountIndex(index uint32) Address {
+	return Address{IsAccountIndex: true, AsAccountIndex: index}
 }
 
 func NewAddress(b []byte) *Address {
@@ -29,26 +255,177 @@ func NewAddress(b []byte) *Address {
 	return s
 }
 
+// NewAddressFromSS58 decodes an SS58 address of one of the supported networks (polkadot, kusama or substrate)
+func NewAddressFromSS58(s string) (Address, error) {
+	for _, prefix := range []uint8{ss58.PolkadotPrefix, ss58.KusamaPrefix, ss58.SubstratePrefix} {
+		a, err := NewAddressFromSS58WithPrefix(s, prefix)
+		if err == nil {
+			return a, nil
+		}
+	}
+	return Address{}, fmt.Errorf("%s is not a polkadot, kusama or substrate address", s)
+}
+
+// NewAddressFromSS58WithPrefix decodes an SS58 address and rejects it if it doesn't belong to the given network
+func NewAddressFromSS58WithPrefix(s string, networkPrefix uint8) (Address, error) {
+	pubKey, prefix, err := ss58.Decode(s)
+	if err != nil {
+		return Address{}, err
+	}
+
+	if prefix != networkPrefix {
+		return Address{}, fmt.Errorf("expected network prefix %d, got %d", networkPrefix, prefix)
+	}
+
+	return *NewAddress(pubKey), nil
+}
+
+// AccountID returns the account id (public key) the address points to
+func (a Address) AccountID() AccountID {
+	return AccountID(a.PubKey)
+}
+
 func (a *Address) Decode(decoder scale.Decoder) error {
-	err := decoder.Read(a.PubKey[:])
+	b, err := decoder.ReadOneByte()
 	if err != nil {
 		return err
 	}
-	return nil
+
+	switch {
+	case b == 0xff:
+		a.IsAccountIndex = false
+		return decoder.Read(a.PubKey[:])
+	case b < 0xf0:
+		a.IsAccountIndex = true
+		a.AsAccountIndex = uint32(b)
+		return nil
+	case b == 0xfc:
+		var i uint16
+		err = decoder.Decode(&i)
+		a.IsAccountIndex = true
+		a.AsAccountIndex = uint32(i)
+		return err
+	case b == 0xfd:
+		a.IsAccountIndex = true
+		return decoder.Decode(&a.AsAccountIndex)
+	}
+	return fmt.Errorf("unsupported address prefix %#x", b)
 }
 
 func (a Address) Encode(encoder scale.Encoder) error {
-	// type of address - public key
-	err := encoder.Write([]byte{255})
-	if err != nil {
-		return err
+	var err error
+	switch {
+	case !a.IsAccountIndex:
+		err = encoder.PushByte(0xff)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(a.PubKey[:])
+	case a.AsAccountIndex < 0xf0:
+		return encoder.PushByte(byte(a.AsAccountIndex))
+	case a.AsAccountIndex <= math.MaxUint16:
+		err = encoder.PushByte(0xfc)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(uint16(a.AsAccountIndex))
+	default:
+		err = encoder.PushByte(0xfd)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(a.AsAccountIndex)
 	}
+}
+
+// MultiAddress is the address format of newer runtimes, it replaces Address. It is either an account id, an account
+// index, raw bytes or a 32 or 20 byte address.
+type MultiAddress struct {
+	IsID        bool
+	AsID        AccountID
+	IsIndex     bool
+	AsIndex     uint32
+	IsRaw       bool
+	AsRaw       []byte
+	IsAddress32 bool
+	AsAddress32 [32]byte
+	IsAddress20 bool
+	AsAddress20 [20]byte
+}
 
-	err = encoder.Write(a.PubKey[:])
+// NewMultiAddressFromAccountID creates a MultiAddress that points to the account with the given public key
+func NewMultiAddressFromAccountID(b []byte) MultiAddress {
+	m := MultiAddress{IsID: true}
+	copy(m.AsID[:], b)
+	return m
+}
+
+func (m *MultiAddress) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
 	if err != nil {
 		return err
 	}
-	return nil
+
+	switch b {
+	case 0:
+		m.IsID = true
+		return decoder.Decode(&m.AsID)
+	case 1:
+		m.IsIndex = true
+		i, err := decoder.DecodeUintCompact()
+		if err != nil {
+			return err
+		}
+		m.AsIndex = uint32(i)
+		return nil
+	case 2:
+		m.IsRaw = true
+		return decoder.Decode(&m.AsRaw)
+	case 3:
+		m.IsAddress32 = true
+		return decoder.Read(m.AsAddress32[:])
+	case 4:
+		m.IsAddress20 = true
+		return decoder.Read(m.AsAddress20[:])
+	}
+	return fmt.Errorf("unknown multi address %d", b)
+}
+
+func (m MultiAddress) Encode(encoder scale.Encoder) error {
+	var err error
+	switch {
+	case m.IsID:
+		err = encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(m.AsID)
+	case m.IsIndex:
+		err = encoder.PushByte(1)
+		if err != nil {
+			return err
+		}
+		return encoder.EncodeUintCompact(uint64(m.AsIndex))
+	case m.IsRaw:
+		err = encoder.PushByte(2)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(m.AsRaw)
+	case m.IsAddress32:
+		err = encoder.PushByte(3)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(m.AsAddress32[:])
+	case m.IsAddress20:
+		err = encoder.PushByte(4)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(m.AsAddress20[:])
+	}
+	return errors.New("multi address not set")
 }
 
 type Index uint64
@@ -0,0 +1,312 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountID_DecodeVec(t *testing.T) {
+	// Session.Validators, a Vec<AccountId> with Alice and Bob
+	b, _ := hexutil.Decode("0x08d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d" +
+		"8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48")
+
+	var validators []AccountID
+	err := scale.NewDecoder(bytes.NewReader(b)).Decode(&validators)
+	assert.NoError(t, err)
+	assert.Len(t, validators, 2)
+	assert.Equal(t, "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY", validators[0].ToSS58(42))
+	assert.Equal(t, "5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty", validators[1].ToSS58(42))
+
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(validators)
+	assert.NoError(t, err)
+	assert.Equal(t, b, buf.Bytes())
+}
+
+func TestTupleVec_EncodeDecode(t *testing.T) {
+	alice, _ := hexutil.Decode(AlicePubKey)
+	var aliceID AccountID
+	copy(aliceID[:], alice)
+
+	// a Vec<(AccountId, u128)>, the struct fields are encoded as a tuple
+	type balance struct {
+		Who    AccountID
+		Amount U128
+	}
+	max, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	balances := []balance{{aliceID, NewU128(big.NewInt(1000))}, {AccountID{0x01}, NewU128(max)}}
+	b, err := scale.EncodeToBytes(balances)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x08"+AlicePubKey[2:]+"e8030000000000000000000000000000"+
+		"01"+strings.Repeat("00", 31)+strings.Repeat("ff", 16), hexutil.Encode(b))
+
+	var dec []balance
+	err = scale.NewDecoder(bytes.NewReader(b)).Decode(&dec)
+	assert.NoError(t, err)
+	assert.Len(t, dec, 2)
+	assert.Equal(t, aliceID, dec[0].Who)
+	assert.Equal(t, 0, big.NewInt(1000).Cmp(dec[0].Amount.Int))
+	assert.Equal(t, 0, max.Cmp(dec[1].Amount.Int))
+
+	// a Vec<(AccountId, Compact<Balance>)>, eg: the others of a staking exposure
+	type exposure struct {
+		Who   AccountID
+		Value UCompact
+	}
+	others := []exposure{{aliceID, NewUCompact(big.NewInt(1))}, {aliceID, NewUCompact(big.NewInt(1000))}}
+	b, err = scale.EncodeToBytes(others)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x08"+AlicePubKey[2:]+"04"+AlicePubKey[2:]+"a10f", hexutil.Encode(b))
+
+	var exposures []exposure
+	err = scale.NewDecoder(bytes.NewReader(b)).Decode(&exposures)
+	assert.NoError(t, err)
+	assert.Len(t, exposures, 2)
+	assert.Equal(t, int64(1), exposures[0].Value.Int64())
+	assert.Equal(t, int64(1000), exposures[1].Value.Int64())
+	assert.Equal(t, aliceID, exposures[1].Who)
+}
+
+func TestDecodeHashes(t *testing.T) {
+	hashes := []Hash{Hash(bytes.Repeat([]byte{0x01}, 32)), Hash(bytes.Repeat([]byte{0x02}, 32))}
+	b, err := scale.EncodeToBytes(hashes)
+	assert.NoError(t, err)
+	// a compact length, the hashes have no prefix
+	assert.Equal(t, "0x08"+strings.Repeat("01", 32)+strings.Repeat("02", 32), hexutil.Encode(b))
+
+	dec, err := DecodeHashes(b)
+	assert.NoError(t, err)
+	assert.Equal(t, hashes, dec)
+
+	dec, err = DecodeHashes([]byte{0x00})
+	assert.NoError(t, err)
+	assert.Len(t, dec, 0)
+
+	_, err = DecodeHashes(append(b, 0xff))
+	assert.EqualError(t, err, "1 bytes left after decoding the hashes")
+
+	_, err = DecodeHashes(b[:40])
+	assert.Error(t, err)
+}
+
+func TestBytes_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		value   Bytes
+		encoded string
+	}{
+		{NewBytes([]byte{}), "0x00"},
+		{NewBytes([]byte{0xab, 0xcd}), "0x08abcd"},
+		{NewBytes(bytes.Repeat([]byte{0x01}, 64)), "0x0101" + strings.Repeat("01", 64)},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.value)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, hexutil.Encode(buf.Bytes()))
+
+		var dec Bytes
+		err = scale.NewDecoder(&buf).Decode(&dec)
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, dec)
+	}
+
+	// fixed size values like the anchor params are written without the length
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Write([]byte{0xab, 0xcd})
+	assert.NoError(t, err)
+	err = scale.NewEncoder(&buf).Encode(NewBytes([]byte{0xab, 0xcd}))
+	assert.NoError(t, err)
+	assert.Equal(t, "0xabcd08abcd", hexutil.Encode(buf.Bytes()))
+
+	var dec Bytes
+	err = scale.NewDecoder(bytes.NewReader([]byte{0x08, 0xab})).Decode(&dec)
+	assert.Error(t, err)
+}
+
+func TestText_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		value   Text
+		encoded string
+	}{
+		{NewText(""), "0x00"},
+		{NewText("Balances"), "0x2042616c616e636573"},
+		{NewText("grüß"), "0x186772c3bcc39f"},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.value)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, hexutil.Encode(buf.Bytes()))
+
+		var dec Text
+		err = scale.NewDecoder(&buf).Decode(&dec)
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, dec)
+	}
+
+	var dec Text
+	err := scale.NewDecoder(bytes.NewReader([]byte{0x04, 0xff})).Decode(&dec)
+	assert.EqualError(t, err, "text is not valid UTF-8")
+}
+
+func TestEncoded_EncodeDecode(t *testing.T) {
+	// a call with pre-encoded arguments
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(Method{CallIndex: MethodIDX{1, 0}, Args: Encoded{0xa1, 0x0f}})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x00, 0xa1, 0x0f}, buf.Bytes())
+
+	// a known length
+	dec := make(Encoded, 2)
+	err = scale.NewDecoder(bytes.NewReader([]byte{0x01, 0x00, 0xa1, 0x0f})).Decode(&dec)
+	assert.NoError(t, err)
+	assert.Equal(t, Encoded{0x01, 0x00}, dec)
+
+	// the rest of the input
+	decoder := scale.NewDecoder(bytes.NewReader([]byte{0x01, 0x00, 0xa1, 0x0f}))
+	var idx MethodIDX
+	err = decoder.Decode(&idx)
+	assert.NoError(t, err)
+	var rest Encoded
+	err = decoder.Decode(&rest)
+	assert.NoError(t, err)
+	assert.Equal(t, Encoded{0xa1, 0x0f}, rest)
+
+	dec = make(Encoded, 3)
+	err = scale.NewDecoder(bytes.NewReader([]byte{0x01})).Decode(&dec)
+	assert.Error(t, err)
+}
+
+func TestAddress_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		address Address
+		encoded string
+	}{
+		{*NewAddress(hexutil.MustDecode(AlicePubKey)), "0xff" + AlicePubKey[2:]},
+		{NewAddressFromAccountIndex(0), "0x00"},
+		{NewAddressFromAccountIndex(0xef), "0xef"},
+		{NewAddressFromAccountIndex(0xf0), "0xfcf000"},
+		{NewAddressFromAccountIndex(0xffff), "0xfcffff"},
+		{NewAddressFromAccountIndex(0x10000), "0xfd00000100"},
+		{NewAddressFromAccountIndex(0xffffffff), "0xfdffffffff"},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.address)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, hexutil.Encode(buf.Bytes()))
+
+		var decoded Address
+		err = scale.NewDecoder(&buf).Decode(&decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, test.address, decoded)
+	}
+
+	var decoded Address
+	err := scale.NewDecoder(bytes.NewReader([]byte{0xfe, 1, 0, 0, 0, 0, 0, 0, 0})).Decode(&decoded)
+	assert.EqualError(t, err, "unsupported address prefix 0xfe")
+	err = scale.NewDecoder(bytes.NewReader([]byte{0xfc, 1})).Decode(&decoded)
+	assert.Error(t, err)
+}
+
+func TestMultiAddress_EncodeDecode(t *testing.T) {
+	alice := hexutil.MustDecode(AlicePubKey)
+	var address32 [32]byte
+	copy(address32[:], alice)
+	var address20 [20]byte
+	copy(address20[:], alice)
+
+	for _, test := range []struct {
+		address MultiAddress
+		encoded string
+	}{
+		{NewMultiAddressFromAccountID(alice), "0x00" + AlicePubKey[2:]},
+		{MultiAddress{IsIndex: true, AsIndex: 70000}, "0x01c2450400"},
+		{MultiAddress{IsRaw: true, AsRaw: []byte{1, 2, 3}}, "0x020c010203"},
+		{MultiAddress{IsAddress32: true, AsAddress32: address32}, "0x03" + AlicePubKey[2:]},
+		{MultiAddress{IsAddress20: true, AsAddress20: address20}, "0x04" + AlicePubKey[2:42]},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.address)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, hexutil.Encode(buf.Bytes()))
+
+		var decoded MultiAddress
+		err = scale.NewDecoder(&buf).Decode(&decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, test.address, decoded)
+	}
+
+	var decoded MultiAddress
+	err := scale.NewDecoder(bytes.NewReader([]byte{5})).Decode(&decoded)
+	assert.EqualError(t, err, "unknown multi address 5")
+}
+
+func TestTuple_EncodeDecode(t *testing.T) {
+	// (Compact<Balance>, AccountId) without a hand written codec
+	type transfer struct {
+		Value   UCompact
+		Account AccountID
+	}
+	value := transfer{
+		Value:   NewUCompact(big.NewInt(1000000000000)),
+		Account: NewAddress(hexutil.MustDecode(AlicePubKey)).AccountID(),
+	}
+
+	b, err := scale.EncodeToBytes(value)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x070010a5d4e8"+AlicePubKey[2:], hexutil.Encode(b))
+
+	var decoded transfer
+	err = scale.DecodeFromBytes(b, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, value.Value.Cmp(decoded.Value.Int))
+	assert.Equal(t, value.Account, decoded.Account)
+}
+
+func TestH160_EncodeDecode(t *testing.T) {
+	h, err := NewH160FromHex("0x6be02d1d3665660d22ff9624b7be0551ee1ac91b")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x6be02d1d3665660d22ff9624b7be0551ee1ac91b", h.Hex())
+
+	b, err := scale.EncodeToBytes(h)
+	assert.NoError(t, err)
+	assert.Equal(t, h[:], b)
+
+	var dec H160
+	err = scale.DecodeFromBytes(b, &dec)
+	assert.NoError(t, err)
+	assert.Equal(t, h, dec)
+
+	// a Vec<H160> has no prefixes for the items
+	b, err = scale.EncodeToBytes([]H160{h, h})
+	assert.NoError(t, err)
+	assert.Len(t, b, 41)
+
+	_, err = NewH160FromHex("0x6be02d1d")
+	assert.EqualError(t, err, "expected 20 bytes, got 4")
+	_, err = NewH160FromHex("6be02d1d3665660d22ff9624b7be0551ee1ac91b")
+	assert.Error(t, err)
+}
+
+func TestH512_EncodeDecode(t *testing.T) {
+	h, err := NewH512FromHex("0x" + strings.Repeat("ab", 64))
+	assert.NoError(t, err)
+
+	b, err := scale.EncodeToBytes(h)
+	assert.NoError(t, err)
+	assert.Equal(t, h[:], b)
+
+	var dec H512
+	err = scale.DecodeFromBytes(b, &dec)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x"+strings.Repeat("ab", 64), dec.Hex())
+
+	_, err = NewH512FromHex("0xab")
+	assert.EqualError(t, err, "expected 64 bytes, got 1")
+}
@@ -4,51 +4,111 @@ import (
 	"context"
 	"sync"
 
-	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/centrifuge/go-substrate-rpc-client/jsonrpc"
 )
 
 type Client interface {
 	Call(result interface{}, method string, args ...interface{}) error
 
-	Subscribe(ctx context.Context, namespace string, channel interface{}, args ...interface{}) (*rpc.ClientSubscription, error)
+	// CallContext is like Call, but gives up waiting for the response once ctx is done and returns ctx.Err()
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+
+	// Subscribe calls subscribeMethod and delivers the notifications of the subscription to channel, see
+	// jsonrpc.Client.Subscribe
+	Subscribe(ctx context.Context, subscribeMethod, unsubscribeMethod string, channel interface{},
+		args ...interface{}) (*jsonrpc.Subscription, error)
+
+	// CallBatch performs all requests in a single round trip, see jsonrpc.Client.CallBatch
+	CallBatch(requests []jsonrpc.Request) ([]jsonrpc.Response, error)
 
 	MetaData(cache bool) (*MetadataVersioned, error)
+
+	// MetaDataForSpecVersion is like MetaData with cache, but fetches the metadata at blockHash again once the spec
+	// version of the runtime changes
+	MetaDataForSpecVersion(specVersion uint32, blockHash Hash) (*MetadataVersioned, error)
+}
+
+// rpcClient is the transport of a client, either a websocket or an HTTP client
+type rpcClient interface {
+	Call(result interface{}, method string, args ...interface{}) error
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	Subscribe(ctx context.Context, subscribeMethod, unsubscribeMethod string, channel interface{},
+		args ...interface{}) (*jsonrpc.Subscription, error)
+	CallBatch(requests []jsonrpc.Request) ([]jsonrpc.Response, error)
 }
 
 type client struct {
-	*rpc.Client
+	rpcClient
 
 	// metadataVersioned is the metadata cache to prevent unnecessary requests
 	metadataVersioned *MetadataVersioned
+	// metadataSpecVersion is the spec version of the runtime the cached metadata belongs to, 0 if it is unknown
+	metadataSpecVersion uint32
 
 	metadataLock sync.RWMutex
 }
 
-func (c *client) MetaData(cache bool) (m *MetadataVersioned, err error) {
-	if cache && c.metadataVersioned != nil {
+func (c *client) MetaData(cache bool) (*MetadataVersioned, error) {
+	if cache {
 		c.metadataLock.RLock()
-		defer c.metadataLock.RUnlock()
-		m = c.metadataVersioned
-	} else {
-		s := NewStateRPC(c)
-		m, err = s.MetaData(nil)
-		if err != nil {
-			return nil, err
+		m := c.metadataVersioned
+		c.metadataLock.RUnlock()
+		if m != nil {
+			return m, nil
 		}
-		// set cache
-		c.metadataLock.Lock()
-		defer c.metadataLock.Unlock()
-		c.metadataVersioned = m
 	}
-	return
+
+	return c.fetchMetaData(0, nil)
+}
+
+// MetaDataForSpecVersion returns the cached metadata if it belongs to the runtime of specVersion, otherwise the
+// metadata is fetched at blockHash and cached again. Pass the spec version of the runtime version at blockHash, eg:
+// from State.GetRuntimeVersion, to refresh the cache after runtime upgrades. Fetching at the same block ensures the
+// metadata belongs to the runtime of specVersion, even if an upgrade is enacted in between.
+func (c *client) MetaDataForSpecVersion(specVersion uint32, blockHash Hash) (*MetadataVersioned, error) {
+	c.metadataLock.RLock()
+	m, cached := c.metadataVersioned, c.metadataSpecVersion
+	c.metadataLock.RUnlock()
+	if m != nil && cached == specVersion {
+		return m, nil
+	}
+
+	return c.fetchMetaData(specVersion, blockHash)
+}
+
+// fetchMetaData fetches the metadata at blockHash, or of the best block if it is nil, and caches it for the runtime
+// of specVersion
+func (c *client) fetchMetaData(specVersion uint32, blockHash Hash) (*MetadataVersioned, error) {
+	m, err := NewStateRPC(c).MetaData(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	m.BuildCallIndex()
+
+	c.metadataLock.Lock()
+	defer c.metadataLock.Unlock()
+	c.metadataVersioned = m
+	c.metadataSpecVersion = specVersion
+	return m, nil
+}
+
+// Connect connects to the websocket endpoint of a node, eg: ws://127.0.0.1:9944. The client reconnects if the
+// connection is lost, see the jsonrpc options to configure it. Calls wait forever for their response unless a
+// timeout is set with jsonrpc.WithTimeout.
+func Connect(url string, opts ...jsonrpc.Option) (Client, error) {
+	c, err := jsonrpc.Dial(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &client{rpcClient: c}, nil
 }
 
-// Connect
-func Connect(url string) (Client, error) {
-	c, err := rpc.Dial(url)
+// ConnectHTTP creates a client for the HTTP endpoint of a node, eg: http://127.0.0.1:9933. It supports all calls,
+// subscriptions fail with jsonrpc.ErrSubscriptionsNotSupported.
+func ConnectHTTP(url string, opts ...jsonrpc.Option) (Client, error) {
+	c, err := jsonrpc.DialHTTP(url, opts...)
 	if err != nil {
 		return nil, err
 	}
-	cc := client{c, nil, sync.RWMutex{}}
-	return &cc, nil
+	return &client{rpcClient: c}, nil
 }
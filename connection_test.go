@@ -0,0 +1,57 @@
+// +build tests
+
+package substrate
+
+import (
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/testrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// metadataClient serves the test metadata, counts the calls and records their arguments
+type metadataClient struct {
+	countingClient
+	args []interface{}
+}
+
+func (c *metadataClient) Call(result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	c.args = args
+	*result.(*string) = testrpc.GetTestMetaData()
+	return nil
+}
+
+func TestClient_MetaData_cache(t *testing.T) {
+	rpc := &metadataClient{}
+	c := &client{rpcClient: rpc}
+
+	m, err := c.MetaData(true)
+	assert.NoError(t, err)
+	assert.Equal(t, "system", m.Metadata.Modules[0].Name)
+	cached, err := c.MetaData(true)
+	assert.NoError(t, err)
+	assert.True(t, m == cached)
+	assert.Equal(t, 1, rpc.calls)
+
+	// the spec version of the cached metadata is unknown
+	m, err = c.MetaDataForSpecVersion(1, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rpc.calls)
+	assert.Empty(t, rpc.args)
+	cached, err = c.MetaDataForSpecVersion(1, nil)
+	assert.NoError(t, err)
+	assert.True(t, m == cached)
+	assert.Equal(t, 2, rpc.calls)
+
+	// runtime upgrade, the metadata is fetched at the block the spec version was read at
+	cached, err = c.MetaDataForSpecVersion(2, Hash{0x01, 0x02})
+	assert.NoError(t, err)
+	assert.False(t, m == cached)
+	assert.Equal(t, 3, rpc.calls)
+	assert.Equal(t, []interface{}{"0x0102"}, rpc.args)
+
+	_, err = c.MetaData(false)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, rpc.calls)
+}
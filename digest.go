@@ -0,0 +1,267 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Digest contains the logs of a block header, each log is a SCALE encoded DigestItem
+type Digest struct {
+	Logs []hexutil.Bytes `json:"logs"`
+}
+
+func (d *Digest) Decode(decoder scale.Decoder) error {
+	n, err := decoder.DecodeLength()
+	if err != nil {
+		return err
+	}
+
+	d.Logs = make([]hexutil.Bytes, n)
+	for i := range d.Logs {
+		d.Logs[i], err = decodeDigestItem(decoder)
+		if err != nil {
+			return fmt.Errorf("unable to decode log #%v: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (d Digest) Encode(encoder scale.Encoder) error {
+	err := encoder.EncodeUintCompact(uint64(len(d.Logs)))
+	if err != nil {
+		return err
+	}
+
+	for _, l := range d.Logs {
+		err = encoder.Write(l)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeDigestItem reads a single DigestItem and returns its SCALE encoding
+func decodeDigestItem(decoder scale.Decoder) ([]byte, error) {
+	bb := new(bytes.Buffer)
+	encoder := scale.NewEncoder(bb)
+
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.PushByte(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var size int
+	var hasData bool
+	switch b {
+	case 0: // Other
+		hasData = true
+	case 2: // ChangesTrieRoot
+		size = 32
+	case 4, 5, 6: // Consensus, Seal and PreRuntime with the consensus engine id
+		size = 4
+		hasData = true
+	case 7: // ChangesTrieSignal with an optional configuration of two u32
+		signal := make([]byte, 2)
+		err = decoder.Read(signal)
+		if err != nil {
+			return nil, err
+		}
+		if signal[1] == 1 {
+			size = 8
+		}
+		err = encoder.Write(signal)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown digest item %d", b)
+	}
+
+	fixed := make([]byte, size)
+	err = decoder.Read(fixed)
+	if err != nil {
+		return nil, err
+	}
+	err = encoder.Write(fixed)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasData {
+		var data []byte
+		err = decoder.Decode(&data)
+		if err != nil {
+			return nil, err
+		}
+		err = encoder.Encode(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return bb.Bytes(), nil
+}
+
+// Items decodes the logs, logs of unknown types are returned as IsOther with the raw log as AsOther
+func (d Digest) Items() ([]DigestItem, error) {
+	items := make([]DigestItem, len(d.Logs))
+	for i, l := range d.Logs {
+		if len(l) > 0 && !isKnownDigestItem(l[0]) {
+			items[i] = DigestItem{IsOther: true, AsOther: l}
+			continue
+		}
+
+		err := scale.NewDecoder(bytes.NewReader(l)).Decode(&items[i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode log #%v: %v", i, err)
+		}
+	}
+	return items, nil
+}
+
+// DigestItem is a log of a block header, eg: the BABE or Aura slot in the pre-runtime digest
+type DigestItem struct {
+	IsOther             bool
+	AsOther             []byte
+	IsChangesTrieRoot   bool
+	AsChangesTrieRoot   Hash
+	IsConsensus         bool
+	AsConsensus         ConsensusEngineData
+	IsSeal              bool
+	AsSeal              ConsensusEngineData
+	IsPreRuntime        bool
+	AsPreRuntime        ConsensusEngineData
+	IsChangesTrieSignal bool
+	// AsChangesTrieSignal is the SCALE encoded signal
+	AsChangesTrieSignal []byte
+}
+
+func isKnownDigestItem(b byte) bool {
+	return b == 0 || b == 2 || b == 4 || b == 5 || b == 6 || b == 7
+}
+
+func (d *DigestItem) Decode(decoder scale.Decoder) error {
+	// read the item into a buffer first, the changes trie signal is kept encoded
+	b, err := decodeDigestItem(decoder)
+	if err != nil {
+		return err
+	}
+
+	dec := scale.NewDecoder(bytes.NewReader(b[1:]))
+	switch b[0] {
+	case 0:
+		d.IsOther = true
+		return dec.Decode(&d.AsOther)
+	case 2:
+		d.IsChangesTrieRoot = true
+		return dec.Decode(&d.AsChangesTrieRoot)
+	case 4:
+		d.IsConsensus = true
+		return dec.Decode(&d.AsConsensus)
+	case 5:
+		d.IsSeal = true
+		return dec.Decode(&d.AsSeal)
+	case 6:
+		d.IsPreRuntime = true
+		return dec.Decode(&d.AsPreRuntime)
+	case 7:
+		d.IsChangesTrieSignal = true
+		d.AsChangesTrieSignal = b[1:]
+		return nil
+	}
+	return fmt.Errorf("unknown digest item %d", b[0])
+}
+
+func (d DigestItem) Encode(encoder scale.Encoder) error {
+	var err error
+	switch {
+	case d.IsOther:
+		err = encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(d.AsOther)
+	case d.IsChangesTrieRoot:
+		err = encoder.PushByte(2)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(d.AsChangesTrieRoot)
+	case d.IsConsensus:
+		err = encoder.PushByte(4)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(d.AsConsensus)
+	case d.IsSeal:
+		err = encoder.PushByte(5)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(d.AsSeal)
+	case d.IsPreRuntime:
+		err = encoder.PushByte(6)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(d.AsPreRuntime)
+	case d.IsChangesTrieSignal:
+		err = encoder.PushByte(7)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(d.AsChangesTrieSignal)
+	}
+	return errors.New("digest item not set")
+}
+
+// ConsensusEngineData is the data of a consensus engine, eg: the BABE pre-digest. EngineID is the 4 byte id of the
+// engine, eg: BABE or aura.
+type ConsensusEngineData struct {
+	EngineID [4]byte
+	Bytes    []byte
+}
+
+func (c *ConsensusEngineData) Decode(decoder scale.Decoder) error {
+	err := decoder.Read(c.EngineID[:])
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(&c.Bytes)
+}
+
+func (c ConsensusEngineData) Encode(encoder scale.Encoder) error {
+	err := encoder.Write(c.EngineID[:])
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(c.Bytes)
+}
+
+// Slot returns the slot of a BABE or Aura pre-runtime digest
+func (c ConsensusEngineData) Slot() (uint64, error) {
+	switch string(c.EngineID[:]) {
+	case "aura":
+		if len(c.Bytes) < 8 {
+			return 0, errors.New("invalid aura pre-digest")
+		}
+		return binary.LittleEndian.Uint64(c.Bytes), nil
+	case "BABE":
+		// all BABE pre-digests start with the kind, the authority index and the slot
+		if len(c.Bytes) < 13 || c.Bytes[0] < 1 || c.Bytes[0] > 3 {
+			return 0, errors.New("invalid BABE pre-digest")
+		}
+		return binary.LittleEndian.Uint64(c.Bytes[5:]), nil
+	}
+	return 0, fmt.Errorf("no slot for consensus engine %s", c.EngineID[:])
+}
@@ -0,0 +1,72 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigest_Items(t *testing.T) {
+	var h Header
+	err := json.Unmarshal([]byte(testPolkadotHeader), &h)
+	assert.NoError(t, err)
+
+	items, err := h.Digest.Items()
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	assert.True(t, items[0].IsPreRuntime)
+	assert.Equal(t, "BABE", string(items[0].AsPreRuntime.EngineID[:]))
+	slot, err := items[0].AsPreRuntime.Slot()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x0fdd8e42), slot)
+
+	assert.True(t, items[1].IsSeal)
+	assert.Equal(t, "BABE", string(items[1].AsSeal.EngineID[:]))
+	assert.Len(t, items[1].AsSeal.Bytes, 64)
+
+	// the items encode to the logs again
+	for i, item := range items {
+		var buf bytes.Buffer
+		err = scale.NewEncoder(&buf).Encode(item)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(h.Digest.Logs[i]), buf.Bytes())
+	}
+}
+
+func TestDigest_Items_variants(t *testing.T) {
+	d := Digest{Logs: []hexutil.Bytes{
+		hexutil.MustDecode("0x000c010203"),
+		hexutil.MustDecode("0x02" + strings.Repeat("ab", 32)),
+		hexutil.MustDecode("0x0461757261040a"),
+		hexutil.MustDecode("0x066175726120d204000000000000"),
+		hexutil.MustDecode("0x0700010200000003000000"),
+		// unknown items are kept as they are
+		hexutil.MustDecode("0x01020304"),
+	}}
+
+	items, err := d.Items()
+	assert.NoError(t, err)
+	assert.Equal(t, DigestItem{IsOther: true, AsOther: []byte{1, 2, 3}}, items[0])
+	assert.Equal(t, DigestItem{IsChangesTrieRoot: true, AsChangesTrieRoot: bytes.Repeat([]byte{0xab}, 32)}, items[1])
+	assert.Equal(t, DigestItem{IsConsensus: true, AsConsensus: ConsensusEngineData{[4]byte{'a', 'u', 'r', 'a'},
+		[]byte{0x0a}}}, items[2])
+	slot, err := items[3].AsPreRuntime.Slot()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1234), slot)
+	signal := hexutil.MustDecode("0x00010200000003000000")
+	assert.Equal(t, DigestItem{IsChangesTrieSignal: true, AsChangesTrieSignal: signal}, items[4])
+	assert.Equal(t, DigestItem{IsOther: true, AsOther: []byte{1, 2, 3, 4}}, items[5])
+
+	_, err = items[2].AsConsensus.Slot()
+	assert.EqualError(t, err, "invalid aura pre-digest")
+	_, err = ConsensusEngineData{EngineID: [4]byte{'F', 'R', 'N', 'K'}}.Slot()
+	assert.EqualError(t, err, "no slot for consensus engine FRNK")
+}
@@ -0,0 +1,125 @@
+package substrate
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// ExtrinsicEra is the period of validity of an extrinsic, it is either immortal or valid for Period blocks from the
+// block it was created in
+type ExtrinsicEra struct {
+	IsImmortalEra bool
+	IsMortalEra   bool
+	AsMortalEra   MortalEra
+}
+
+// NewImmortalEra creates an era which never expires
+func NewImmortalEra() ExtrinsicEra {
+	return ExtrinsicEra{IsImmortalEra: true}
+}
+
+// NewMortalEra creates an era which is valid for period blocks starting at blockNumber. The period is rounded up to
+// a power of two between 4 and 65536 and the phase is quantized, as it is done by substrate.
+func NewMortalEra(period uint64, blockNumber uint64) ExtrinsicEra {
+	// next power of two, 0 ends up at the minimum and overflows at the maximum
+	p := uint64(1 << 16)
+	if period == 0 {
+		p = 1
+	} else if period <= 1<<63 {
+		p = uint64(1) << uint(bits.Len64(period-1))
+	}
+	if p < 4 {
+		p = 4
+	}
+	if p > 1<<16 {
+		p = 1 << 16
+	}
+
+	phase := blockNumber % p
+	quantizeFactor := maxUint64(p>>12, 1)
+	quantizedPhase := phase / quantizeFactor * quantizeFactor
+
+	return ExtrinsicEra{IsMortalEra: true, AsMortalEra: MortalEra{Period: p, Phase: quantizedPhase}}
+}
+
+// Birth returns the first block number of the era, given the current block number
+func (e ExtrinsicEra) Birth(current uint64) uint64 {
+	if !e.IsMortalEra {
+		return 0
+	}
+
+	m := e.AsMortalEra
+	return (maxUint64(current, m.Phase)-m.Phase)/m.Period*m.Period + m.Phase
+}
+
+// Death returns the first block number at which the era is no longer valid, given the current block number
+func (e ExtrinsicEra) Death(current uint64) uint64 {
+	if !e.IsMortalEra {
+		return math.MaxUint64
+	}
+
+	return e.Birth(current) + e.AsMortalEra.Period
+}
+
+func (e *ExtrinsicEra) Decode(decoder scale.Decoder) error {
+	first, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	if first == 0 {
+		*e = NewImmortalEra()
+		return nil
+	}
+
+	second, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	encoded := uint64(first) + uint64(second)<<8
+	period := uint64(2) << (encoded % (1 << 4))
+	quantizeFactor := maxUint64(period>>12, 1)
+	phase := (encoded >> 4) * quantizeFactor
+	if period < 4 || phase >= period {
+		return errors.New("invalid mortal era")
+	}
+
+	*e = ExtrinsicEra{IsMortalEra: true, AsMortalEra: MortalEra{Period: period, Phase: phase}}
+	return nil
+}
+
+func (e ExtrinsicEra) Encode(encoder scale.Encoder) error {
+	if !e.IsMortalEra {
+		return encoder.PushByte(0)
+	}
+
+	m := e.AsMortalEra
+	quantizeFactor := maxUint64(m.Period>>12, 1)
+	tz := uint64(bits.TrailingZeros64(m.Period))
+	if tz < 2 {
+		tz = 2
+	}
+	if tz > 16 {
+		tz = 16
+	}
+	encoded := uint16(tz-1) | uint16((m.Phase/quantizeFactor)<<4)
+
+	return encoder.Write([]byte{byte(encoded), byte(encoded >> 8)})
+}
+
+// MortalEra is the period and phase of a mortal era, see NewMortalEra
+type MortalEra struct {
+	Period uint64
+	Phase  uint64
+}
+
+func maxUint64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,61 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMortalEra(t *testing.T) {
+	assert.Equal(t, MortalEra{Period: 64, Phase: 42}, NewMortalEra(64, 42).AsMortalEra)
+	// rounded up to a power of two and at least 4
+	assert.Equal(t, MortalEra{Period: 64, Phase: 42}, NewMortalEra(50, 42).AsMortalEra)
+	assert.Equal(t, MortalEra{Period: 4, Phase: 2}, NewMortalEra(1, 6).AsMortalEra)
+	assert.Equal(t, MortalEra{Period: 4, Phase: 2}, NewMortalEra(0, 6).AsMortalEra)
+	// at most 65536, the phase is quantized
+	assert.Equal(t, MortalEra{Period: 65536, Phase: 20000}, NewMortalEra(100000, 20001).AsMortalEra)
+	assert.Equal(t, MortalEra{Period: 65536, Phase: 16}, NewMortalEra(math.MaxUint64, 20).AsMortalEra)
+}
+
+func TestExtrinsicEra_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		era     ExtrinsicEra
+		encoded []byte
+	}{
+		{NewImmortalEra(), []byte{0}},
+		{NewMortalEra(64, 42), []byte{0xa5, 0x02}},
+		{NewMortalEra(32768, 20000), []byte{78, 156}},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.era)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, buf.Bytes())
+
+		var dec ExtrinsicEra
+		err = scale.NewDecoder(&buf).Decode(&dec)
+		assert.NoError(t, err)
+		assert.Equal(t, test.era, dec)
+	}
+
+	var dec ExtrinsicEra
+	err := scale.NewDecoder(bytes.NewReader([]byte{0x01, 0xff})).Decode(&dec)
+	assert.EqualError(t, err, "invalid mortal era")
+}
+
+func TestExtrinsicEra_BirthDeath(t *testing.T) {
+	e := NewMortalEra(4, 6)
+	for _, current := range []uint64{6, 7, 8, 9} {
+		assert.Equal(t, uint64(6), e.Birth(current))
+		assert.Equal(t, uint64(10), e.Death(current))
+	}
+	assert.Equal(t, uint64(10), e.Birth(10))
+	assert.Equal(t, uint64(2), e.Birth(5))
+
+	assert.Equal(t, uint64(0), NewImmortalEra().Birth(100))
+	assert.Equal(t, uint64(math.MaxUint64), NewImmortalEra().Death(100))
+}
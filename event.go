@@ -0,0 +1,460 @@
+package substrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// EventRecordsRaw is the SCALE encoded value of the System.Events storage, a Vec<EventRecord> where each record
+// consists of the phase, the event and the topics of the event
+type EventRecordsRaw []byte
+
+// DecodeEventRecords decodes the event records into t, which must be a pointer to a struct like EventRecords. The
+// events are appended to the field named <Module>_<Event> of t, which must be a slice of structs that have a Phase
+// as first field, followed by the event arguments and Topics []Hash as last field. Embed EventRecords in your own
+// struct to decode events of custom modules.
+func (e EventRecordsRaw) DecodeEventRecords(m *MetadataVersioned, t interface{}) error {
+	_, err := e.decodeEventRecords(m, t)
+	return err
+}
+
+// DecodeEventRecordList decodes the event records like DecodeEventRecords and also returns them in the order they
+// were emitted, see EventRecord
+func (e EventRecordsRaw) DecodeEventRecordList(m *MetadataVersioned, t interface{}) ([]EventRecord, error) {
+	return e.decodeEventRecords(m, t)
+}
+
+func (e EventRecordsRaw) decodeEventRecords(m *MetadataVersioned, t interface{}) ([]EventRecord, error) {
+	target := reflect.ValueOf(t)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("target must be a pointer to a struct, got %T", t)
+	}
+	target = target.Elem()
+
+	r := bytes.NewReader(e)
+	decoder := scale.NewDecoder(r)
+	n, err := decoder.DecodeLength()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]EventRecord, 0, n)
+
+	for i := 0; i < n; i++ {
+		var phase Phase
+		err = decoder.Decode(&phase)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode the phase of event #%v: %v", i, err)
+		}
+
+		var id EventID
+		err = decoder.Decode(&id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode the id of event #%v: %v", i, err)
+		}
+
+		moduleName, eventName, err := m.FindEventNamesForEventID(id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the event #%v with id %v: %v", i, id, err)
+		}
+
+		name := strings.Title(moduleName) + "_" + eventName
+		field := target.FieldByName(name)
+		if !field.IsValid() {
+			return nil, fmt.Errorf("unable to find the field %v for event #%v with id %v", name, i, id)
+		}
+
+		if field.Kind() != reflect.Slice || field.Type().Elem().Kind() != reflect.Struct {
+			return nil, fmt.Errorf("field %v must be a slice of structs", name)
+		}
+
+		event := reflect.New(field.Type().Elem()).Elem()
+		if event.NumField() == 0 || event.Field(0).Type() != reflect.TypeOf(phase) {
+			return nil, fmt.Errorf("the first field of %v must be a Phase", name)
+		}
+		// the topics end each record, without them the following records would be misaligned
+		last := event.NumField() - 1
+		if event.Field(last).Type() != reflect.TypeOf([]Hash{}) {
+			return nil, fmt.Errorf("the last field of %v must be Topics []Hash", name)
+		}
+
+		event.Field(0).Set(reflect.ValueOf(phase))
+		for j := 1; j < event.NumField(); j++ {
+			err = decoder.DecodeIntoReflectValue(event.Field(j))
+			if err != nil {
+				return nil, fmt.Errorf("unable to decode field %v of event %v: %v", j, name, err)
+			}
+		}
+
+		field.Set(reflect.Append(field, event))
+		records = append(records, EventRecord{Phase: phase, ID: id, Module: moduleName, Name: eventName,
+			Event: event.Interface(), Topics: event.Field(last).Interface().([]Hash)})
+	}
+
+	if r.Len() > 0 {
+		return nil, fmt.Errorf("%v bytes left after decoding %v events", r.Len(), n)
+	}
+	return records, nil
+}
+
+// EventRecord is a decoded event with the phase it was emitted in
+type EventRecord struct {
+	Phase  Phase
+	ID     EventID
+	Module string
+	Name   string
+	// Event is the decoded event struct, eg: EventBalancesTransfer
+	Event interface{}
+	// Topics are the topics of the event, the last field of Event
+	Topics []Hash
+}
+
+// ExtrinsicEventRecords returns the events that were emitted while applying the extrinsic with the given index in
+// the block, in the order they were emitted
+func ExtrinsicEventRecords(records []EventRecord, index uint32) []EventRecord {
+	var res []EventRecord
+	for _, r := range records {
+		if r.Phase.IsApplyExtrinsic && r.Phase.AsApplyExtrinsic == index {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// EventID is the index of the module and the index of the event within the module
+type EventID [2]byte
+
+func (e *EventID) Decode(decoder scale.Decoder) error {
+	return decoder.Read(e[:])
+}
+
+func (e EventID) Encode(encoder scale.Encoder) error {
+	return encoder.Write(e[:])
+}
+
+// Phase is the phase of the block execution an event was emitted in
+type Phase struct {
+	IsApplyExtrinsic bool
+	// AsApplyExtrinsic is the index of the extrinsic in the block
+	AsApplyExtrinsic uint32
+	IsFinalization   bool
+	IsInitialization bool
+}
+
+func (p *Phase) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		p.IsApplyExtrinsic = true
+		return decoder.Decode(&p.AsApplyExtrinsic)
+	case 1:
+		p.IsFinalization = true
+	case 2:
+		p.IsInitialization = true
+	default:
+		return fmt.Errorf("unknown phase %d", b)
+	}
+	return nil
+}
+
+func (p Phase) Encode(encoder scale.Encoder) error {
+	switch {
+	case p.IsApplyExtrinsic:
+		err := encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(p.AsApplyExtrinsic)
+	case p.IsFinalization:
+		return encoder.PushByte(1)
+	case p.IsInitialization:
+		return encoder.PushByte(2)
+	}
+	return errors.New("phase not set")
+}
+
+// DispatchInfo is the weight information of an extrinsic
+type DispatchInfo struct {
+	Weight  uint64
+	Class   DispatchClass
+	PaysFee bool
+}
+
+func (d *DispatchInfo) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&d.Weight)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&d.Class)
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(&d.PaysFee)
+}
+
+func (d DispatchInfo) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(d.Weight)
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(d.Class)
+	if err != nil {
+		return err
+	}
+
+	return encoder.Encode(d.PaysFee)
+}
+
+// DispatchClass is the class of an extrinsic
+type DispatchClass struct {
+	IsNormal      bool
+	IsOperational bool
+	IsMandatory   bool
+}
+
+func (d *DispatchClass) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		d.IsNormal = true
+	case 1:
+		d.IsOperational = true
+	case 2:
+		d.IsMandatory = true
+	default:
+		return fmt.Errorf("unknown dispatch class %d", b)
+	}
+	return nil
+}
+
+func (d DispatchClass) Encode(encoder scale.Encoder) error {
+	switch {
+	case d.IsNormal:
+		return encoder.PushByte(0)
+	case d.IsOperational:
+		return encoder.PushByte(1)
+	case d.IsMandatory:
+		return encoder.PushByte(2)
+	}
+	return errors.New("dispatch class not set")
+}
+
+// UnmarshalJSON decodes the class as it is returned by the RPC, eg: "normal"
+func (d *DispatchClass) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	switch s {
+	case "normal":
+		*d = DispatchClass{IsNormal: true}
+	case "operational":
+		*d = DispatchClass{IsOperational: true}
+	case "mandatory":
+		*d = DispatchClass{IsMandatory: true}
+	default:
+		return fmt.Errorf("unknown dispatch class %s", s)
+	}
+	return nil
+}
+
+func (d DispatchClass) MarshalJSON() ([]byte, error) {
+	switch {
+	case d.IsNormal:
+		return json.Marshal("normal")
+	case d.IsOperational:
+		return json.Marshal("operational")
+	case d.IsMandatory:
+		return json.Marshal("mandatory")
+	}
+	return nil, errors.New("dispatch class not set")
+}
+
+// DispatchResult is the result of a dispatch, a Result<(), DispatchError>. IsOk is false if the dispatch failed with
+// AsError.
+type DispatchResult struct {
+	IsOk    bool
+	AsError DispatchError
+}
+
+func (d *DispatchResult) Decode(decoder scale.Decoder) error {
+	return decoder.DecodeResult(&d.IsOk, nil, &d.AsError)
+}
+
+func (d DispatchResult) Encode(encoder scale.Encoder) error {
+	return encoder.EncodeResult(d.IsOk, nil, d.AsError)
+}
+
+// DispatchError is the reason an extrinsic failed, module errors reference the module index and the error index
+// within the module
+type DispatchError struct {
+	IsOther        bool
+	IsCannotLookup bool
+	IsBadOrigin    bool
+	IsModule       bool
+	AsModule       ModuleError
+}
+
+// ModuleError is the index of the module and the index of the error within the module
+type ModuleError struct {
+	Index uint8
+	Error uint8
+}
+
+func (d *DispatchError) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		d.IsOther = true
+	case 1:
+		d.IsCannotLookup = true
+	case 2:
+		d.IsBadOrigin = true
+	case 3:
+		d.IsModule = true
+		err = decoder.Decode(&d.AsModule.Index)
+		if err != nil {
+			return err
+		}
+		return decoder.Decode(&d.AsModule.Error)
+	default:
+		return fmt.Errorf("unknown dispatch error %d", b)
+	}
+	return nil
+}
+
+// Resolve looks up the module and the name of a module error in the metadata, eg: "Balances" and
+// "InsufficientBalance". The other errors resolve to their variant, eg: "BadOrigin", without a module. The name is
+// empty if the metadata doesn't define the error, v4 metadata doesn't define any errors.
+func (d DispatchError) Resolve(meta *MetadataVersioned) (module string, name string, docs []string) {
+	switch {
+	case d.IsOther:
+		return "", "Other", nil
+	case d.IsCannotLookup:
+		return "", "CannotLookup", nil
+	case d.IsBadOrigin:
+		return "", "BadOrigin", nil
+	}
+
+	// errors are indexed by the position of the module in the runtime, not only counting modules with errors
+	if meta.Version != 11 || int(d.AsModule.Index) >= len(meta.MetadataV11.Modules) {
+		return "", "", nil
+	}
+
+	mod := meta.MetadataV11.Modules[d.AsModule.Index]
+	if int(d.AsModule.Error) >= len(mod.Errors) {
+		return mod.Name, "", nil
+	}
+
+	e := mod.Errors[d.AsModule.Error]
+	return mod.Name, e.Name, e.Documentation
+}
+
+func (d DispatchError) Encode(encoder scale.Encoder) error {
+	switch {
+	case d.IsOther:
+		return encoder.PushByte(0)
+	case d.IsCannotLookup:
+		return encoder.PushByte(1)
+	case d.IsBadOrigin:
+		return encoder.PushByte(2)
+	case d.IsModule:
+		return encoder.Write([]byte{3, d.AsModule.Index, d.AsModule.Error})
+	}
+	return errors.New("dispatch error not set")
+}
+
+// EventRecords contains the events of the System and Balances modules, see EventRecordsRaw.DecodeEventRecords
+type EventRecords struct {
+	System_ExtrinsicSuccess []EventSystemExtrinsicSuccess
+	System_ExtrinsicFailed  []EventSystemExtrinsicFailed
+	System_CodeUpdated      []EventSystemCodeUpdated
+	System_NewAccount       []EventSystemNewAccount
+	System_KilledAccount    []EventSystemKilledAccount
+	Balances_Endowed        []EventBalancesEndowed
+	Balances_Transfer       []EventBalancesTransfer
+	Balances_Deposit        []EventBalancesDeposit
+}
+
+// EventSystemExtrinsicSuccess is emitted when an extrinsic completed successfully
+type EventSystemExtrinsicSuccess struct {
+	Phase        Phase
+	DispatchInfo DispatchInfo
+	Topics       []Hash
+}
+
+// EventSystemExtrinsicFailed is emitted when an extrinsic failed
+type EventSystemExtrinsicFailed struct {
+	Phase         Phase
+	DispatchError DispatchError
+	DispatchInfo  DispatchInfo
+	Topics        []Hash
+}
+
+// EventSystemCodeUpdated is emitted when the runtime code was updated
+type EventSystemCodeUpdated struct {
+	Phase  Phase
+	Topics []Hash
+}
+
+// EventSystemNewAccount is emitted when a new account was created
+type EventSystemNewAccount struct {
+	Phase  Phase
+	Who    AccountID
+	Topics []Hash
+}
+
+// EventSystemKilledAccount is emitted when an account was reaped
+type EventSystemKilledAccount struct {
+	Phase  Phase
+	Who    AccountID
+	Topics []Hash
+}
+
+// EventBalancesEndowed is emitted when an account was created with some free balance
+type EventBalancesEndowed struct {
+	Phase   Phase
+	Who     AccountID
+	Balance U128
+	Topics  []Hash
+}
+
+// EventBalancesTransfer is emitted when a transfer succeeded
+type EventBalancesTransfer struct {
+	Phase  Phase
+	From   AccountID
+	To     AccountID
+	Value  U128
+	Topics []Hash
+}
+
+// EventBalancesDeposit is emitted when some amount was deposited, eg: for transaction fees
+type EventBalancesDeposit struct {
+	Phase   Phase
+	Who     AccountID
+	Balance U128
+	Topics  []Hash
+}
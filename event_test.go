@@ -0,0 +1,201 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// testEventRecords are the events of the V11 test metadata: System.ExtrinsicSuccess of extrinsic 0,
+// Balances.Transfer and System.ExtrinsicFailed with one topic of extrinsic 1 and System.ExtrinsicSuccess in the
+// finalization phase
+const testEventRecords = "0x10" +
+	"00000000000000102700000000000000" + "0100" +
+	"00010000000100d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d" +
+	"8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48e803000000000000000000000000000000" + "00" +
+	"01000000000103020310270000000000000001" + "041111111111111111111111111111111111111111111111111111111111111111" +
+	"0100001027000000000000000100"
+
+func TestEventRecordsRaw_DecodeEventRecords(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	var events EventRecords
+	err := EventRecordsRaw(hexutil.MustDecode(testEventRecords)).DecodeEventRecords(m, &events)
+	assert.NoError(t, err)
+
+	info := DispatchInfo{Weight: 10000, Class: DispatchClass{IsNormal: true}, PaysFee: true}
+	assert.Equal(t, []EventSystemExtrinsicSuccess{
+		{Phase: Phase{IsApplyExtrinsic: true, AsApplyExtrinsic: 0}, DispatchInfo: info},
+		{Phase: Phase{IsFinalization: true}, DispatchInfo: info},
+	}, events.System_ExtrinsicSuccess)
+
+	assert.Len(t, events.Balances_Transfer, 1)
+	transfer := events.Balances_Transfer[0]
+	assert.Equal(t, Phase{IsApplyExtrinsic: true, AsApplyExtrinsic: 1}, transfer.Phase)
+	assert.Equal(t, "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY", transfer.From.ToSS58(42))
+	assert.Equal(t, "5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty", transfer.To.ToSS58(42))
+	assert.Equal(t, 0, big.NewInt(1000).Cmp(transfer.Value.Int))
+
+	assert.Len(t, events.System_ExtrinsicFailed, 1)
+	failed := events.System_ExtrinsicFailed[0]
+	assert.Equal(t, DispatchError{IsModule: true, AsModule: ModuleError{Index: 2, Error: 3}}, failed.DispatchError)
+	assert.Equal(t, info, failed.DispatchInfo)
+	assert.Equal(t, []Hash{hexutil.MustDecode("0x1111111111111111111111111111111111111111111111111111111111111111")},
+		failed.Topics)
+}
+
+func TestEventRecordsRaw_DecodeEventRecords_errors(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	raw := EventRecordsRaw(hexutil.MustDecode(testEventRecords))
+
+	var missingField struct {
+		System_ExtrinsicSuccess []EventSystemExtrinsicSuccess
+	}
+	err := raw.DecodeEventRecords(m, &missingField)
+	assert.EqualError(t, err, "unable to find the field Balances_Transfer for event #1 with id [1 0]")
+
+	err = raw.DecodeEventRecords(m, EventRecords{})
+	assert.Error(t, err)
+
+	err = EventRecordsRaw{0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x00}.DecodeEventRecords(m, &EventRecords{})
+	assert.EqualError(t, err, "unable to find the event #0 with id [5 0]: module index 5 out of range")
+}
+
+func TestEventRecordsRaw_DecodeEventRecords_topics(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	topic1 := strings.Repeat("11", 32)
+	topic2 := strings.Repeat("22", 32)
+	// System.ExtrinsicSuccess of extrinsic 0 with two topics and in the finalization phase without topics
+	raw := "0x08" +
+		"0000000000" + "0000" + "102700000000000000" + "01" + "08" + topic1 + topic2 +
+		"01" + "0000" + "102700000000000000" + "01" + "00"
+
+	var events EventRecords
+	records, err := EventRecordsRaw(hexutil.MustDecode(raw)).DecodeEventRecordList(m, &events)
+	assert.NoError(t, err)
+	assert.Len(t, events.System_ExtrinsicSuccess, 2)
+	assert.Equal(t, []Hash{hexutil.MustDecode("0x" + topic1), hexutil.MustDecode("0x" + topic2)},
+		events.System_ExtrinsicSuccess[0].Topics)
+	assert.Equal(t, events.System_ExtrinsicSuccess[0].Topics, records[0].Topics)
+	assert.Equal(t, Phase{IsFinalization: true}, events.System_ExtrinsicSuccess[1].Phase)
+	assert.Empty(t, events.System_ExtrinsicSuccess[1].Topics)
+	assert.Empty(t, records[1].Topics)
+
+	// a topic too many misaligns the records
+	misaligned := strings.Replace(raw, "01"+"08"+topic1, "01"+"0c"+topic1, 1)
+	err = EventRecordsRaw(hexutil.MustDecode(misaligned)).DecodeEventRecords(m, &EventRecords{})
+	assert.Error(t, err)
+
+	err = EventRecordsRaw(hexutil.MustDecode(raw+"00")).DecodeEventRecords(m, &EventRecords{})
+	assert.EqualError(t, err, "1 bytes left after decoding 2 events")
+
+	var withoutTopics struct {
+		System_ExtrinsicSuccess []struct {
+			Phase        Phase
+			DispatchInfo DispatchInfo
+		}
+	}
+	err = EventRecordsRaw(hexutil.MustDecode(raw)).DecodeEventRecords(m, &withoutTopics)
+	assert.EqualError(t, err, "the last field of System_ExtrinsicSuccess must be Topics []Hash")
+}
+
+func TestPhase_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		phase   Phase
+		encoded []byte
+	}{
+		{Phase{IsApplyExtrinsic: true, AsApplyExtrinsic: 258}, []byte{0, 2, 1, 0, 0}},
+		{Phase{IsFinalization: true}, []byte{1}},
+		{Phase{IsInitialization: true}, []byte{2}},
+	} {
+		b, err := scale.EncodeToBytes(test.phase)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, b)
+
+		var decoded Phase
+		err = scale.DecodeFromBytes(b, &decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, test.phase, decoded)
+	}
+
+	var decoded Phase
+	err := scale.DecodeFromBytes([]byte{3}, &decoded)
+	assert.EqualError(t, err, "unknown phase 3")
+	_, err = scale.EncodeToBytes(Phase{})
+	assert.EqualError(t, err, "phase not set")
+}
+
+func TestDispatchResult_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		result  DispatchResult
+		encoded []byte
+	}{
+		{DispatchResult{IsOk: true}, []byte{0}},
+		{DispatchResult{AsError: DispatchError{IsBadOrigin: true}}, []byte{1, 2}},
+		{DispatchResult{AsError: DispatchError{IsModule: true, AsModule: ModuleError{Index: 4, Error: 1}}},
+			[]byte{1, 3, 4, 1}},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.result)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, buf.Bytes())
+
+		var decoded DispatchResult
+		err = scale.NewDecoder(&buf).Decode(&decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, test.result, decoded)
+	}
+}
+
+func TestDispatchError_Resolve(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+
+	mod, name, docs := DispatchError{IsModule: true, AsModule: ModuleError{Index: 2, Error: 1}}.Resolve(m)
+	assert.Equal(t, "Balances", mod)
+	assert.Equal(t, "InsufficientBalance", name)
+	assert.Equal(t, []string{" Balance too low to send value"}, docs)
+
+	mod, name, _ = DispatchError{IsModule: true, AsModule: ModuleError{Index: 0, Error: 0}}.Resolve(m)
+	assert.Equal(t, "System", mod)
+	assert.Equal(t, "InvalidSpecName", name)
+
+	// Timestamp doesn't define errors
+	mod, name, _ = DispatchError{IsModule: true, AsModule: ModuleError{Index: 1, Error: 0}}.Resolve(m)
+	assert.Equal(t, "Timestamp", mod)
+	assert.Equal(t, "", name)
+
+	mod, name, _ = DispatchError{IsModule: true, AsModule: ModuleError{Index: 9, Error: 0}}.Resolve(m)
+	assert.Equal(t, "", mod)
+	assert.Equal(t, "", name)
+
+	mod, name, _ = DispatchError{IsBadOrigin: true}.Resolve(m)
+	assert.Equal(t, "", mod)
+	assert.Equal(t, "BadOrigin", name)
+}
+
+func TestEventRecordsRaw_DecodeEventRecordList(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	var events EventRecords
+	records, err := EventRecordsRaw(hexutil.MustDecode(testEventRecords)).DecodeEventRecordList(m, &events)
+	assert.NoError(t, err)
+	assert.Len(t, records, 4)
+	assert.Len(t, events.System_ExtrinsicSuccess, 2)
+
+	assert.Equal(t, "System", records[0].Module)
+	assert.Equal(t, "ExtrinsicSuccess", records[0].Name)
+	assert.Equal(t, events.System_ExtrinsicSuccess[0], records[0].Event)
+	assert.Equal(t, Phase{IsFinalization: true}, records[3].Phase)
+
+	ext := ExtrinsicEventRecords(records, 1)
+	assert.Len(t, ext, 2)
+	assert.Equal(t, EventID{1, 0}, ext[0].ID)
+	assert.Equal(t, events.Balances_Transfer[0], ext[0].Event)
+	assert.Equal(t, "ExtrinsicFailed", ext[1].Name)
+	assert.Empty(t, ExtrinsicEventRecords(records, 2))
+}
@@ -0,0 +1,355 @@
+package substrate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+)
+
+// ExtrinsicVersion4 is the version byte of a signed extrinsic in the version 4 format
+const ExtrinsicVersion4 = 0x84
+
+// ExtrinsicVersion3 is the version byte of a signed extrinsic in the version 3 format
+const ExtrinsicVersion3 = 0x83
+
+// DefaultSignedExtensions are the signed extensions the payload of ExtrinsicPayloadV4 is laid out for unless other
+// SignedExtensions are set, those of the substrate node template.
+var DefaultSignedExtensions = NodeTemplateSignedExtensions
+
+// NodeTemplateSignedExtensions are the signed extensions of the substrate node template in their order
+var NodeTemplateSignedExtensions = []string{"CheckSpecVersion", "CheckTxVersion", "CheckGenesis", "CheckMortality",
+	"CheckNonce", "CheckWeight", "ChargeTransactionPayment"}
+
+// PolkadotSignedExtensions are the signed extensions of the polkadot runtime in their order
+var PolkadotSignedExtensions = []string{"CheckSpecVersion", "CheckTxVersion", "CheckGenesis", "CheckMortality",
+	"CheckNonce", "CheckWeight", "ChargeTransactionPayment", "PrevalidateAttests"}
+
+// KusamaSignedExtensions are the signed extensions of the kusama runtime in their order, those of polkadot without
+// PrevalidateAttests
+var KusamaSignedExtensions = []string{"CheckSpecVersion", "CheckTxVersion", "CheckGenesis", "CheckMortality",
+	"CheckNonce", "CheckWeight", "ChargeTransactionPayment"}
+
+// SignedExtensionData is the encoded data of a chain specific signed extension. Extra is part of the extrinsic and of
+// the signed payload, AdditionalSigned is only part of the signed payload. Extensions without data have neither.
+type SignedExtensionData struct {
+	Extra            Encoded
+	AdditionalSigned Encoded
+}
+
+// ExtrinsicPayloadV4 is the payload that is signed for extrinsics of version 4. Besides the call, era, nonce and tip
+// it includes the data the runtime adds through the CheckSpecVersion, CheckTxVersion, CheckGenesis and CheckEra
+// signed extensions, which is not part of the extrinsic itself.
+type ExtrinsicPayloadV4 struct {
+	Method             Method
+	Era                ExtrinsicEra
+	Nonce              uint64
+	Tip                UCompact
+	SpecVersion        uint32
+	TransactionVersion uint32
+	GenesisHash        [32]byte
+	// BlockHash is the block the era starts at, the genesis hash for immortal extrinsics
+	BlockHash [32]byte
+
+	// SignedExtensions are the names of the signed extensions of the runtime in the order of the metadata, see
+	// MetadataV11.Extrinsic. The payload is laid out for DefaultSignedExtensions if it is nil. The data of extensions
+	// this package doesn't know must be set in CustomSignedExtensions, otherwise encoding fails.
+	SignedExtensions       []string
+	CustomSignedExtensions map[string]SignedExtensionData
+}
+
+// signedExtensions returns the encoded extra and additional signed data of the signed extensions in their order
+func (e ExtrinsicPayloadV4) signedExtensions() (extra, additional []byte, err error) {
+	names := e.SignedExtensions
+	if names == nil {
+		names = DefaultSignedExtensions
+	}
+
+	var ex, add interface{}
+	for _, name := range names {
+		ex, add = nil, nil
+		switch name {
+		case "CheckSpecVersion":
+			add = e.SpecVersion
+		case "CheckTxVersion":
+			add = e.TransactionVersion
+		case "CheckGenesis":
+			add = Encoded(e.GenesisHash[:])
+		case "CheckMortality", "CheckEra":
+			ex, add = e.Era, Encoded(e.BlockHash[:])
+		case "CheckNonce":
+			ex = NewUCompact(new(big.Int).SetUint64(e.Nonce))
+		case "CheckWeight", "PrevalidateAttests":
+		case "ChargeTransactionPayment":
+			ex = e.Tip
+		default:
+			data, ok := e.CustomSignedExtensions[name]
+			if !ok {
+				return nil, nil, fmt.Errorf("unknown signed extension %s", name)
+			}
+			ex, add = data.Extra, data.AdditionalSigned
+		}
+
+		if ex != nil {
+			b, err := scale.EncodeToBytes(ex)
+			if err != nil {
+				return nil, nil, err
+			}
+			extra = append(extra, b...)
+		}
+		if add != nil {
+			b, err := scale.EncodeToBytes(add)
+			if err != nil {
+				return nil, nil, err
+			}
+			additional = append(additional, b...)
+		}
+	}
+	return extra, additional, nil
+}
+
+func (e ExtrinsicPayloadV4) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(e.Method)
+	if err != nil {
+		return err
+	}
+	if e.SignedExtensions != nil {
+		extra, additional, err := e.signedExtensions()
+		if err != nil {
+			return err
+		}
+		err = encoder.Write(extra)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(additional)
+	}
+
+	err = encoder.Encode(e.Era)
+	if err != nil {
+		return err
+	}
+	err = encoder.EncodeUintCompact(e.Nonce)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.Tip)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.SpecVersion)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.TransactionVersion)
+	if err != nil {
+		return err
+	}
+	err = encoder.Write(e.GenesisHash[:])
+	if err != nil {
+		return err
+	}
+	return encoder.Write(e.BlockHash[:])
+}
+
+// ExtrinsicSignatureV4 is the signature part of an extrinsic of version 4, the signature is a MultiSignature that
+// includes the crypto scheme of the signer
+type ExtrinsicSignatureV4 struct {
+	Signer    Address
+	Signature signature.MultiSignature
+	Era       ExtrinsicEra
+	Nonce     uint64
+	Tip       UCompact
+	// Extra is the encoded extra data of custom signed extensions, it is encoded instead of the era, nonce and tip if
+	// it is set, see ExtrinsicPayloadV4.SignedExtensions. It is not decoded.
+	Extra Encoded
+
+	// UseMultiAddress encodes and decodes the signer as MultiAddress, see ExtrinsicSignature.UseMultiAddress
+	UseMultiAddress bool
+}
+
+func (e *ExtrinsicSignatureV4) Decode(decoder scale.Decoder) error {
+	version, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+	if version != ExtrinsicVersion4 {
+		return errors.New("only signed extrinsics of version 4 are supported")
+	}
+
+	if e.UseMultiAddress {
+		var signer MultiAddress
+		err = decoder.Decode(&signer)
+		if err != nil {
+			return err
+		}
+		if !signer.IsID {
+			return errors.New("only account ids are supported as multi address signer")
+		}
+		e.Signer = Address{PubKey: signer.AsID}
+	} else {
+		err = decoder.Decode(&e.Signer)
+		if err != nil {
+			return err
+		}
+		if e.Signer.IsAccountIndex {
+			return errors.New("only account ids are supported as signer")
+		}
+	}
+
+	err = decoder.Decode(&e.Signature)
+	if err != nil {
+		return err
+	}
+	err = decoder.Decode(&e.Era)
+	if err != nil {
+		return err
+	}
+	e.Nonce, err = decoder.DecodeUintCompact()
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(&e.Tip)
+}
+
+func (e ExtrinsicSignatureV4) Encode(encoder scale.Encoder) error {
+	err := encoder.PushByte(ExtrinsicVersion4)
+	if err != nil {
+		return err
+	}
+
+	if e.UseMultiAddress {
+		err = encoder.Encode(NewMultiAddressFromAccountID(e.Signer.PubKey[:]))
+	} else {
+		err = encoder.Encode(e.Signer)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = encoder.Encode(e.Signature)
+	if err != nil {
+		return err
+	}
+	if e.Extra != nil {
+		return encoder.Encode(e.Extra)
+	}
+	err = encoder.Encode(e.Era)
+	if err != nil {
+		return err
+	}
+	err = encoder.EncodeUintCompact(e.Nonce)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(e.Tip)
+}
+
+// ExtrinsicPayloadV3 is the payload that is signed for extrinsics of version 3. It is laid out for the CheckVersion,
+// CheckGenesis, CheckEra, CheckNonce, CheckWeight and TakeFees signed extensions, there is no transaction version.
+type ExtrinsicPayloadV3 struct {
+	Method      Method
+	Era         ExtrinsicEra
+	Nonce       uint64
+	Tip         UCompact
+	SpecVersion uint32
+	GenesisHash [32]byte
+	// BlockHash is the block the era starts at, the genesis hash for immortal extrinsics
+	BlockHash [32]byte
+}
+
+func (e ExtrinsicPayloadV3) Encode(encoder scale.Encoder) error {
+	err := encoder.Encode(e.Method)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.Era)
+	if err != nil {
+		return err
+	}
+	err = encoder.EncodeUintCompact(e.Nonce)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.Tip)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.SpecVersion)
+	if err != nil {
+		return err
+	}
+	err = encoder.Write(e.GenesisHash[:])
+	if err != nil {
+		return err
+	}
+	return encoder.Write(e.BlockHash[:])
+}
+
+// ExtrinsicSignatureV3 is the signature part of an extrinsic of version 3. Unlike version 4 the signature is 64 bytes
+// without the crypto scheme, so only ed25519 and sr25519 signers are supported.
+type ExtrinsicSignatureV3 struct {
+	Signer    Address
+	Signature Signature
+	Era       ExtrinsicEra
+	Nonce     uint64
+	Tip       UCompact
+}
+
+func (e *ExtrinsicSignatureV3) Decode(decoder scale.Decoder) error {
+	version, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+	if version != ExtrinsicVersion3 {
+		return errors.New("only signed extrinsics of version 3 are supported")
+	}
+
+	err = decoder.Decode(&e.Signer)
+	if err != nil {
+		return err
+	}
+	if e.Signer.IsAccountIndex {
+		return errors.New("only account ids are supported as signer")
+	}
+
+	err = decoder.Decode(&e.Signature)
+	if err != nil {
+		return err
+	}
+	err = decoder.Decode(&e.Era)
+	if err != nil {
+		return err
+	}
+	e.Nonce, err = decoder.DecodeUintCompact()
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(&e.Tip)
+}
+
+func (e ExtrinsicSignatureV3) Encode(encoder scale.Encoder) error {
+	err := encoder.PushByte(ExtrinsicVersion3)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.Signer)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.Signature)
+	if err != nil {
+		return err
+	}
+	err = encoder.Encode(e.Era)
+	if err != nil {
+		return err
+	}
+	err = encoder.EncodeUintCompact(e.Nonce)
+	if err != nil {
+		return err
+	}
+	return encoder.Encode(e.Tip)
+}
@@ -0,0 +1,286 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtrinsicPayloadV4_Encode(t *testing.T) {
+	p := ExtrinsicPayloadV4{
+		Method:             Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))},
+		Era:                NewImmortalEra(),
+		Nonce:              7,
+		Tip:                NewUCompact(big.NewInt(1)),
+		SpecVersion:        2026,
+		TransactionVersion: 4,
+	}
+	p.GenesisHash[0] = 0xaa
+	p.BlockHash[0] = 0xbb
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(p)
+	assert.NoError(t, err)
+	// call, era, compact nonce and tip, spec and transaction version, genesis and block hash
+	assert.Equal(t, "0x0100a10f"+"00"+"1c"+"04"+"ea070000"+"04000000"+
+		"aa"+string(bytes.Repeat([]byte("00"), 31))+"bb"+string(bytes.Repeat([]byte("00"), 31)),
+		hexutil.Encode(buf.Bytes()))
+}
+
+func TestExtrinsicPayloadV4_Encode_signedExtensions(t *testing.T) {
+	p := ExtrinsicPayloadV4{
+		Method:             Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))},
+		Era:                NewImmortalEra(),
+		Nonce:              7,
+		Tip:                NewUCompact(big.NewInt(1)),
+		SpecVersion:        2026,
+		TransactionVersion: 4,
+	}
+	p.GenesisHash[0] = 0xaa
+	p.BlockHash[0] = 0xbb
+	def, err := scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+
+	// the default extensions lay out the payload as without extensions
+	p.SignedExtensions = DefaultSignedExtensions
+	b, err := scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, def, b)
+
+	// a chain specific extension after the defaults
+	p.SignedExtensions = append(DefaultSignedExtensions[:len(DefaultSignedExtensions):len(DefaultSignedExtensions)],
+		"CheckCustom")
+	_, err = scale.EncodeToBytes(p)
+	assert.EqualError(t, err, "unknown signed extension CheckCustom")
+
+	p.CustomSignedExtensions = map[string]SignedExtensionData{
+		"CheckCustom": {Extra: Encoded{0x11}, AdditionalSigned: Encoded{0x22, 0x33}},
+	}
+	b, err = scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	// call, era, nonce, tip and the custom extra, then the additional signed data
+	assert.Equal(t, "0x0100a10f"+"00"+"1c"+"04"+"11"+"ea070000"+"04000000"+
+		"aa"+string(bytes.Repeat([]byte("00"), 31))+"bb"+string(bytes.Repeat([]byte("00"), 31))+"2233",
+		hexutil.Encode(b))
+
+	// extensions without data, like CheckWeight, add nothing
+	p.SignedExtensions = []string{"CheckNonce", "CheckWeight"}
+	b, err = scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0100a10f1c", hexutil.Encode(b))
+
+	// the polkadot extensions only add PrevalidateAttests, which has no data
+	p.SignedExtensions = PolkadotSignedExtensions
+	b, err = scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, def, b)
+
+	p.SignedExtensions = KusamaSignedExtensions
+	b, err = scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, def, b)
+
+	// the additional signed data follows the order of the extensions
+	p.SignedExtensions = []string{"CheckGenesis", "CheckSpecVersion", "CheckNonce"}
+	b, err = scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0100a10f"+"1c"+"aa"+string(bytes.Repeat([]byte("00"), 31))+"ea070000", hexutil.Encode(b))
+}
+
+func TestExtrinsic_Encode_signedExtensions(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	genesis := bytes.Repeat([]byte{0x02}, 32)
+	method := Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))}
+	e := NewExtrinsicWithKey(pair, 7, genesis, method)
+	e.Version4 = true
+	e.SignedExtensions = []string{"CheckGenesis", "CheckCustom", "CheckNonce"}
+	e.CustomSignedExtensions = map[string]SignedExtensionData{"CheckCustom": {Extra: Encoded{0x11}}}
+
+	b, err := scale.EncodeToBytes(e)
+	assert.NoError(t, err)
+	// two byte length, version, signer, signature, the extra of CheckCustom and CheckNonce and the call
+	assert.Equal(t, 2+1+33+65+2+4, len(b))
+	assert.Equal(t, []byte{0x11, 0x1c, 0x01, 0x00, 0xa1, 0x0f}, b[len(b)-6:])
+
+	payload := ExtrinsicPayloadV4{Method: method, Nonce: 7, SignedExtensions: e.SignedExtensions,
+		CustomSignedExtensions: e.CustomSignedExtensions}
+	copy(payload.GenesisHash[:], genesis)
+	pb, err := scale.EncodeToBytes(payload)
+	assert.NoError(t, err)
+	var sig signature.MultiSignature
+	err = scale.DecodeFromBytes(b[36:101], &sig)
+	assert.NoError(t, err)
+	assert.True(t, pair.Verify(pb, sig))
+}
+
+func TestExtrinsic_EncodeDecode_u128Tip(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	maxU128 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	genesis := bytes.Repeat([]byte{0x02}, 32)
+	method := Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))}
+	e := NewExtrinsicWithKey(pair, 7, genesis, method)
+	e.Version4 = true
+	e.Tip = NewUCompact(maxU128)
+
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(e)
+	assert.NoError(t, err)
+
+	decoded := Extrinsic{Version4: true, Method: Method{Args: &UCompact{}}}
+	err = scale.NewDecoder(&buf).Decode(&decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, maxU128, decoded.SignatureV4.Tip.Int)
+
+	// the signature covers the tip
+	payload := ExtrinsicPayloadV4{Method: method, Era: NewImmortalEra(), Nonce: 7, Tip: NewUCompact(maxU128)}
+	copy(payload.GenesisHash[:], genesis)
+	copy(payload.BlockHash[:], genesis)
+	var pbuf bytes.Buffer
+	err = scale.NewEncoder(&pbuf).Encode(payload)
+	assert.NoError(t, err)
+	assert.True(t, pair.Verify(pbuf.Bytes(), decoded.SignatureV4.Signature))
+	// u128::MAX is encoded in the big integer mode with 16 bytes
+	assert.Equal(t, append([]byte{0x33}, bytes.Repeat([]byte{0xff}, 16)...), pbuf.Bytes()[6:23])
+}
+
+func TestExtrinsic_EncodeDecode_version4(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	genesis := bytes.Repeat([]byte{0x02}, 32)
+	method := Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))}
+	for _, useMultiAddress := range []bool{false, true} {
+		e := NewExtrinsicWithKey(pair, 7, genesis, method)
+		e.Version4 = true
+		e.SpecVersion = 2026
+		e.TransactionVersion = 4
+		e.UseMultiAddress = useMultiAddress
+
+		var buf bytes.Buffer
+		err = scale.NewEncoder(&buf).Encode(e)
+		assert.NoError(t, err)
+
+		decoded := Extrinsic{Version4: true, UseMultiAddress: useMultiAddress, Method: Method{Args: &UCompact{}}}
+		err = scale.NewDecoder(&buf).Decode(&decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, pair.PublicKey(), decoded.SignatureV4.Signer.PubKey[:])
+		assert.True(t, decoded.SignatureV4.Signature.IsEd25519)
+		assert.Equal(t, uint64(7), decoded.SignatureV4.Nonce)
+		assert.Equal(t, int64(0), decoded.SignatureV4.Tip.Int64())
+		assert.Equal(t, NewImmortalEra(), decoded.SignatureV4.Era)
+		assert.Equal(t, MethodIDX{1, 0}, decoded.Method.CallIndex)
+
+		// the signature covers the spec and transaction version
+		payload := ExtrinsicPayloadV4{Method: method, Era: NewImmortalEra(), Nonce: 7, SpecVersion: 2026,
+			TransactionVersion: 4}
+		copy(payload.GenesisHash[:], genesis)
+		copy(payload.BlockHash[:], genesis)
+		var pbuf bytes.Buffer
+		err = scale.NewEncoder(&pbuf).Encode(payload)
+		assert.NoError(t, err)
+		assert.True(t, pair.Verify(pbuf.Bytes(), decoded.SignatureV4.Signature))
+
+		payload.TransactionVersion = 3
+		pbuf.Reset()
+		err = scale.NewEncoder(&pbuf).Encode(payload)
+		assert.NoError(t, err)
+		assert.False(t, pair.Verify(pbuf.Bytes(), decoded.SignatureV4.Signature))
+	}
+
+	// extrinsics of the old format are rejected
+	var buf bytes.Buffer
+	err = scale.NewEncoder(&buf).Encode(NewExtrinsicWithKey(pair, 7, genesis, method))
+	assert.NoError(t, err)
+	decoded := Extrinsic{Version4: true, Method: Method{Args: &UCompact{}}}
+	err = scale.NewDecoder(&buf).Decode(&decoded)
+	assert.EqualError(t, err, "only signed extrinsics of version 4 are supported")
+}
+
+func TestExtrinsicPayloadV3_Encode(t *testing.T) {
+	p := ExtrinsicPayloadV3{
+		Method:      Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))},
+		Era:         NewImmortalEra(),
+		Nonce:       7,
+		Tip:         NewUCompact(big.NewInt(1)),
+		SpecVersion: 2026,
+	}
+	p.GenesisHash[0] = 0xaa
+	p.BlockHash[0] = 0xbb
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(p)
+	assert.NoError(t, err)
+	// call, era, compact nonce and tip, spec version, genesis and block hash
+	assert.Equal(t, "0x0100a10f"+"00"+"1c"+"04"+"ea070000"+
+		"aa"+string(bytes.Repeat([]byte("00"), 31))+"bb"+string(bytes.Repeat([]byte("00"), 31)),
+		hexutil.Encode(buf.Bytes()))
+}
+
+func TestExtrinsic_EncodeDecode_version3(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	genesis := bytes.Repeat([]byte{0x02}, 32)
+	method := Method{CallIndex: MethodIDX{1, 0}, Args: NewUCompact(big.NewInt(1000))}
+	e := NewExtrinsicWithKey(pair, 7, genesis, method)
+	e.Version3 = true
+	e.SpecVersion = 2026
+	e.Tip = NewUCompact(big.NewInt(5))
+
+	raw, err := scale.EncodeToBytes(e)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(ExtrinsicVersion3), extrinsicVersion(raw))
+
+	// the version is detected from the version byte
+	decoded, err := NewExtrinsicFromHex(hexutil.Encode(raw))
+	assert.NoError(t, err)
+	assert.True(t, decoded.Version3)
+	assert.False(t, decoded.Version4)
+	assert.True(t, decoded.IsSigned())
+	assert.Equal(t, pair.PublicKey(), decoded.SignatureV3.Signer.PubKey[:])
+	assert.Equal(t, uint64(7), decoded.SignatureV3.Nonce)
+	assert.Equal(t, int64(5), decoded.SignatureV3.Tip.Int64())
+	assert.Equal(t, NewImmortalEra(), decoded.SignatureV3.Era)
+	assert.Equal(t, MethodIDX{1, 0}, decoded.Method.CallIndex)
+
+	// the signature covers the spec version, but no transaction version
+	payload := ExtrinsicPayloadV3{Method: method, Era: NewImmortalEra(), Nonce: 7, Tip: NewUCompact(big.NewInt(5)),
+		SpecVersion: 2026}
+	copy(payload.GenesisHash[:], genesis)
+	copy(payload.BlockHash[:], genesis)
+	pb, err := scale.EncodeToBytes(payload)
+	assert.NoError(t, err)
+	sig := signature.MultiSignature{IsEd25519: true, AsEd25519: decoded.SignatureV3.Signature.Hash}
+	assert.True(t, pair.Verify(pb, sig))
+
+	// a version 4 payload doesn't match it
+	pb4, err := scale.EncodeToBytes(ExtrinsicPayloadV4{Method: method, Era: NewImmortalEra(), Nonce: 7,
+		Tip: NewUCompact(big.NewInt(5)), SpecVersion: 2026, GenesisHash: payload.GenesisHash,
+		BlockHash: payload.BlockHash})
+	assert.NoError(t, err)
+	assert.False(t, pair.Verify(pb4, sig))
+
+	// extrinsics of version 4 are rejected
+	e.Version3, e.Version4 = false, true
+	raw, err = scale.EncodeToBytes(e)
+	assert.NoError(t, err)
+	decoded = Extrinsic{Version3: true}
+	err = scale.NewDecoder(bytes.NewReader(raw)).Decode(&decoded)
+	assert.EqualError(t, err, "only signed extrinsics of version 3 are supported")
+}
@@ -0,0 +1,239 @@
+package substrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/centrifuge/go-substrate-rpc-client/jsonrpc"
+)
+
+// ExtrinsicStatus is the status of a submitted extrinsic, see Author.SubmitAndWatchExtrinsic
+type ExtrinsicStatus struct {
+	IsFuture    bool
+	IsReady     bool
+	IsBroadcast bool
+	// AsBroadcast are the ids of the peers the extrinsic was broadcast to
+	AsBroadcast       []string
+	IsInBlock         bool
+	AsInBlock         Hash
+	IsRetracted       bool
+	AsRetracted       Hash
+	IsFinalityTimeout bool
+	AsFinalityTimeout Hash
+	IsFinalized       bool
+	AsFinalized       Hash
+	IsUsurped         bool
+	AsUsurped         Hash
+	IsDropped         bool
+	IsInvalid         bool
+}
+
+// UnmarshalJSON decodes the status, which is either a string like "ready" or an object like {"inBlock": "0x..."}
+func (s *ExtrinsicStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		switch name {
+		case "future":
+			s.IsFuture = true
+		case "ready":
+			s.IsReady = true
+		case "dropped":
+			s.IsDropped = true
+		case "invalid":
+			s.IsInvalid = true
+		default:
+			return fmt.Errorf("unknown extrinsic status %s", name)
+		}
+		return nil
+	}
+
+	var obj map[string]json.RawMessage
+	err := json.Unmarshal(data, &obj)
+	if err != nil {
+		return err
+	}
+	if len(obj) != 1 {
+		return fmt.Errorf("unknown extrinsic status %s", data)
+	}
+
+	for name, value := range obj {
+		switch name {
+		case "broadcast":
+			s.IsBroadcast = true
+			return json.Unmarshal(value, &s.AsBroadcast)
+		case "inBlock":
+			s.IsInBlock = true
+			return json.Unmarshal(value, &s.AsInBlock)
+		case "retracted":
+			s.IsRetracted = true
+			return json.Unmarshal(value, &s.AsRetracted)
+		case "finalityTimeout":
+			s.IsFinalityTimeout = true
+			return json.Unmarshal(value, &s.AsFinalityTimeout)
+		case "finalized":
+			s.IsFinalized = true
+			return json.Unmarshal(value, &s.AsFinalized)
+		case "usurped":
+			s.IsUsurped = true
+			return json.Unmarshal(value, &s.AsUsurped)
+		}
+	}
+	return fmt.Errorf("unknown extrinsic status %s", data)
+}
+
+// MarshalJSON encodes the status in the format of the node, the inverse of UnmarshalJSON
+func (s ExtrinsicStatus) MarshalJSON() ([]byte, error) {
+	switch {
+	case s.IsFuture:
+		return json.Marshal("future")
+	case s.IsReady:
+		return json.Marshal("ready")
+	case s.IsBroadcast:
+		return json.Marshal(map[string][]string{"broadcast": s.AsBroadcast})
+	case s.IsInBlock:
+		return json.Marshal(map[string]Hash{"inBlock": s.AsInBlock})
+	case s.IsRetracted:
+		return json.Marshal(map[string]Hash{"retracted": s.AsRetracted})
+	case s.IsFinalityTimeout:
+		return json.Marshal(map[string]Hash{"finalityTimeout": s.AsFinalityTimeout})
+	case s.IsFinalized:
+		return json.Marshal(map[string]Hash{"finalized": s.AsFinalized})
+	case s.IsUsurped:
+		return json.Marshal(map[string]Hash{"usurped": s.AsUsurped})
+	case s.IsDropped:
+		return json.Marshal("dropped")
+	case s.IsInvalid:
+		return json.Marshal("invalid")
+	}
+	return nil, errors.New("extrinsic status not set")
+}
+
+// isTerminal returns true if the node sends no further status updates
+func (s ExtrinsicStatus) isTerminal() bool {
+	return s.IsFinalized || s.IsFinalityTimeout || s.IsUsurped || s.IsDropped || s.IsInvalid
+}
+
+// ExtrinsicStatusSubscription delivers the status updates of a submitted extrinsic
+type ExtrinsicStatusSubscription struct {
+	sub     *jsonrpc.Subscription
+	channel chan ExtrinsicStatus
+
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// Chan returns the channel the status updates are delivered to. It is closed after the extrinsic is finalized,
+// dropped, invalid or usurped, or when the subscription ends with an error.
+func (s *ExtrinsicStatusSubscription) Chan() <-chan ExtrinsicStatus {
+	return s.channel
+}
+
+// Err returns a channel that receives the error that ended the subscription, if any
+func (s *ExtrinsicStatusSubscription) Err() <-chan error {
+	return s.sub.Err()
+}
+
+// Unsubscribe ends the subscription
+func (s *ExtrinsicStatusSubscription) Unsubscribe() {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+	s.sub.Unsubscribe()
+}
+
+func (s *ExtrinsicStatusSubscription) forward(in <-chan ExtrinsicStatus) {
+	defer close(s.channel)
+
+	for status := range in {
+		select {
+		case s.channel <- status:
+		case <-s.quit:
+			return
+		}
+
+		if status.isTerminal() {
+			s.Unsubscribe()
+			return
+		}
+	}
+}
+
+// SubmitAndWatchExtrinsic submits the extrinsic like SubmitExtrinsic and delivers its status updates until it is
+// finalized or can't be included anymore. The subscription ends with jsonrpc.ErrSubscriptionLost if the client
+// reconnects, as subscribing again would submit the extrinsic again.
+func (a *Author) SubmitAndWatchExtrinsic(accountNonce uint64, method string, args Args) (
+	*ExtrinsicStatusSubscription, error) {
+	eb, err := a.encodeExtrinsic(accountNonce, method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	in := make(chan ExtrinsicStatus)
+	sub, err := a.client.Subscribe(jsonrpc.NoResubscribe(context.Background()), "author_submitAndWatchExtrinsic",
+		"author_unwatchExtrinsic", in, eb)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &ExtrinsicStatusSubscription{sub: sub, channel: make(chan ExtrinsicStatus), quit: make(chan struct{})}
+	go s.forward(in)
+	return s, nil
+}
+
+// SubmitAndWaitFinalized submits the extrinsic like SubmitAndWatchExtrinsic and blocks until it is finalized. It
+// returns the hash of the block the extrinsic was finalized in, or an error if it is dropped, invalid, usurped or not
+// finalized in time. It returns ctx.Err() once the context is done, use context.WithTimeout to limit the wait.
+func (a *Author) SubmitAndWaitFinalized(ctx context.Context, accountNonce uint64, method string, args Args) (Hash,
+	error) {
+	s, err := a.SubmitAndWatchExtrinsic(accountNonce, method, args)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Unsubscribe()
+
+	return waitFinalized(ctx, s.Chan(), s.Err())
+}
+
+// waitFinalized waits for the finalized status of an extrinsic, see SubmitAndWaitFinalized
+func waitFinalized(ctx context.Context, statuses <-chan ExtrinsicStatus, errc <-chan error) (Hash, error) {
+	for {
+		select {
+		case status, ok := <-statuses:
+			if !ok {
+				// the error that ended the subscription is sent before the channels are closed
+				select {
+				case err := <-errc:
+					if err != nil {
+						return nil, err
+					}
+				default:
+				}
+				return nil, errors.New("the subscription ended before the extrinsic was finalized")
+			}
+
+			switch {
+			case status.IsFinalized:
+				return status.AsFinalized, nil
+			case status.IsFinalityTimeout:
+				return nil, fmt.Errorf("extrinsic in block %v was not finalized in time", status.AsFinalityTimeout.String())
+			case status.IsUsurped:
+				return nil, fmt.Errorf("extrinsic was usurped by %v", status.AsUsurped.String())
+			case status.IsDropped:
+				return nil, errors.New("extrinsic was dropped")
+			case status.IsInvalid:
+				return nil, errors.New("extrinsic is invalid")
+			}
+		case err := <-errc:
+			if err != nil {
+				return nil, err
+			}
+			// errc is closed, the statuses are closed as well
+			errc = nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtrinsicStatus_UnmarshalJSON(t *testing.T) {
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	for _, test := range []struct {
+		json     string
+		status   ExtrinsicStatus
+		terminal bool
+	}{
+		{`"future"`, ExtrinsicStatus{IsFuture: true}, false},
+		{`"ready"`, ExtrinsicStatus{IsReady: true}, false},
+		{`{"broadcast":["QmPeer1","QmPeer2"]}`,
+			ExtrinsicStatus{IsBroadcast: true, AsBroadcast: []string{"QmPeer1", "QmPeer2"}}, false},
+		{`{"inBlock":"` + testBlockHash + `"}`, ExtrinsicStatus{IsInBlock: true, AsInBlock: hash}, false},
+		{`{"retracted":"` + testBlockHash + `"}`, ExtrinsicStatus{IsRetracted: true, AsRetracted: hash}, false},
+		{`{"finalityTimeout":"` + testBlockHash + `"}`,
+			ExtrinsicStatus{IsFinalityTimeout: true, AsFinalityTimeout: hash}, true},
+		{`{"finalized":"` + testBlockHash + `"}`, ExtrinsicStatus{IsFinalized: true, AsFinalized: hash}, true},
+		{`{"usurped":"` + testBlockHash + `"}`, ExtrinsicStatus{IsUsurped: true, AsUsurped: hash}, true},
+		{`"dropped"`, ExtrinsicStatus{IsDropped: true}, true},
+		{`"invalid"`, ExtrinsicStatus{IsInvalid: true}, true},
+	} {
+		var s ExtrinsicStatus
+		err := json.Unmarshal([]byte(test.json), &s)
+		assert.NoError(t, err)
+		assert.Equal(t, test.status, s)
+		assert.Equal(t, test.terminal, s.isTerminal())
+
+		b, err := json.Marshal(s)
+		assert.NoError(t, err)
+		assert.JSONEq(t, test.json, string(b))
+	}
+}
+
+func TestExtrinsicStatus_UnmarshalJSON_unknown(t *testing.T) {
+	var s ExtrinsicStatus
+	assert.EqualError(t, json.Unmarshal([]byte(`"pending"`), &s), "unknown extrinsic status pending")
+	assert.EqualError(t, json.Unmarshal([]byte(`{"included":"0x00"}`), &s),
+		`unknown extrinsic status {"included":"0x00"}`)
+	assert.Error(t, json.Unmarshal([]byte(`42`), &s))
+
+	_, err := json.Marshal(ExtrinsicStatus{})
+	assert.Error(t, err)
+}
+
+func TestWaitFinalized(t *testing.T) {
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	for _, test := range []struct {
+		status ExtrinsicStatus
+		err    string
+	}{
+		{ExtrinsicStatus{IsFinalized: true, AsFinalized: hash}, ""},
+		{ExtrinsicStatus{IsFinalityTimeout: true, AsFinalityTimeout: hash},
+			"extrinsic in block " + testBlockHash + " was not finalized in time"},
+		{ExtrinsicStatus{IsUsurped: true, AsUsurped: hash}, "extrinsic was usurped by " + testBlockHash},
+		{ExtrinsicStatus{IsDropped: true}, "extrinsic was dropped"},
+		{ExtrinsicStatus{IsInvalid: true}, "extrinsic is invalid"},
+	} {
+		// the statuses before the terminal one are skipped
+		statuses := make(chan ExtrinsicStatus, 3)
+		statuses <- ExtrinsicStatus{IsReady: true}
+		statuses <- ExtrinsicStatus{IsInBlock: true, AsInBlock: hash}
+		statuses <- test.status
+		h, err := waitFinalized(context.Background(), statuses, make(chan error))
+		if test.err != "" {
+			assert.EqualError(t, err, test.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, hash, h)
+	}
+
+	// the subscription ends with an error
+	statuses := make(chan ExtrinsicStatus)
+	errc := make(chan error, 1)
+	errc <- errors.New("connection lost")
+	close(errc)
+	close(statuses)
+	_, err := waitFinalized(context.Background(), statuses, errc)
+	assert.EqualError(t, err, "connection lost")
+
+	// or without one
+	errc = make(chan error)
+	close(errc)
+	_, err = waitFinalized(context.Background(), statuses, errc)
+	assert.EqualError(t, err, "the subscription ended before the extrinsic was finalized")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = waitFinalized(ctx, make(chan ExtrinsicStatus), make(chan error))
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestAuthor_SubmitAndWatchExtrinsic(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	a := NewAuthorRPCWithKey(testClient, bytes.Repeat([]byte{0x02}, 32), pair)
+
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	testServer.SetExtrinsicStatuses(`"ready"`, `{"inBlock":"`+testBlockHash+`"}`,
+		`{"finalized":"`+testBlockHash+`"}`)
+	defer testServer.SetExtrinsicStatuses()
+
+	sub, err := a.SubmitAndWatchExtrinsic(7, "Balances.transfer", NewUCompact(big.NewInt(1)))
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	var statuses []ExtrinsicStatus
+	timeout := time.After(time.Second)
+	for done := false; !done; {
+		select {
+		case status, ok := <-sub.Chan():
+			if !ok {
+				done = true
+				break
+			}
+			statuses = append(statuses, status)
+		case <-timeout:
+			t.Fatal("the subscription didn't end")
+		}
+	}
+
+	// the channel is closed after the finalized status
+	assert.Equal(t, []ExtrinsicStatus{
+		{IsReady: true},
+		{IsInBlock: true, AsInBlock: hash},
+		{IsFinalized: true, AsFinalized: hash},
+	}, statuses)
+	assert.Nil(t, <-sub.Err())
+
+	h, err := a.SubmitAndWaitFinalized(context.Background(), 7, "Balances.transfer", NewUCompact(big.NewInt(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, hash, h)
+}
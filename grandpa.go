@@ -0,0 +1,44 @@
+package substrate
+
+type Grandpa struct {
+	client Client
+}
+
+func NewGrandpaRPC(client Client) *Grandpa {
+	return &Grandpa{client: client}
+}
+
+// RoundState is the result of grandpa_roundState, the state of the best round and the background rounds of the
+// current authority set
+type RoundState struct {
+	SetID      uint32  `json:"setId"`
+	Best       Round   `json:"best"`
+	Background []Round `json:"background"`
+}
+
+// Round is the state of a GRANDPA voting round. Finality stalls while the prevotes or precommits of the best round
+// don't reach the threshold weight.
+type Round struct {
+	Round           uint64 `json:"round"`
+	TotalWeight     uint32 `json:"totalWeight"`
+	ThresholdWeight uint32 `json:"thresholdWeight"`
+	Prevotes        Votes  `json:"prevotes"`
+	Precommits      Votes  `json:"precommits"`
+}
+
+// Votes are the prevotes or precommits of a round, Missing are the SS58 addresses of the voters that didn't vote yet
+type Votes struct {
+	CurrentWeight uint32   `json:"currentWeight"`
+	Missing       []string `json:"missing"`
+}
+
+// RoundState returns the state of the current GRANDPA rounds, the node returns an error until it voted in a round
+func (g *Grandpa) RoundState() (*RoundState, error) {
+	var s RoundState
+	err := g.client.Call(&s, "grandpa_roundState")
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
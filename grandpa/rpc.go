@@ -0,0 +1,11 @@
+package grandpa
+
+import (
+	"github.com/centrifuge/go-substrate-rpc-client"
+)
+
+// RoundState returns the state of the current GRANDPA rounds, finality stalls while the best round doesn't reach the
+// threshold weight of precommits
+func RoundState(client substrate.Client) (*substrate.RoundState, error) {
+	return substrate.NewGrandpaRPC(client).RoundState()
+}
@@ -0,0 +1,52 @@
+// +build tests
+
+package substrate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRoundState = `{"setId":2,"best":{"round":78,"totalWeight":4,"thresholdWeight":3,` +
+	`"prevotes":{"currentWeight":4,"missing":[]},` +
+	`"precommits":{"currentWeight":2,"missing":["5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty",` +
+	`"5FLSigC9HGRKVhB9FiEo4Y3koPsNmBmLJbpXg2mp1hXcS59Y"]}},` +
+	`"background":[{"round":77,"totalWeight":4,"thresholdWeight":3,` +
+	`"prevotes":{"currentWeight":4,"missing":[]},"precommits":{"currentWeight":4,"missing":[]}}]}`
+
+func TestGrandpa_RoundState(t *testing.T) {
+	testServer.SetRoundState(testRoundState)
+	s, err := NewGrandpaRPC(testClient).RoundState()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), s.SetID)
+	assert.Equal(t, uint64(78), s.Best.Round)
+	assert.Len(t, s.Background, 1)
+}
+
+func TestRoundState_JSON(t *testing.T) {
+	var s RoundState
+	err := json.Unmarshal([]byte(testRoundState), &s)
+	assert.NoError(t, err)
+	assert.Equal(t, RoundState{
+		SetID: 2,
+		Best: Round{
+			Round:           78,
+			TotalWeight:     4,
+			ThresholdWeight: 3,
+			Prevotes:        Votes{CurrentWeight: 4, Missing: []string{}},
+			Precommits: Votes{CurrentWeight: 2, Missing: []string{
+				"5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty",
+				"5FLSigC9HGRKVhB9FiEo4Y3koPsNmBmLJbpXg2mp1hXcS59Y",
+			}},
+		},
+		Background: []Round{{
+			Round:           77,
+			TotalWeight:     4,
+			ThresholdWeight: 3,
+			Prevotes:        Votes{CurrentWeight: 4, Missing: []string{}},
+			Precommits:      Votes{CurrentWeight: 4, Missing: []string{}},
+		}},
+	}, s)
+}
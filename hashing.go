@@ -0,0 +1,33 @@
+package substrate
+
+import "github.com/minio/blake2b-simd"
+
+// Blake2_128 returns the blake2b-128 hash of data, as hashed by the Blake2_128 storage hasher
+func Blake2_128(data []byte) []byte {
+	// the size is valid, so creating the hasher can't fail
+	h, _ := blake2bHash(data, 16)
+	return h
+}
+
+// Blake2_256 returns the blake2b-256 hash of data, the hash of blocks and extrinsics and of the Blake2_256 storage
+// hasher
+func Blake2_256(data []byte) []byte {
+	h := blake2b.Sum256(data)
+	return h[:]
+}
+
+// Blake2_512 returns the blake2b-512 hash of data
+func Blake2_512(data []byte) []byte {
+	h := blake2b.Sum512(data)
+	return h[:]
+}
+
+// blake2bHash returns the blake2b hash of data with size bytes, size must be between 1 and 64
+func blake2bHash(data []byte, size uint8) ([]byte, error) {
+	hasher, err := blake2b.New(&blake2b.Config{Size: size})
+	if err != nil {
+		return nil, err
+	}
+	hasher.Write(data)
+	return hasher.Sum(nil), nil
+}
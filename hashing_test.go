@@ -0,0 +1,33 @@
+// +build tests
+
+package substrate
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlake2(t *testing.T) {
+	assert.Equal(t, "0xcae66941d9efbd404e4d88758ea67670", hexutil.Encode(Blake2_128(nil)))
+	assert.Equal(t, "0x0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		hexutil.Encode(Blake2_256(nil)))
+	assert.Equal(t, "0x786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419d25e1031afee585313896444934eb"+
+		"04b903a685b1448b755d56f701afe9be2ce", hexutil.Encode(Blake2_512(nil)))
+
+	// the storage hashers use the same hashes
+	data := []byte("Balances")
+	for _, test := range []struct {
+		hasher StorageHasherV11
+		hash   []byte
+	}{
+		{HasherBlake2_128, Blake2_128(data)},
+		{HasherBlake2_256, Blake2_256(data)},
+		{HasherBlake2_128Concat, append(Blake2_128(data), data...)},
+	} {
+		h, err := test.hasher.hash(data)
+		assert.NoError(t, err)
+		assert.Equal(t, test.hash, h)
+	}
+}
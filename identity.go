@@ -0,0 +1,109 @@
+package substrate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// maxRawDataLen is the maximum length of raw Data
+const maxRawDataLen = 32
+
+// Data is the value of an identity field of the identity pallet, eg: the display name. It is either none, raw bytes
+// of up to 32 bytes or a 32 byte hash of the value. Raw bytes are encoded with their length in the variant index,
+// 1 for empty bytes up to 33 for 32 bytes, the hashes follow with 34 to 37.
+type Data struct {
+	IsNone        bool
+	IsRaw         bool
+	AsRaw         []byte
+	IsBlakeTwo256 bool
+	AsBlakeTwo256 [32]byte
+	IsSha256      bool
+	AsSha256      [32]byte
+	IsKeccak256   bool
+	AsKeccak256   [32]byte
+	IsShaThree256 bool
+	AsShaThree256 [32]byte
+}
+
+// NewDataRaw creates Data with the raw bytes, eg: a display name. Encoding fails if there are more than 32 bytes.
+func NewDataRaw(b []byte) Data {
+	return Data{IsRaw: true, AsRaw: b}
+}
+
+func (d *Data) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	*d = Data{}
+	switch {
+	case b == 0:
+		d.IsNone = true
+		return nil
+	case b <= maxRawDataLen+1:
+		d.IsRaw = true
+		d.AsRaw = make([]byte, b-1)
+		if len(d.AsRaw) == 0 {
+			return nil
+		}
+		return decoder.Read(d.AsRaw)
+	case b == maxRawDataLen+2:
+		d.IsBlakeTwo256 = true
+		return decoder.Read(d.AsBlakeTwo256[:])
+	case b == maxRawDataLen+3:
+		d.IsSha256 = true
+		return decoder.Read(d.AsSha256[:])
+	case b == maxRawDataLen+4:
+		d.IsKeccak256 = true
+		return decoder.Read(d.AsKeccak256[:])
+	case b == maxRawDataLen+5:
+		d.IsShaThree256 = true
+		return decoder.Read(d.AsShaThree256[:])
+	}
+	return fmt.Errorf("unknown data %d", b)
+}
+
+func (d Data) Encode(encoder scale.Encoder) error {
+	var err error
+	switch {
+	case d.IsNone:
+		return encoder.PushByte(0)
+	case d.IsRaw:
+		if len(d.AsRaw) > maxRawDataLen {
+			return fmt.Errorf("raw data of %d bytes exceeds %d bytes", len(d.AsRaw), maxRawDataLen)
+		}
+		err = encoder.PushByte(byte(len(d.AsRaw) + 1))
+		if err != nil {
+			return err
+		}
+		return encoder.Write(d.AsRaw)
+	case d.IsBlakeTwo256:
+		err = encoder.PushByte(maxRawDataLen + 2)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(d.AsBlakeTwo256[:])
+	case d.IsSha256:
+		err = encoder.PushByte(maxRawDataLen + 3)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(d.AsSha256[:])
+	case d.IsKeccak256:
+		err = encoder.PushByte(maxRawDataLen + 4)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(d.AsKeccak256[:])
+	case d.IsShaThree256:
+		err = encoder.PushByte(maxRawDataLen + 5)
+		if err != nil {
+			return err
+		}
+		return encoder.Write(d.AsShaThree256[:])
+	}
+	return errors.New("data not set")
+}
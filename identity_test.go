@@ -0,0 +1,53 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestData_EncodeDecode(t *testing.T) {
+	var hash [32]byte
+	copy(hash[:], hexutil.MustDecode(AlicePubKey))
+
+	for _, test := range []struct {
+		data    Data
+		encoded string
+	}{
+		{Data{IsNone: true}, "0x00"},
+		{NewDataRaw([]byte{}), "0x01"},
+		{NewDataRaw([]byte("Alice")), "0x06416c696365"},
+		{NewDataRaw(bytes.Repeat([]byte{0xab}, 32)), "0x21" + strings.Repeat("ab", 32)},
+		{Data{IsBlakeTwo256: true, AsBlakeTwo256: hash}, "0x22" + AlicePubKey[2:]},
+		{Data{IsSha256: true, AsSha256: hash}, "0x23" + AlicePubKey[2:]},
+		{Data{IsKeccak256: true, AsKeccak256: hash}, "0x24" + AlicePubKey[2:]},
+		{Data{IsShaThree256: true, AsShaThree256: hash}, "0x25" + AlicePubKey[2:]},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.data)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, hexutil.Encode(buf.Bytes()))
+
+		var decoded Data
+		err = scale.NewDecoder(&buf).Decode(&decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, test.data, decoded)
+	}
+
+	_, err := scale.EncodeToBytes(NewDataRaw(bytes.Repeat([]byte{0xab}, 33)))
+	assert.EqualError(t, err, "raw data of 33 bytes exceeds 32 bytes")
+	_, err = scale.EncodeToBytes(Data{})
+	assert.EqualError(t, err, "data not set")
+
+	var decoded Data
+	err = scale.DecodeFromBytes([]byte{0x26}, &decoded)
+	assert.EqualError(t, err, "unknown data 38")
+	err = scale.DecodeFromBytes([]byte{0x03, 0x01}, &decoded)
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+package substrate
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// U128 is an unsigned 128-bit integer, it is represented as a big.Int in Go.
+type U128 struct {
+	*big.Int
+}
+
+// NewU128 creates a new U128 type
+func NewU128(i *big.Int) U128 {
+	return U128{i}
+}
+
+func (i *U128) Decode(decoder scale.Decoder) error {
+	v, err := decodeFixedWidthUint(decoder, 16)
+	if err != nil {
+		return err
+	}
+	i.Int = v
+	return nil
+}
+
+func (i U128) Encode(encoder scale.Encoder) error {
+	return encodeFixedWidthUint(encoder, i.Int, 16)
+}
+
+// UnmarshalJSON decodes a JSON number or a decimal or hex encoded string, as nodes send large numbers as strings
+func (i *U128) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalJSONBigInt(data)
+	if err != nil {
+		return err
+	}
+
+	if v.BitLen() > 128 {
+		return fmt.Errorf("value %s does not fit into 128 bits", v)
+	}
+	i.Int = v
+	return nil
+}
+
+// U256 is an unsigned 256-bit integer, it is represented as a big.Int in Go.
+type U256 struct {
+	*big.Int
+}
+
+// NewU256 creates a new U256 type
+func NewU256(i *big.Int) U256 {
+	return U256{i}
+}
+
+func (i *U256) Decode(decoder scale.Decoder) error {
+	v, err := decodeFixedWidthUint(decoder, 32)
+	if err != nil {
+		return err
+	}
+	i.Int = v
+	return nil
+}
+
+func (i U256) Encode(encoder scale.Encoder) error {
+	return encodeFixedWidthUint(encoder, i.Int, 32)
+}
+
+// I8 is a signed 8-bit integer
+type I8 int8
+
+// NewI8 creates a new I8 type
+func NewI8(i int8) I8 {
+	return I8(i)
+}
+
+func (i *I8) Decode(decoder scale.Decoder) error {
+	b := make([]byte, 1)
+	err := decoder.Read(b)
+	if err != nil {
+		return err
+	}
+	*i = I8(b[0])
+	return nil
+}
+
+func (i I8) Encode(encoder scale.Encoder) error {
+	return encoder.Write([]byte{byte(i)})
+}
+
+// I16 is a signed 16-bit integer, it is encoded as little endian two's complement
+type I16 int16
+
+// NewI16 creates a new I16 type
+func NewI16(i int16) I16 {
+	return I16(i)
+}
+
+func (i *I16) Decode(decoder scale.Decoder) error {
+	b := make([]byte, 2)
+	err := decoder.Read(b)
+	if err != nil {
+		return err
+	}
+	*i = I16(binary.LittleEndian.Uint16(b))
+	return nil
+}
+
+func (i I16) Encode(encoder scale.Encoder) error {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, uint16(i))
+	return encoder.Write(b)
+}
+
+// I32 is a signed 32-bit integer, it is encoded as little endian two's complement
+type I32 int32
+
+// NewI32 creates a new I32 type
+func NewI32(i int32) I32 {
+	return I32(i)
+}
+
+func (i *I32) Decode(decoder scale.Decoder) error {
+	b := make([]byte, 4)
+	err := decoder.Read(b)
+	if err != nil {
+		return err
+	}
+	*i = I32(binary.LittleEndian.Uint32(b))
+	return nil
+}
+
+func (i I32) Encode(encoder scale.Encoder) error {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(i))
+	return encoder.Write(b)
+}
+
+// I64 is a signed 64-bit integer, it is encoded as little endian two's complement
+type I64 int64
+
+// NewI64 creates a new I64 type
+func NewI64(i int64) I64 {
+	return I64(i)
+}
+
+func (i *I64) Decode(decoder scale.Decoder) error {
+	b := make([]byte, 8)
+	err := decoder.Read(b)
+	if err != nil {
+		return err
+	}
+	*i = I64(binary.LittleEndian.Uint64(b))
+	return nil
+}
+
+func (i I64) Encode(encoder scale.Encoder) error {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(i))
+	return encoder.Write(b)
+}
+
+// UCompact is an unsigned integer of up to 536 bits in the SCALE compact encoding, eg: a Compact<Balance>. It is
+// represented as a big.Int in Go.
+type UCompact struct {
+	*big.Int
+}
+
+// NewUCompact creates a new UCompact type
+func NewUCompact(i *big.Int) UCompact {
+	return UCompact{i}
+}
+
+func (i *UCompact) Decode(decoder scale.Decoder) error {
+	v, err := decoder.DecodeBigUintCompact()
+	if err != nil {
+		return err
+	}
+	i.Int = v
+	return nil
+}
+
+// Encode encodes the value, a nil value is encoded as zero
+func (i UCompact) Encode(encoder scale.Encoder) error {
+	if i.Int == nil {
+		return encoder.EncodeUintCompact(0)
+	}
+	return encoder.EncodeBigUintCompact(i.Int)
+}
+
+// encodeFixedWidthUint writes v as a little endian unsigned integer of size bytes. A nil v is encoded as zero.
+func encodeFixedWidthUint(encoder scale.Encoder, v *big.Int, size int) error {
+	b := make([]byte, size)
+	if v != nil {
+		if v.Sign() < 0 {
+			return fmt.Errorf("cannot encode a negative value %s as an unsigned integer", v)
+		}
+
+		if v.BitLen() > size*8 {
+			return fmt.Errorf("value %s does not fit into %d bits", v, size*8)
+		}
+
+		// big.Int bytes are big endian
+		vb := v.Bytes()
+		for i := range vb {
+			b[i] = vb[len(vb)-1-i]
+		}
+	}
+
+	return encoder.Write(b)
+}
+
+func decodeFixedWidthUint(decoder scale.Decoder, size int) (*big.Int, error) {
+	b := make([]byte, size)
+	err := decoder.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// reverse the little endian bytes for big.Int
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+func unmarshalJSONBigInt(data []byte) (*big.Int, error) {
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		err := json.Unmarshal(data, &s)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	base := 10
+	if strings.HasPrefix(s, "0x") {
+		s, base = s[2:], 16
+	}
+	v, ok := new(big.Int).SetString(s, base)
+	if !ok || v.Sign() < 0 {
+		return nil, fmt.Errorf("invalid unsigned integer %s", data)
+	}
+	return v, nil
+}
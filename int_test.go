@@ -0,0 +1,155 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestU128_EncodeDecode(t *testing.T) {
+	v, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10) // 2^128-1
+	for _, i := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(1000000000000), v} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(NewU128(i))
+		assert.NoError(t, err)
+		assert.Equal(t, 16, buf.Len())
+
+		var dec U128
+		err = scale.NewDecoder(&buf).Decode(&dec)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, i.Cmp(dec.Int))
+	}
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(NewU128(big.NewInt(1000000000000)))
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0010a5d4e80000000000000000000000", hexutil.Encode(buf.Bytes()))
+}
+
+func TestU128_EncodeInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(NewU128(big.NewInt(-1)))
+	assert.Error(t, err)
+
+	err = scale.NewEncoder(&buf).Encode(NewU128(new(big.Int).Lsh(big.NewInt(1), 128)))
+	assert.Error(t, err)
+}
+
+func TestU256_EncodeDecode(t *testing.T) {
+	v := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(NewU256(v))
+	assert.NoError(t, err)
+	assert.Equal(t, 32, buf.Len())
+
+	var dec U256
+	err = scale.NewDecoder(&buf).Decode(&dec)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v.Cmp(dec.Int))
+
+	err = scale.NewEncoder(&buf).Encode(NewU256(new(big.Int).Lsh(big.NewInt(1), 256)))
+	assert.Error(t, err)
+}
+
+func TestSignedInts_EncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		value   interface{}
+		decoded interface{}
+		encoded string
+	}{
+		{NewI8(-1), new(I8), "0xff"},
+		{NewI8(math.MinInt8), new(I8), "0x80"},
+		{NewI8(math.MaxInt8), new(I8), "0x7f"},
+		{NewI16(-2), new(I16), "0xfeff"},
+		{NewI16(math.MinInt16), new(I16), "0x0080"},
+		{NewI32(-1), new(I32), "0xffffffff"},
+		{NewI32(-1000000), new(I32), "0xc0bdf0ff"},
+		{NewI32(math.MaxInt32), new(I32), "0xffffff7f"},
+		{NewI64(-1), new(I64), "0xffffffffffffffff"},
+		{NewI64(math.MinInt64), new(I64), "0x0000000000000080"},
+		{NewI64(1), new(I64), "0x0100000000000000"},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.value)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, hexutil.Encode(buf.Bytes()))
+
+		err = scale.NewDecoder(&buf).Decode(test.decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, reflect.ValueOf(test.decoded).Elem().Interface())
+	}
+}
+
+func TestUCompact_EncodeDecode(t *testing.T) {
+	e30, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+	maxU128 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	maxU536 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 536), big.NewInt(1))
+	for _, test := range []struct {
+		value   *big.Int
+		encoded string
+	}{
+		{big.NewInt(0), "0x00"},
+		{big.NewInt(64), "0x0101"},
+		{big.NewInt(1073741824), "0x0300000040"},
+		{new(big.Int).SetUint64(math.MaxUint64), "0x13ffffffffffffffff"},
+		{new(big.Int).Lsh(big.NewInt(1), 64), "0x17000000000000000001"},
+		{e30, "0x2700000040eaed7446d09c2c9f0c"},
+		{maxU128, "0x33ffffffffffffffffffffffffffffffff"},
+		{maxU536, "0xff" + strings.Repeat("ff", 67)},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(NewUCompact(test.value))
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, hexutil.Encode(buf.Bytes()))
+
+		var dec UCompact
+		err = scale.NewDecoder(&buf).Decode(&dec)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, test.value.Cmp(dec.Int))
+	}
+}
+
+func TestUCompact_EncodeInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(NewUCompact(big.NewInt(-1)))
+	assert.EqualError(t, err, "cannot compact-encode a negative value -1")
+
+	err = scale.NewEncoder(&buf).Encode(NewUCompact(new(big.Int).Lsh(big.NewInt(1), 536)))
+	assert.Error(t, err)
+}
+
+func TestU128_UnmarshalJSON(t *testing.T) {
+	for _, test := range []struct {
+		json  string
+		value int64
+	}{
+		{`125000000`, 125000000},
+		{`"125000000"`, 125000000},
+		{`"0x773594"`, 0x773594},
+	} {
+		var i U128
+		err := json.Unmarshal([]byte(test.json), &i)
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, i.Int64())
+	}
+
+	var i U128
+	assert.Error(t, json.Unmarshal([]byte(`"-1"`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`"abc"`), &i))
+	// only hex strings have a prefix
+	assert.Error(t, json.Unmarshal([]byte(`"0b1"`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`"0o7"`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`"1_000"`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`"0x"`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`"0x100000000000000000000000000000000"`), &i))
+}
@@ -0,0 +1,108 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// Request is a call of a batch
+type Request struct {
+	Method string
+	Args   []interface{}
+}
+
+// Response is the response to a Request of a batch, either Result or Error is set
+type Response struct {
+	Result json.RawMessage
+	Error  error
+}
+
+// Decode unmarshals the result into result, which must be a pointer. It returns the error of the response if any.
+func (r Response) Decode(result interface{}) error {
+	if r.Error != nil {
+		return r.Error
+	}
+
+	return json.Unmarshal(r.Result, result)
+}
+
+// CallBatch sends all requests in a single message and waits for all of their responses. The responses are
+// matched to the requests by id and returned in the order of the requests, a failed request only sets the Error
+// of its response.
+func (c *Client) CallBatch(requests []Request) ([]Response, error) {
+	ctx, cancel := c.callContext()
+	defer cancel()
+	return c.CallBatchContext(ctx, requests)
+}
+
+// CallBatchContext is like CallBatch, but returns ctx.Err() once the context is done without waiting for the
+// remaining responses.
+func (c *Client) CallBatchContext(ctx context.Context, requests []Request) ([]Response, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	msgs := make([]jsonMessage, len(requests))
+	ids := make([]uint64, len(requests))
+	ops := make(map[uint64]*requestOp, len(requests))
+	for i, r := range requests {
+		id, msg, err := c.newMessage(r.Method, r.Args)
+		if err != nil {
+			return nil, err
+		}
+
+		msgs[i] = msg
+		ids[i] = id
+		ops[id] = newRequestOp(nil)
+	}
+	if len(ops) != len(requests) {
+		return nil, errors.New("the ids of the requests are not unique")
+	}
+
+	b, err := json.Marshal(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	cn, err := c.addPending(ctx, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.write(cn, b)
+	if err != nil {
+		c.removePending(ids...)
+		return nil, err
+	}
+
+	responses := make([]Response, len(requests))
+	for i, id := range ids {
+		select {
+		case resp := <-ops[id].resp:
+			c.opts.logRequest(msgs[i], resp)
+			if resp.Error != nil {
+				responses[i].Error = resp.Error
+			} else {
+				responses[i].Result = resp.Result
+			}
+		case err := <-ops[id].errc:
+			c.opts.logRequest(msgs[i], nil)
+			responses[i].Error = err
+		case <-ctx.Done():
+			c.removePending(ids[i:]...)
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, c.closeErr()
+		}
+	}
+
+	return responses, nil
+}
+
+// isBatch returns true if the message is a JSON array
+func isBatch(b []byte) bool {
+	b = bytes.TrimLeft(b, " \t\r\n")
+	return len(b) > 0 && b[0] == '['
+}
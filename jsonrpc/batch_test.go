@@ -0,0 +1,55 @@
+// +build tests
+
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_CallBatch(t *testing.T) {
+	f := newFakeConn()
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		switch req.Method {
+		case "test_echo":
+			var args []string
+			_ = json.Unmarshal(req.Params, &args)
+			b, _ := json.Marshal(args[0])
+			return response(string(b))
+		case "test_hang":
+			return nil
+		default:
+			return []*jsonMessage{{Error: &RPCError{Code: -32601, Message: "Method not found"}}}
+		}
+	})
+	c := newClient(f, nil)
+	defer c.Close()
+
+	res, err := c.CallBatch([]Request{
+		{Method: "test_echo", Args: []interface{}{"a"}},
+		{Method: "test_unknown"},
+		{Method: "test_echo", Args: []interface{}{"b"}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, res, 3)
+
+	var s string
+	assert.NoError(t, res[0].Decode(&s))
+	assert.Equal(t, "a", s)
+	assert.EqualError(t, res[1].Decode(&s), "Method not found")
+	assert.NoError(t, res[2].Decode(&s))
+	assert.Equal(t, "b", s)
+
+	res, err = c.CallBatch(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = c.CallBatchContext(ctx, []Request{{Method: "test_echo", Args: []interface{}{"a"}}, {Method: "test_hang"}})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,540 @@
+// Package jsonrpc implements a JSON-RPC 2.0 client over websockets.
+//
+// Unlike the go-ethereum rpc client it supports substrate style subscriptions, where the subscribe and
+// unsubscribe methods are arbitrary (eg: chain_subscribeNewHeads) and notifications are routed by their
+// subscription id instead of by the notification method name.
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	version = "2.0"
+
+	// defaultOrigin is sent on the websocket handshake, substrate nodes don't validate it by default
+	defaultOrigin = "http://localhost/"
+)
+
+// ErrClientClosed is returned for calls on a closed client
+var ErrClientClosed = errors.New("client is closed")
+
+// conn is the message based transport used by the client
+type conn interface {
+	readMessage() ([]byte, error)
+	writeMessage([]byte) error
+	close() error
+}
+
+type wsConn struct {
+	ws *websocket.Conn
+}
+
+func (c wsConn) readMessage() ([]byte, error) {
+	var b []byte
+	err := websocket.Message.Receive(c.ws, &b)
+	return b, err
+}
+
+func (c wsConn) writeMessage(b []byte) error {
+	return websocket.Message.Send(c.ws, string(b))
+}
+
+func (c wsConn) close() error {
+	return c.ws.Close()
+}
+
+type jsonMessage struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+func (m *jsonMessage) isNotification() bool {
+	return m.ID == nil && m.Method != ""
+}
+
+type subscriptionParams struct {
+	Subscription json.RawMessage `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// RPCError is the error object of a failed call, calls return it as *RPCError. Nodes often put the reason into Data,
+// eg: the reason an extrinsic is invalid.
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = fmt.Sprintf("json-rpc error %d", e.Code)
+	}
+
+	if len(e.Data) == 0 {
+		return msg
+	}
+
+	// data is usually a string
+	var s string
+	if json.Unmarshal(e.Data, &s) == nil {
+		return msg + ": " + s
+	}
+	return msg + ": " + string(e.Data)
+}
+
+// ErrorCode returns the JSON-RPC error code
+func (e *RPCError) ErrorCode() int {
+	return e.Code
+}
+
+// ErrorData returns the raw JSON data of the error, nil if there is none
+func (e *RPCError) ErrorData() interface{} {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return e.Data
+}
+
+// requestOp is a request waiting for its response
+type requestOp struct {
+	resp chan *jsonMessage
+	// errc receives the error if the connection is lost before the response arrives
+	errc chan error
+	// sub is set for subscribe requests, it is registered as soon as the response arrives
+	sub *Subscription
+}
+
+func newRequestOp(sub *Subscription) *requestOp {
+	return &requestOp{resp: make(chan *jsonMessage, 1), errc: make(chan error, 1), sub: sub}
+}
+
+// Client is a JSON-RPC client. It is safe for concurrent use.
+//
+// If the connection is lost the client reconnects, see WithMaxRetries. Pending requests fail with the connection
+// error, new requests wait for the new connection and active subscriptions are subscribed again.
+type Client struct {
+	// dial opens a new connection, it is nil if the client cannot reconnect
+	dial func() (conn, error)
+	opts options
+
+	idCounter uint64
+
+	writeLock sync.Mutex
+
+	// mu guards conn, ready, pending, subs and err
+	mu sync.Mutex
+	// conn is nil while reconnecting, ready is closed once it is set
+	conn    conn
+	ready   chan struct{}
+	pending map[uint64]*requestOp
+	subs    map[string]*Subscription
+	err     error
+
+	// closed is closed once the connection is gone, err holds the reason
+	closed chan struct{}
+}
+
+// Dial connects to the websocket endpoint at url, eg: ws://127.0.0.1:9944
+func Dial(url string, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+	config, err := websocket.NewConfig(url, defaultOrigin)
+	if err != nil {
+		return nil, err
+	}
+	config.Header = o.header
+	config.TlsConfig = o.tlsConfig
+	config.Dialer = o.dialer
+
+	dial := func() (conn, error) {
+		ws, err := websocket.DialConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return wsConn{ws}, nil
+	}
+
+	c, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(c, dial, opts...), nil
+}
+
+// newClient creates a client on the connection c, dial is used to reconnect and may be nil
+func newClient(c conn, dial func() (conn, error), opts ...Option) *Client {
+	o := newOptions(opts)
+
+	ready := make(chan struct{})
+	close(ready)
+
+	cl := &Client{
+		dial:    dial,
+		opts:    o,
+		conn:    c,
+		ready:   ready,
+		pending: make(map[uint64]*requestOp),
+		subs:    make(map[string]*Subscription),
+		closed:  make(chan struct{}),
+	}
+	go cl.readLoop(c)
+	return cl
+}
+
+// Call performs a JSON-RPC call with the given arguments and unmarshals the result into result, which must be
+// a pointer or nil.
+func (c *Client) Call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := c.callContext()
+	defer cancel()
+	return c.CallContext(ctx, result, method, args...)
+}
+
+// callContext returns the context of calls without a context, it is bound by the timeout option
+func (c *Client) callContext() (context.Context, context.CancelFunc) {
+	if c.opts.timeout > 0 {
+		return context.WithTimeout(context.Background(), c.opts.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// CallContext is like Call, but returns ctx.Err() once the context is done without waiting for the response.
+func (c *Client) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	resp, err := c.request(ctx, newRequestOp(nil), method, args)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Close closes the connection, pending calls and active subscriptions fail with ErrClientClosed.
+func (c *Client) Close() {
+	c.mu.Lock()
+	cn := c.conn
+	c.mu.Unlock()
+
+	c.shutdown(ErrClientClosed)
+	if cn != nil {
+		cn.close()
+	}
+}
+
+func (c *Client) nextID() uint64 {
+	if c.opts.nextID != nil {
+		return c.opts.nextID()
+	}
+	return atomic.AddUint64(&c.idCounter, 1)
+}
+
+// request sends the request and waits for its response, op.sub is registered on success
+func (c *Client) request(ctx context.Context, op *requestOp, method string, args []interface{}) (*jsonMessage, error) {
+	id, msg, err := c.newMessage(method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	cn, err := c.addPending(ctx, map[uint64]*requestOp{id: op})
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.write(cn, b)
+	if err != nil {
+		c.removePending(id)
+		return nil, err
+	}
+
+	var resp *jsonMessage
+	defer func() {
+		c.opts.logRequest(msg, resp)
+	}()
+
+	select {
+	case resp = <-op.resp:
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp, nil
+	case err := <-op.errc:
+		return nil, err
+	case <-ctx.Done():
+		c.removePending(id)
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, c.closeErr()
+	}
+}
+
+// newMessage creates the request message for method with a new id
+func (c *Client) newMessage(method string, args []interface{}) (uint64, jsonMessage, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+
+	params, err := json.Marshal(args)
+	if err != nil {
+		return 0, jsonMessage{}, err
+	}
+
+	id := c.nextID()
+	return id, jsonMessage{Version: version, ID: json.RawMessage(strconv.FormatUint(id, 10)), Method: method,
+		Params: params}, nil
+}
+
+// addPending registers the requests by id and returns the connection to send them on. It waits while the client
+// is reconnecting and fails if the client is closed.
+func (c *Client) addPending(ctx context.Context, ops map[uint64]*requestOp) (conn, error) {
+	for {
+		c.mu.Lock()
+		if c.err != nil {
+			c.mu.Unlock()
+			return nil, c.err
+		}
+
+		if c.conn != nil {
+			// a reused id would route the response to the wrong request
+			for id := range ops {
+				if _, ok := c.pending[id]; ok {
+					c.mu.Unlock()
+					return nil, fmt.Errorf("request id %v is already pending", id)
+				}
+			}
+			for id, op := range ops {
+				c.pending[id] = op
+			}
+			cn := c.conn
+			c.mu.Unlock()
+			return cn, nil
+		}
+
+		ready := c.ready
+		c.mu.Unlock()
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-c.closed:
+			return nil, c.closeErr()
+		}
+	}
+}
+
+func (c *Client) write(cn conn, b []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return cn.writeMessage(b)
+}
+
+func (c *Client) removePending(ids ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range ids {
+		delete(c.pending, id)
+	}
+}
+
+func (c *Client) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+func (c *Client) readLoop(cn conn) {
+	for {
+		b, err := cn.readMessage()
+		if err != nil {
+			c.connectionLost(cn, err)
+			return
+		}
+
+		if isBatch(b) {
+			var msgs []jsonMessage
+			err = json.Unmarshal(b, &msgs)
+			if err != nil {
+				continue
+			}
+
+			for i := range msgs {
+				c.handle(&msgs[i])
+			}
+			continue
+		}
+
+		var msg jsonMessage
+		err = json.Unmarshal(b, &msg)
+		if err != nil {
+			// not a JSON-RPC message, nothing we could route it to
+			continue
+		}
+
+		c.handle(&msg)
+	}
+}
+
+func (c *Client) handle(msg *jsonMessage) {
+	if msg.isNotification() {
+		var params subscriptionParams
+		err := json.Unmarshal(msg.Params, &params)
+		if err != nil {
+			return
+		}
+
+		c.mu.Lock()
+		sub := c.subs[string(params.Subscription)]
+		c.mu.Unlock()
+		if sub != nil {
+			sub.deliver(params.Result)
+		}
+		return
+	}
+
+	id, err := strconv.ParseUint(string(msg.ID), 10, 64)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	op := c.pending[id]
+	delete(c.pending, id)
+	// register the subscription before handling the next message, so no notification is missed
+	if op != nil && op.sub != nil && msg.Error == nil {
+		op.sub.id = msg.Result
+		c.subs[string(msg.Result)] = op.sub
+	}
+	c.mu.Unlock()
+
+	if op != nil {
+		op.resp <- msg
+	}
+}
+
+// connectionLost fails the pending requests of the lost connection cn and starts reconnecting. The client is shut
+// down instead if it cannot reconnect.
+func (c *Client) connectionLost(cn conn, err error) {
+	c.mu.Lock()
+	if c.err != nil || c.conn != cn {
+		c.mu.Unlock()
+		return
+	}
+
+	if c.dial == nil || c.opts.maxRetries == 0 {
+		c.mu.Unlock()
+		c.shutdown(err)
+		return
+	}
+
+	c.conn = nil
+	c.ready = make(chan struct{})
+	pending := c.pending
+	c.pending = make(map[uint64]*requestOp)
+	c.mu.Unlock()
+
+	cn.close()
+	for _, op := range pending {
+		op.errc <- err
+	}
+
+	c.setState(Disconnected)
+	go c.reconnect(err)
+}
+
+// reconnect dials with exponential backoff until it succeeds, the retries are exhausted or the client is closed
+func (c *Client) reconnect(err error) {
+	backoff := c.opts.initialBackoff
+	for attempt := 1; c.opts.maxRetries < 0 || attempt <= c.opts.maxRetries; attempt++ {
+		select {
+		case <-time.After(backoff):
+		case <-c.closed:
+			return
+		}
+
+		cn, dialErr := c.dial()
+		if dialErr == nil {
+			c.connected(cn)
+			return
+		}
+		err = dialErr
+
+		backoff *= 2
+		if backoff > c.opts.maxBackoff {
+			backoff = c.opts.maxBackoff
+		}
+	}
+
+	c.shutdown(err)
+}
+
+// connected switches to the new connection cn and subscribes the active subscriptions again
+func (c *Client) connected(cn conn) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		cn.close()
+		return
+	}
+
+	c.conn = cn
+	close(c.ready)
+	subs := c.subs
+	c.subs = make(map[string]*Subscription)
+	c.mu.Unlock()
+
+	go c.readLoop(cn)
+	c.setState(Connected)
+
+	for _, sub := range subs {
+		c.resubscribe(sub)
+	}
+}
+
+// shutdown fails all pending requests and subscriptions with err, only the first call has an effect
+func (c *Client) shutdown(err error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.err = err
+	subs := c.subs
+	c.subs = make(map[string]*Subscription)
+	c.pending = make(map[uint64]*requestOp)
+	close(c.closed)
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close(err)
+	}
+
+	c.setState(Closed)
+}
+
+func (c *Client) setState(state ConnectionState) {
+	if c.opts.onStateChange != nil {
+		c.opts.onStateChange(state)
+	}
+}
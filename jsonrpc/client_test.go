@@ -0,0 +1,617 @@
+// +build tests
+
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConn is an in memory conn, the test acts as the node on the other end
+type fakeConn struct {
+	toClient   chan []byte
+	fromClient chan []byte
+	closed     chan struct{}
+	closeOnce  sync.Once
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{toClient: make(chan []byte, 100), fromClient: make(chan []byte, 100), closed: make(chan struct{})}
+}
+
+func (f *fakeConn) readMessage() ([]byte, error) {
+	select {
+	case b := <-f.toClient:
+		return b, nil
+	case <-f.closed:
+		return nil, io.EOF
+	}
+}
+
+func (f *fakeConn) writeMessage(b []byte) error {
+	select {
+	case f.fromClient <- b:
+		return nil
+	case <-f.closed:
+		return io.EOF
+	}
+}
+
+func (f *fakeConn) close() error {
+	f.closeOnce.Do(func() { close(f.closed) })
+	return nil
+}
+
+// serve answers requests with handler until the conn is closed. The messages returned by handler are sent in
+// order, the ones without a method are responses to the request. No messages means no response.
+func (f *fakeConn) serve(handler func(req jsonMessage) []*jsonMessage) {
+	for {
+		select {
+		case b := <-f.fromClient:
+			if isBatch(b) {
+				f.serveBatch(b, handler)
+				continue
+			}
+
+			var req jsonMessage
+			err := json.Unmarshal(b, &req)
+			if err != nil {
+				panic(err)
+			}
+			for _, msg := range handler(req) {
+				msg.Version = version
+				if msg.Method == "" {
+					msg.ID = req.ID
+				}
+				f.send(msg)
+			}
+		case <-f.closed:
+			return
+		}
+	}
+}
+
+// serveBatch answers a batch request with a batch of the responses in reverse order
+func (f *fakeConn) serveBatch(b []byte, handler func(req jsonMessage) []*jsonMessage) {
+	var reqs []jsonMessage
+	err := json.Unmarshal(b, &reqs)
+	if err != nil {
+		panic(err)
+	}
+
+	var resps []*jsonMessage
+	for i := len(reqs) - 1; i >= 0; i-- {
+		for _, msg := range handler(reqs[i]) {
+			msg.Version = version
+			msg.ID = reqs[i].ID
+			resps = append(resps, msg)
+		}
+	}
+
+	b, err = json.Marshal(resps)
+	if err != nil {
+		panic(err)
+	}
+	f.toClient <- b
+}
+
+func response(result string) []*jsonMessage {
+	return []*jsonMessage{{Result: json.RawMessage(result)}}
+}
+
+func (f *fakeConn) send(msg *jsonMessage) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	f.toClient <- b
+}
+
+func notification(subID string, result string) *jsonMessage {
+	params := `{"subscription":` + subID + `,"result":` + result + `}`
+	return &jsonMessage{Method: "test_notification", Params: json.RawMessage(params)}
+}
+
+func TestClient_Call(t *testing.T) {
+	f := newFakeConn()
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		switch req.Method {
+		case "test_echo":
+			var args []string
+			_ = json.Unmarshal(req.Params, &args)
+			b, _ := json.Marshal(args[0])
+			return response(string(b))
+		case "test_noParams":
+			return response(string(req.Params))
+		case "test_hang":
+			return nil
+		default:
+			return []*jsonMessage{{Error: &RPCError{Code: -32601, Message: "Method not found"}}}
+		}
+	})
+	c := newClient(f, nil)
+	defer c.Close()
+
+	var res string
+	err := c.Call(&res, "test_echo", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", res)
+
+	var params []interface{}
+	err = c.Call(&params, "test_noParams")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, params)
+
+	err = c.Call(&res, "test_unknown")
+	assert.EqualError(t, err, "Method not found")
+	assert.Equal(t, -32601, err.(*RPCError).ErrorCode())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = c.CallContext(ctx, &res, "test_hang")
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestClient_idGenerator(t *testing.T) {
+	ids := make(chan string, 10)
+	serve := func(req jsonMessage) []*jsonMessage {
+		ids <- string(req.ID)
+		if req.Method == "test_hang" {
+			return nil
+		}
+		return response("true")
+	}
+
+	// the clients share the ids
+	next := NewIDCounter()
+	var clients []*Client
+	for i := 0; i < 2; i++ {
+		f := newFakeConn()
+		go f.serve(serve)
+		c := newClient(f, nil, WithIDGenerator(next))
+		defer c.Close()
+		clients = append(clients, c)
+	}
+
+	for _, c := range clients {
+		err := c.Call(nil, "test_echo")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "1", <-ids)
+	assert.Equal(t, "2", <-ids)
+
+	// a generator that repeats ids fails instead of mixing up responses
+	f := newFakeConn()
+	go f.serve(serve)
+	c := newClient(f, nil, WithIDGenerator(func() uint64 { return 7 }))
+	defer c.Close()
+
+	done := make(chan error)
+	go func() {
+		done <- c.Call(nil, "test_hang")
+	}()
+	assert.Equal(t, "7", <-ids)
+	err := c.Call(nil, "test_echo")
+	assert.EqualError(t, err, "request id 7 is already pending")
+
+	_, err = c.CallBatch([]Request{{Method: "test_echo"}, {Method: "test_echo"}})
+	assert.EqualError(t, err, "the ids of the requests are not unique")
+
+	c.Close()
+	assert.Error(t, <-done)
+}
+
+// requestLog records the requests passed to a request logger
+type requestLog struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *requestLog) log(method string, req, resp []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, method+" "+string(req)+" "+string(resp))
+}
+
+func (l *requestLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string{}, l.entries...)
+}
+
+func TestClient_requestLogger(t *testing.T) {
+	f := newFakeConn()
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		switch req.Method {
+		case "test_echo":
+			return response(`"hello"`)
+		case "test_hang":
+			return nil
+		default:
+			return []*jsonMessage{{Error: &RPCError{Code: -32601, Message: "Method not found"}}}
+		}
+	})
+	var l requestLog
+	c := newClient(f, nil, WithRequestLogger(l.log))
+	defer c.Close()
+
+	err := c.Call(nil, "test_echo", "hello")
+	assert.NoError(t, err)
+	err = c.Call(nil, "test_unknown")
+	assert.Error(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = c.CallContext(ctx, nil, "test_hang")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	_, err = c.CallBatch([]Request{{Method: "test_echo"}, {Method: "test_unknown"}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`test_echo {"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hello"]} {"jsonrpc":"2.0","id":1,` +
+			`"result":"hello"}`,
+		`test_unknown {"jsonrpc":"2.0","id":2,"method":"test_unknown","params":[]} {"jsonrpc":"2.0","id":2,` +
+			`"error":{"code":-32601,"message":"Method not found"}}`,
+		// no response arrived
+		`test_hang {"jsonrpc":"2.0","id":3,"method":"test_hang","params":[]} `,
+		// the requests of a batch are passed one by one
+		`test_echo {"jsonrpc":"2.0","id":4,"method":"test_echo","params":[]} {"jsonrpc":"2.0","id":4,` +
+			`"result":"hello"}`,
+		`test_unknown {"jsonrpc":"2.0","id":5,"method":"test_unknown","params":[]} {"jsonrpc":"2.0","id":5,` +
+			`"error":{"code":-32601,"message":"Method not found"}}`,
+	}, l.get())
+}
+
+func TestRPCError(t *testing.T) {
+	var m jsonMessage
+	err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":1010,"message":"Invalid Transaction",`+
+		`"data":"Transaction is outdated"}}`), &m)
+	assert.NoError(t, err)
+	assert.Equal(t, 1010, m.Error.ErrorCode())
+	assert.EqualError(t, m.Error, "Invalid Transaction: Transaction is outdated")
+	assert.Equal(t, json.RawMessage(`"Transaction is outdated"`), m.Error.ErrorData())
+
+	assert.EqualError(t, &RPCError{Code: 1, Data: json.RawMessage(`{"a":1}`)}, `json-rpc error 1: {"a":1}`)
+	assert.Nil(t, (&RPCError{Code: 1}).ErrorData())
+}
+
+func TestClient_Call_timeout(t *testing.T) {
+	f := newFakeConn()
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		if req.Method == "test_hang" {
+			return nil
+		}
+		return response(`"ok"`)
+	})
+	c := newClient(f, nil, WithTimeout(10*time.Millisecond))
+	defer c.Close()
+
+	var res string
+	err := c.Call(&res, "test_hang")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	_, err = c.CallBatch([]Request{{Method: "test_echo"}, {Method: "test_hang"}})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// abandoned requests don't stay pending
+	c.mu.Lock()
+	assert.Empty(t, c.pending)
+	c.mu.Unlock()
+
+	err = c.Call(&res, "test_echo")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res)
+}
+
+func TestClient_Call_concurrent(t *testing.T) {
+	f := newFakeConn()
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		return response(string(req.Params))
+	})
+	c := newClient(f, nil)
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var res []int
+			err := c.Call(&res, "test_echo", i)
+			assert.NoError(t, err)
+			assert.Equal(t, []int{i}, res)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	f := newFakeConn()
+	unsubscribed := make(chan json.RawMessage, 1)
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		switch req.Method {
+		case "test_subscribe":
+			// notifications right after the response must not be lost
+			return append(response(`"abc"`),
+				notification(`"abc"`, "1"), notification(`"unknown"`, "42"), notification(`"abc"`, "2"))
+		case "test_unsubscribe":
+			unsubscribed <- req.Params
+			return response("true")
+		}
+		return nil
+	})
+	c := newClient(f, nil)
+	defer c.Close()
+
+	ch := make(chan int)
+	sub, err := c.Subscribe(context.Background(), "test_subscribe", "test_unsubscribe", ch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, <-ch)
+	assert.Equal(t, 2, <-ch)
+
+	sub.Unsubscribe()
+	assert.Equal(t, `["abc"]`, string(<-unsubscribed))
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.Nil(t, <-sub.Err())
+
+	_, err = c.Subscribe(context.Background(), "test_subscribe", "test_unsubscribe", 1)
+	assert.Error(t, err)
+}
+
+func TestClient_Subscribe_decodeError(t *testing.T) {
+	f := newFakeConn()
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		if req.Method == "test_subscribe" {
+			return append(response("1"), notification("1", `"not a number"`))
+		}
+		return response("true")
+	})
+	c := newClient(f, nil)
+	defer c.Close()
+
+	ch := make(chan int)
+	sub, err := c.Subscribe(context.Background(), "test_subscribe", "test_unsubscribe", ch)
+	assert.NoError(t, err)
+	assert.Error(t, <-sub.Err())
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+// hookConn passes the messages the client writes to hook first, they are only sent if it returns false
+type hookConn struct {
+	*fakeConn
+	hook func(b []byte) bool
+}
+
+func (h *hookConn) writeMessage(b []byte) error {
+	if h.hook(b) {
+		return nil
+	}
+	return h.fakeConn.writeMessage(b)
+}
+
+func TestClient_Subscribe_contextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := newFakeConn()
+	var c *Client
+	h := &hookConn{fakeConn: f, hook: func(b []byte) bool {
+		var req jsonMessage
+		err := json.Unmarshal(b, &req)
+		if err != nil || req.Method != "test_subscribe" {
+			return false
+		}
+
+		// the response is handled, which registers the subscription, but the context is done before Subscribe
+		// receives the response
+		id, err := strconv.ParseUint(string(req.ID), 10, 64)
+		assert.NoError(t, err)
+		c.mu.Lock()
+		op := c.pending[id]
+		c.mu.Unlock()
+		c.handle(&jsonMessage{Version: version, ID: req.ID, Result: json.RawMessage(`"abc"`)})
+		<-op.resp
+		cancel()
+		return true
+	}}
+	c = newClient(h, nil)
+	defer c.Close()
+
+	_, err := c.Subscribe(ctx, "test_subscribe", "test_unsubscribe", make(chan int))
+	assert.Equal(t, context.Canceled, err)
+
+	// no notifications are queued for the subscription and the node cancels it
+	c.mu.Lock()
+	assert.Empty(t, c.subs)
+	c.mu.Unlock()
+
+	var req jsonMessage
+	select {
+	case b := <-f.fromClient:
+		err = json.Unmarshal(b, &req)
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the subscription was not cancelled")
+	}
+	assert.Equal(t, "test_unsubscribe", req.Method)
+	assert.Equal(t, `["abc"]`, string(req.Params))
+}
+
+func TestClient_Close(t *testing.T) {
+	f := newFakeConn()
+	go f.serve(func(req jsonMessage) []*jsonMessage {
+		if req.Method == "test_subscribe" {
+			return response(`"abc"`)
+		}
+		return nil
+	})
+	c := newClient(f, nil)
+
+	ch := make(chan int)
+	sub, err := c.Subscribe(context.Background(), "test_subscribe", "test_unsubscribe", ch)
+	assert.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- c.Call(nil, "test_hang")
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	c.Close()
+	assert.Equal(t, ErrClientClosed, <-done)
+	assert.Equal(t, ErrClientClosed, <-sub.Err())
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	err = c.Call(nil, "test_echo")
+	assert.Equal(t, ErrClientClosed, err)
+	sub.Unsubscribe()
+}
+
+func TestClient_connectionLost(t *testing.T) {
+	f := newFakeConn()
+	c := newClient(f, nil)
+	f.close()
+
+	err := c.Call(nil, "test_echo")
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestClient_reconnect(t *testing.T) {
+	conns := make(chan *fakeConn, 10)
+	dial := func() (conn, error) {
+		f := newFakeConn()
+		conns <- f
+		go f.serve(func(req jsonMessage) []*jsonMessage {
+			switch req.Method {
+			case "test_subscribe":
+				return append(response(`"abc"`), notification(`"abc"`, "1"))
+			case "test_hang":
+				return nil
+			}
+			return response("true")
+		})
+		return f, nil
+	}
+
+	states := make(chan ConnectionState, 10)
+	first, _ := dial()
+	c := newClient(first, dial, WithBackoff(time.Millisecond, time.Millisecond), WithStateHandler(func(s ConnectionState) {
+		states <- s
+	}))
+	defer c.Close()
+
+	ch := make(chan int)
+	sub, err := c.Subscribe(context.Background(), "test_subscribe", "test_unsubscribe", ch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, <-ch)
+	assert.Len(t, sub.Resubscribed(), 0)
+
+	done := make(chan error)
+	go func() {
+		done <- c.Call(nil, "test_hang")
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	(<-conns).close()
+	// the pending call fails, the subscription continues on the new connection
+	assert.Equal(t, io.EOF, <-done)
+	assert.Equal(t, Disconnected, <-states)
+	assert.Equal(t, Connected, <-states)
+	assert.Equal(t, 1, <-ch)
+	select {
+	case <-sub.Resubscribed():
+	case <-time.After(time.Second):
+		t.Fatal("no resubscribed signal")
+	}
+
+	var res bool
+	err = c.Call(&res, "test_echo")
+	assert.NoError(t, err)
+	assert.True(t, res)
+}
+
+func TestClient_reconnect_noResubscribe(t *testing.T) {
+	conns := make(chan *fakeConn, 10)
+	subscribed := make(chan struct{}, 10)
+	dial := func() (conn, error) {
+		f := newFakeConn()
+		conns <- f
+		go f.serve(func(req jsonMessage) []*jsonMessage {
+			if req.Method == "test_subscribe" {
+				subscribed <- struct{}{}
+				return append(response(`"abc"`), notification(`"abc"`, "1"))
+			}
+			return response("true")
+		})
+		return f, nil
+	}
+
+	first, _ := dial()
+	c := newClient(first, dial, WithBackoff(time.Millisecond, time.Millisecond))
+	defer c.Close()
+
+	ch := make(chan int)
+	sub, err := c.Subscribe(NoResubscribe(context.Background()), "test_subscribe", "test_unsubscribe", ch)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, <-ch)
+	<-subscribed
+
+	(<-conns).close()
+	// the subscription ends instead of being subscribed again
+	assert.Equal(t, ErrSubscriptionLost, <-sub.Err())
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	var res bool
+	err = c.Call(&res, "test_echo")
+	assert.NoError(t, err)
+	assert.True(t, res)
+	assert.Len(t, subscribed, 0)
+}
+
+func TestClient_reconnect_maxRetries(t *testing.T) {
+	dialErr := errors.New("connection refused")
+	dials := 0
+	dial := func() (conn, error) {
+		dials++
+		return nil, dialErr
+	}
+
+	states := make(chan ConnectionState, 10)
+	f := newFakeConn()
+	c := newClient(f, dial, WithMaxRetries(2), WithBackoff(time.Millisecond, time.Millisecond),
+		WithStateHandler(func(s ConnectionState) {
+			states <- s
+		}))
+
+	f.close()
+	assert.Equal(t, Disconnected, <-states)
+	assert.Equal(t, Closed, <-states)
+	assert.Equal(t, 2, dials)
+
+	err := c.Call(nil, "test_echo")
+	assert.Equal(t, dialErr, err)
+}
+
+func TestWithBackoff(t *testing.T) {
+	o := newOptions([]Option{WithBackoff(time.Second, time.Minute)})
+	assert.Equal(t, time.Second, o.initialBackoff)
+	assert.Equal(t, time.Minute, o.maxBackoff)
+
+	// doubling a zero backoff would reconnect in a busy loop
+	o = newOptions([]Option{WithBackoff(0, 0)})
+	assert.Equal(t, 100*time.Millisecond, o.initialBackoff)
+	assert.Equal(t, 100*time.Millisecond, o.maxBackoff)
+
+	o = newOptions([]Option{WithBackoff(-time.Second, time.Second)})
+	assert.Equal(t, 100*time.Millisecond, o.initialBackoff)
+	assert.Equal(t, time.Second, o.maxBackoff)
+}
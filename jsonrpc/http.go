@@ -0,0 +1,212 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+)
+
+// ErrSubscriptionsNotSupported is returned when subscribing over a transport without subscriptions, eg: HTTP
+var ErrSubscriptionsNotSupported = errors.New("subscriptions are not supported over http")
+
+// HTTPClient is a JSON-RPC client that posts each call to an HTTP endpoint, eg: http://127.0.0.1:9933. It is safe
+// for concurrent use. It supports the same calls as Client, but no subscriptions.
+type HTTPClient struct {
+	url    string
+	client *http.Client
+	opts   options
+
+	idCounter uint64
+}
+
+// DialHTTP creates a client for the HTTP endpoint at rawurl. No connection is opened until the first call, only
+// WithTimeout, WithHeader, WithTLSConfig, WithIDGenerator and WithRequestLogger apply to HTTP clients.
+func DialHTTP(rawurl string, opts ...Option) (*HTTPClient, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%s is not an http url", rawurl)
+	}
+
+	o := newOptions(opts)
+	client := new(http.Client)
+	if o.tlsConfig != nil {
+		client.Transport = &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: o.tlsConfig}
+	}
+
+	return &HTTPClient{url: rawurl, client: client, opts: o}, nil
+}
+
+// Call performs a JSON-RPC call with the given arguments and unmarshals the result into result, which must be
+// a pointer or nil.
+func (c *HTTPClient) Call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := c.callContext()
+	defer cancel()
+	return c.CallContext(ctx, result, method, args...)
+}
+
+// CallContext is like Call, but the request is cancelled once the context is done
+func (c *HTTPClient) CallContext(ctx context.Context, result interface{}, method string,
+	args ...interface{}) error {
+	msg, err := c.newMessage(method, args)
+	if err != nil {
+		return err
+	}
+
+	var resp jsonMessage
+	err = c.post(ctx, msg, &resp)
+	if err != nil {
+		c.opts.logRequest(msg, nil)
+		return err
+	}
+	c.opts.logRequest(msg, &resp)
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
+
+// CallBatch posts all requests at once and returns their responses in the order of the requests, see
+// Client.CallBatch
+func (c *HTTPClient) CallBatch(requests []Request) ([]Response, error) {
+	ctx, cancel := c.callContext()
+	defer cancel()
+	return c.CallBatchContext(ctx, requests)
+}
+
+// CallBatchContext is like CallBatch, but the request is cancelled once the context is done
+func (c *HTTPClient) CallBatchContext(ctx context.Context, requests []Request) ([]Response, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	msgs := make([]jsonMessage, len(requests))
+	for i, r := range requests {
+		msg, err := c.newMessage(r.Method, r.Args)
+		if err != nil {
+			return nil, err
+		}
+		msgs[i] = msg
+	}
+
+	var resps []jsonMessage
+	err := c.post(ctx, msgs, &resps)
+	if err != nil {
+		for _, msg := range msgs {
+			c.opts.logRequest(msg, nil)
+		}
+		return nil, err
+	}
+
+	byID := make(map[string]jsonMessage, len(resps))
+	for _, r := range resps {
+		byID[string(r.ID)] = r
+	}
+
+	responses := make([]Response, len(requests))
+	for i, msg := range msgs {
+		r, ok := byID[string(msg.ID)]
+		if ok {
+			c.opts.logRequest(msg, &r)
+		} else {
+			c.opts.logRequest(msg, nil)
+		}
+		switch {
+		case !ok:
+			responses[i].Error = fmt.Errorf("no response for request %s", msg.ID)
+		case r.Error != nil:
+			responses[i].Error = r.Error
+		default:
+			responses[i].Result = r.Result
+		}
+	}
+
+	return responses, nil
+}
+
+// Subscribe always fails with ErrSubscriptionsNotSupported
+func (c *HTTPClient) Subscribe(ctx context.Context, subscribeMethod, unsubscribeMethod string, channel interface{},
+	args ...interface{}) (*Subscription, error) {
+	return nil, ErrSubscriptionsNotSupported
+}
+
+func (c *HTTPClient) callContext() (context.Context, context.CancelFunc) {
+	if c.opts.timeout > 0 {
+		return context.WithTimeout(context.Background(), c.opts.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+func (c *HTTPClient) newMessage(method string, args []interface{}) (jsonMessage, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
+
+	params, err := json.Marshal(args)
+	if err != nil {
+		return jsonMessage{}, err
+	}
+
+	var id uint64
+	if c.opts.nextID != nil {
+		id = c.opts.nextID()
+	} else {
+		id = atomic.AddUint64(&c.idCounter, 1)
+	}
+	return jsonMessage{Version: version, ID: json.RawMessage(strconv.FormatUint(id, 10)), Method: method,
+		Params: params}, nil
+}
+
+// post sends body as JSON and unmarshals the response into result
+func (c *HTTPClient) post(ctx context.Context, body interface{}, result interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	for k, v := range c.opts.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		// report cancellations like the websocket client does
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	rb, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("http status %s: %s", resp.Status, bytes.TrimSpace(rb))
+	}
+
+	return json.Unmarshal(rb, result)
+}
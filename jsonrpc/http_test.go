@@ -0,0 +1,158 @@
+// +build tests
+
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newHTTPServer serves JSON-RPC over HTTP, the handler returns the response to a request or nil to not respond
+func newHTTPServer(handler func(req jsonMessage) *jsonMessage) *httptest.Server {
+	reply := func(req jsonMessage) *jsonMessage {
+		resp := handler(req)
+		if resp == nil {
+			time.Sleep(time.Second)
+			return &jsonMessage{Version: version, ID: req.ID, Result: json.RawMessage("null")}
+		}
+		resp.Version = version
+		resp.ID = req.ID
+		return resp
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if isBatch(b) {
+			var reqs []jsonMessage
+			_ = json.Unmarshal(b, &reqs)
+			resps := make([]*jsonMessage, len(reqs))
+			for i := range reqs {
+				resps[len(reqs)-1-i] = reply(reqs[i])
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+
+		var req jsonMessage
+		_ = json.Unmarshal(b, &req)
+		_ = json.NewEncoder(w).Encode(reply(req))
+	}))
+}
+
+func TestHTTPClient_Call(t *testing.T) {
+	s := newHTTPServer(func(req jsonMessage) *jsonMessage {
+		switch req.Method {
+		case "test_echo":
+			var args []string
+			_ = json.Unmarshal(req.Params, &args)
+			b, _ := json.Marshal(args[0])
+			return &jsonMessage{Result: b}
+		case "test_hang":
+			return nil
+		default:
+			return &jsonMessage{Error: &RPCError{Code: -32601, Message: "Method not found"}}
+		}
+	})
+	defer s.Close()
+
+	c, err := DialHTTP(s.URL, WithTimeout(100*time.Millisecond))
+	assert.NoError(t, err)
+
+	var res string
+	err = c.Call(&res, "test_echo", "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", res)
+
+	err = c.Call(&res, "test_unknown")
+	assert.EqualError(t, err, "Method not found")
+
+	err = c.Call(&res, "test_hang")
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	resps, err := c.CallBatch([]Request{{Method: "test_echo", Args: []interface{}{"a"}}, {Method: "test_unknown"},
+		{Method: "test_echo", Args: []interface{}{"b"}}})
+	assert.NoError(t, err)
+	assert.Len(t, resps, 3)
+	assert.NoError(t, resps[0].Decode(&res))
+	assert.Equal(t, "a", res)
+	assert.EqualError(t, resps[1].Decode(&res), "Method not found")
+	assert.NoError(t, resps[2].Decode(&res))
+	assert.Equal(t, "b", res)
+
+	_, err = c.Subscribe(context.Background(), "test_subscribe", "test_unsubscribe", make(chan string))
+	assert.Equal(t, ErrSubscriptionsNotSupported, err)
+}
+
+func TestHTTPClient_errors(t *testing.T) {
+	_, err := DialHTTP("ws://127.0.0.1:9944")
+	assert.EqualError(t, err, "ws://127.0.0.1:9944 is not an http url")
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer s.Close()
+
+	c, err := DialHTTP(s.URL)
+	assert.NoError(t, err)
+	err = c.Call(nil, "test_echo")
+	assert.EqualError(t, err, "http status 403 Forbidden: forbidden")
+}
+
+func TestHTTPClient_header(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req jsonMessage
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(jsonMessage{Version: version, ID: req.ID, Result: json.RawMessage("null")})
+	}))
+	defer s.Close()
+
+	c, err := DialHTTP(s.URL)
+	assert.NoError(t, err)
+	err = c.Call(nil, "test_echo")
+	assert.EqualError(t, err, "http status 401 Unauthorized: unauthorized")
+
+	c, err = DialHTTP(s.URL, WithHeader("Authorization", "Bearer token"))
+	assert.NoError(t, err)
+	err = c.Call(nil, "test_echo")
+	assert.NoError(t, err)
+}
+
+func TestHTTPClient_requestLogger(t *testing.T) {
+	s := newHTTPServer(func(req jsonMessage) *jsonMessage {
+		return &jsonMessage{Result: json.RawMessage(`"hello"`)}
+	})
+	defer s.Close()
+
+	var l requestLog
+	c, err := DialHTTP(s.URL, WithRequestLogger(l.log))
+	assert.NoError(t, err)
+
+	err = c.Call(nil, "test_echo", "hello")
+	assert.NoError(t, err)
+	_, err = c.CallBatch([]Request{{Method: "test_echo"}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`test_echo {"jsonrpc":"2.0","id":1,"method":"test_echo","params":["hello"]} {"jsonrpc":"2.0","id":1,` +
+			`"result":"hello"}`,
+		`test_echo {"jsonrpc":"2.0","id":2,"method":"test_echo","params":[]} {"jsonrpc":"2.0","id":2,` +
+			`"result":"hello"}`,
+	}, l.get())
+
+	// the server is gone, no response arrives
+	s.Close()
+	err = c.Call(nil, "test_echo")
+	assert.Error(t, err)
+	assert.Equal(t, `test_echo {"jsonrpc":"2.0","id":3,"method":"test_echo","params":[]} `, l.get()[2])
+}
@@ -0,0 +1,188 @@
+package jsonrpc
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ConnectionState is the state of the connection of a client
+type ConnectionState int
+
+const (
+	// Connected is the state of a connected client, it is reported once the client reconnected
+	Connected ConnectionState = iota
+	// Disconnected is the state while the client reconnects
+	Disconnected
+	// Closed is the final state, the client is closed or it gave up reconnecting
+	Closed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case Connected:
+		return "connected"
+	case Disconnected:
+		return "disconnected"
+	case Closed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// Option configures a client, see Dial
+type Option func(*options)
+
+type options struct {
+	// maxRetries is the number of reconnect attempts, negative retries forever
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	onStateChange  func(ConnectionState)
+	// timeout limits calls without a context, 0 waits forever
+	timeout time.Duration
+	// header is sent on the websocket handshake and with each HTTP request
+	header    http.Header
+	tlsConfig *tls.Config
+	dialer    *net.Dialer
+	// nextID returns the id of the next request, nil counts from 1 per client
+	nextID func() uint64
+	// requestLogger receives the re-encoded JSON of each request and its response, see WithRequestLogger
+	requestLogger func(method string, req, resp []byte)
+}
+
+func defaultOptions() options {
+	return options{
+		maxRetries:     -1,
+		initialBackoff: 500 * time.Millisecond,
+		maxBackoff:     30 * time.Second,
+		header:         make(http.Header),
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithMaxRetries limits the reconnect attempts after the connection is lost, the client is closed once they are
+// exhausted. 0 disables reconnecting, by default the client retries forever.
+func WithMaxRetries(n int) Option {
+	return func(o *options) {
+		o.maxRetries = n
+	}
+}
+
+// minBackoff replaces a non-positive initial backoff, doubling it would never make the client wait
+const minBackoff = 100 * time.Millisecond
+
+// WithBackoff sets the wait before the first reconnect attempt, it is doubled after each failed attempt up to max.
+// The default is 500ms up to 30s. A non-positive initial wait is raised to 100ms.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(o *options) {
+		if initial <= 0 {
+			initial = minBackoff
+		}
+		if max < initial {
+			max = initial
+		}
+		o.initialBackoff = initial
+		o.maxBackoff = max
+	}
+}
+
+// WithStateHandler sets a function that is called on each state transition of the connection, eg: to log
+// reconnects. It must not block.
+func WithStateHandler(f func(ConnectionState)) Option {
+	return func(o *options) {
+		o.onStateChange = f
+	}
+}
+
+// WithTimeout limits the time Call and CallBatch wait for a response, they fail with context.DeadlineExceeded once
+// it is exceeded. It doesn't apply to the Context variants, which are bound by their context. By default they wait
+// forever.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithHeader sets a header that is sent on the websocket handshake, also on reconnects, and with each request of
+// HTTP clients, eg: an Authorization header for an authenticating proxy
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.header.Set(key, value)
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of wss and https connections, eg: to trust a private CA
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = config
+	}
+}
+
+// WithDialer sets the dialer of websocket connections, eg: to set a connect timeout. It doesn't apply to HTTP
+// clients.
+func WithDialer(dialer *net.Dialer) Option {
+	return func(o *options) {
+		o.dialer = dialer
+	}
+}
+
+// WithIDGenerator sets the function that returns the ids of requests. It is called concurrently and must not return
+// an id twice while a request with it is pending, eg: share a NewIDCounter between the clients of a pool to
+// correlate requests across them in logs. By default each client counts from 1.
+func WithIDGenerator(f func() uint64) Option {
+	return func(o *options) {
+		o.nextID = f
+	}
+}
+
+// WithRequestLogger sets a function that receives the JSON of each request and of its response once it arrived, eg:
+// to debug calls. resp is nil if the call failed without a response, eg: because it timed out. Both are re-encoded
+// from the parsed messages, so they may differ from the bytes on the wire in whitespace and field order. A batch is
+// sent as one JSON array but its requests are passed one by one, each with its own response, notifications of
+// subscriptions are not passed. It is called concurrently and must not block.
+func WithRequestLogger(f func(method string, req, resp []byte)) Option {
+	return func(o *options) {
+		o.requestLogger = f
+	}
+}
+
+// logRequest passes the re-encoded request msg and its response to the request logger, resp is nil if no response
+// arrived
+func (o options) logRequest(msg jsonMessage, resp *jsonMessage) {
+	if o.requestLogger == nil {
+		return
+	}
+
+	req, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	var rb []byte
+	if resp != nil {
+		rb, err = json.Marshal(resp)
+		if err != nil {
+			return
+		}
+	}
+	o.requestLogger(msg.Method, req, rb)
+}
+
+// NewIDCounter returns an id generator that counts from 1, it is safe for concurrent use
+func NewIDCounter() func() uint64 {
+	var counter uint64
+	return func() uint64 {
+		return atomic.AddUint64(&counter, 1)
+	}
+}
@@ -0,0 +1,237 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// maxQueuedNotifications is the number of notifications buffered for a subscriber that doesn't keep up.
+const maxQueuedNotifications = 10000
+
+// ErrSubscriptionQueueOverflow is returned when a subscriber doesn't read notifications fast enough
+var ErrSubscriptionQueueOverflow = errors.New("subscription queue overflow")
+
+// ErrSubscriptionLost ends a subscription that is not subscribed again after a reconnect, see NoResubscribe
+var ErrSubscriptionLost = errors.New("subscription lost on reconnect")
+
+// noResubscribeKey is the context key of NoResubscribe
+type noResubscribeKey struct{}
+
+// NoResubscribe returns a context for Subscribe, the subscription then ends with ErrSubscriptionLost when the client
+// reconnects instead of being subscribed again. Use it for subscribe methods with side effects, eg:
+// author_submitAndWatchExtrinsic would submit the extrinsic again.
+func NoResubscribe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noResubscribeKey{}, true)
+}
+
+// Subscription delivers the notifications of a subscription to a channel
+type Subscription struct {
+	client            *Client
+	subscribeMethod   string
+	unsubscribeMethod string
+	args              []interface{}
+	id                json.RawMessage
+	// noResubscribe ends the subscription on a reconnect, see NoResubscribe
+	noResubscribe bool
+
+	// channel is the typed channel of the subscriber
+	channel reflect.Value
+	etype   reflect.Type
+
+	mu     sync.Mutex
+	queue  []json.RawMessage
+	signal chan struct{}
+
+	// resubscribed is signalled after the subscription was subscribed again on a new connection
+	resubscribed chan struct{}
+
+	errc      chan error
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe calls subscribeMethod with args and delivers the notifications of the subscription to channel,
+// which must be a writable channel of a type the notification result can be JSON decoded into.
+// The channel is closed once the subscription ends, see Err for the reason.
+func (c *Client) Subscribe(ctx context.Context, subscribeMethod, unsubscribeMethod string, channel interface{},
+	args ...interface{}) (*Subscription, error) {
+	chanVal := reflect.ValueOf(channel)
+	if chanVal.Kind() != reflect.Chan || chanVal.Type().ChanDir()&reflect.SendDir == 0 {
+		return nil, errors.New("channel must be a writable channel")
+	}
+
+	sub := &Subscription{
+		client:            c,
+		subscribeMethod:   subscribeMethod,
+		unsubscribeMethod: unsubscribeMethod,
+		args:              args,
+		noResubscribe:     ctx.Value(noResubscribeKey{}) != nil,
+		channel:           chanVal,
+		etype:             chanVal.Type().Elem(),
+		signal:            make(chan struct{}, 1),
+		resubscribed:      make(chan struct{}, 1),
+		errc:              make(chan error, 1),
+		quit:              make(chan struct{}),
+	}
+
+	_, err := c.request(ctx, newRequestOp(sub), subscribeMethod, args)
+	if err != nil {
+		// the response may have registered the subscription before ctx was done, it must not stay registered
+		// without a subscriber. The node is notified in the background, ctx may be done already.
+		sub.close(err)
+		if id, ok := c.removeSubscription(sub); ok && unsubscribeMethod != "" {
+			go c.Call(nil, unsubscribeMethod, id)
+		}
+		return nil, err
+	}
+
+	go sub.forward()
+	return sub, nil
+}
+
+// Err returns a channel that receives the error that ended the subscription, if any. It is closed once the
+// subscription ends.
+func (s *Subscription) Err() <-chan error {
+	return s.errc
+}
+
+// Resubscribed returns a channel that is signalled each time the subscription was subscribed again after the client
+// reconnected, see WithMaxRetries. Notifications sent by the node while the client was disconnected are lost, a
+// signal marks such a gap. Signals are dropped while one is pending, so it is not necessary to read the channel.
+func (s *Subscription) Resubscribed() <-chan struct{} {
+	return s.resubscribed
+}
+
+// Unsubscribe ends the subscription and notifies the node. The channel is closed and Err is closed without an error.
+func (s *Subscription) Unsubscribe() {
+	s.close(nil)
+	s.client.unsubscribe(s)
+}
+
+// deliver queues a notification, it must not block as it is called from the read loop of the client
+func (s *Subscription) deliver(result json.RawMessage) {
+	s.mu.Lock()
+	if len(s.queue) >= maxQueuedNotifications {
+		s.mu.Unlock()
+		s.close(ErrSubscriptionQueueOverflow)
+		go s.client.unsubscribe(s)
+		return
+	}
+	s.queue = append(s.queue, result)
+	s.mu.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Subscription) forward() {
+	defer s.channel.Close()
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.signal:
+				continue
+			case <-s.quit:
+				return
+			}
+		}
+		next := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		v := reflect.New(s.etype)
+		err := json.Unmarshal(next, v.Interface())
+		if err != nil {
+			s.close(err)
+			go s.client.unsubscribe(s)
+			return
+		}
+
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.quit)},
+			{Dir: reflect.SelectSend, Chan: s.channel, Send: v.Elem()},
+		}
+		chosen, _, _ := reflect.Select(cases)
+		if chosen == 0 {
+			return
+		}
+	}
+}
+
+// closed returns true once the subscription ended
+func (s *Subscription) closed() bool {
+	select {
+	case <-s.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Subscription) close(err error) {
+	s.closeOnce.Do(func() {
+		if err != nil {
+			s.errc <- err
+		}
+		close(s.errc)
+		close(s.quit)
+	})
+}
+
+// unsubscribe removes the subscription and notifies the node, errors are ignored as the subscription is gone anyway
+func (c *Client) unsubscribe(s *Subscription) {
+	id, ok := c.removeSubscription(s)
+	if !ok || s.unsubscribeMethod == "" {
+		return
+	}
+
+	_ = c.Call(nil, s.unsubscribeMethod, id)
+}
+
+// removeSubscription removes s from the subscriptions notifications are delivered to, ok is false if it wasn't
+// registered. The id is read under the lock, it is rewritten when the subscription is subscribed again.
+func (c *Client) removeSubscription(s *Subscription) (id json.RawMessage, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id = s.id
+	_, ok = c.subs[string(id)]
+	delete(c.subs, string(id))
+	return id, ok
+}
+
+// resubscribe subscribes s again after a reconnect, the notifications are delivered to the same channel
+func (c *Client) resubscribe(s *Subscription) {
+	if s.closed() {
+		return
+	}
+
+	if s.noResubscribe {
+		s.close(ErrSubscriptionLost)
+		return
+	}
+
+	_, err := c.request(context.Background(), newRequestOp(s), s.subscribeMethod, s.args)
+	if err != nil {
+		s.close(err)
+		return
+	}
+
+	// unsubscribed while subscribing again
+	if s.closed() {
+		c.unsubscribe(s)
+		return
+	}
+
+	select {
+	case s.resubscribed <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,533 @@
+package substrate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// MetadataV11 is the runtime metadata of substrate 2.0 nodes. Compared to V4 modules are no longer split into
+// name and storage prefix, storage keys are built per item from the hashed prefix and item name, and modules
+// expose their constants and errors.
+type MetadataV11 struct {
+	Modules   []ModuleMetadataV11
+	Extrinsic ExtrinsicMetadataV11
+}
+
+func (m *MetadataV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Modules)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Extrinsic)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *MetadataV11) MethodIndex(method string) MethodIDX {
+	s := strings.Split(method, ".")
+	var sIDX, mIDX uint8 = 0, 0
+	// section index
+	var sCounter = 0
+
+	for _, n := range m.Modules {
+		if n.HasCalls {
+			if n.Name == s[0] {
+				sIDX = uint8(sCounter)
+				for j, f := range n.Calls {
+					if f.Name == s[1] {
+						mIDX = uint8(j)
+					}
+				}
+			}
+			sCounter++
+		}
+	}
+
+	return MethodIDX{sIDX, mIDX}
+}
+
+// calls returns the calls of all modules in the order of their indices, the module index counts the modules with
+// calls only. It is the only place that assigns call indices, the lookups of MetadataVersioned build on it.
+func (m *MetadataV11) calls() []CallMetadata {
+	var calls []CallMetadata
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasCalls {
+			continue
+		}
+		for ci, call := range mod.Calls {
+			calls = append(calls, newCallMetadata(mod.Name, MethodIDX{mi, uint8(ci)}, call))
+		}
+		mi++
+	}
+	return calls
+}
+
+// FindEventNamesForEventID returns the module and event name of the event with the given id, the module index
+// counts the modules with events only
+func (m *MetadataV11) FindEventNamesForEventID(eventID EventID) (string, string, error) {
+	mod, event, err := m.findEvent(eventID)
+	if err != nil {
+		return "", "", err
+	}
+	return mod, event.Name, nil
+}
+
+// findEvent returns the module name and the metadata of the event with the given id
+func (m *MetadataV11) findEvent(eventID EventID) (string, *EventMetadata, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if !mod.HasEvents {
+			continue
+		}
+		if mi != eventID[0] {
+			mi++
+			continue
+		}
+		if int(eventID[1]) >= len(mod.Events) {
+			return "", nil, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+		}
+		return mod.Name, &mod.Events[eventID[1]], nil
+	}
+	return "", nil, fmt.Errorf("module index %v out of range", eventID[0])
+}
+
+// findStorageEntry returns the storage entry fn of the module with the storage prefix module
+func (m *MetadataV11) findStorageEntry(module, fn string) (*StorageEntryMetadataV11, error) {
+	for _, mod := range m.Modules {
+		if !mod.HasStorage || mod.Storage.Prefix != module {
+			continue
+		}
+
+		for i := range mod.Storage.Items {
+			if mod.Storage.Items[i].Name == fn {
+				return &mod.Storage.Items[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no meta data found for module %s function %s", module, fn)
+}
+
+type ModuleMetadataV11 struct {
+	Name       string
+	HasStorage bool
+	Storage    StorageMetadataV11
+	HasCalls   bool
+	Calls      []FunctionMetaData
+	HasEvents  bool
+	Events     []EventMetadata
+	Constants  []ModuleConstantMetadataV11
+	Errors     []ErrorMetadataV11
+}
+
+func (m *ModuleMetadataV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.HasStorage)
+	if err != nil {
+		return err
+	}
+
+	if m.HasStorage {
+		err = decoder.Decode(&m.Storage)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = decoder.Decode(&m.HasCalls)
+	if err != nil {
+		return err
+	}
+
+	if m.HasCalls {
+		err = decoder.Decode(&m.Calls)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = decoder.Decode(&m.HasEvents)
+	if err != nil {
+		return err
+	}
+
+	if m.HasEvents {
+		err = decoder.Decode(&m.Events)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = decoder.Decode(&m.Constants)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Errors)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type StorageMetadataV11 struct {
+	Prefix string
+	Items  []StorageEntryMetadataV11
+}
+
+func (m *StorageMetadataV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Prefix)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Items)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type StorageEntryMetadataV11 struct {
+	Name          string
+	Modifier      uint8
+	Type          uint8
+	Plain         string
+	Map           MapTypeV11
+	DoubleMap     DoubleMapTypeV11
+	Fallback      []byte
+	Documentation []string
+}
+
+func (s StorageEntryMetadataV11) IsMap() bool {
+	return s.Type == 1
+}
+
+func (s StorageEntryMetadataV11) IsDoubleMap() bool {
+	return s.Type == 2
+}
+
+func (s StorageEntryMetadataV11) IsPlain() bool {
+	return s.Type == 0
+}
+
+// Hashers returns the hashers of the keys, none for plain entries
+func (s StorageEntryMetadataV11) Hashers() ([]StorageHasherV11, error) {
+	switch {
+	case s.IsMap():
+		return []StorageHasherV11{s.Map.Hasher}, nil
+	case s.IsDoubleMap():
+		return []StorageHasherV11{s.DoubleMap.Hasher, s.DoubleMap.Key2Hasher}, nil
+	}
+	return nil, nil
+}
+
+// KeyTypes returns the type names of the keys, none for plain entries
+func (s StorageEntryMetadataV11) KeyTypes() []string {
+	switch {
+	case s.IsMap():
+		return []string{s.Map.Key}
+	case s.IsDoubleMap():
+		return []string{s.DoubleMap.Key1, s.DoubleMap.Key2}
+	}
+	return nil
+}
+
+// ValueType returns the type name of the value
+func (s StorageEntryMetadataV11) ValueType() string {
+	switch {
+	case s.IsMap():
+		return s.Map.Value
+	case s.IsDoubleMap():
+		return s.DoubleMap.Value
+	}
+	return s.Plain
+}
+
+func (m *StorageEntryMetadataV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Modifier)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Type)
+	if err != nil {
+		return err
+	}
+
+	switch m.Type {
+	case 0:
+		err = decoder.Decode(&m.Plain)
+	case 1:
+		err = decoder.Decode(&m.Map)
+	case 2:
+		err = decoder.Decode(&m.DoubleMap)
+	default:
+		err = fmt.Errorf("unknown storage entry type %d", m.Type)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Fallback)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Documentation)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type MapTypeV11 struct {
+	Hasher StorageHasherV11
+	Key    string
+	Value  string
+	Linked bool
+}
+
+func (m *MapTypeV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Hasher)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Key)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Value)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Linked)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type DoubleMapTypeV11 struct {
+	Hasher     StorageHasherV11
+	Key1       string
+	Key2       string
+	Value      string
+	Key2Hasher StorageHasherV11
+}
+
+func (m *DoubleMapTypeV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Hasher)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Key1)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Key2)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Value)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Key2Hasher)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// StorageHasherV11 is the hasher applied to storage map keys, V11 added the Identity hasher
+type StorageHasherV11 uint8
+
+const (
+	HasherBlake2_128 StorageHasherV11 = iota
+	HasherBlake2_256
+	HasherBlake2_128Concat
+	HasherTwox128
+	HasherTwox256
+	HasherTwox64Concat
+	HasherIdentity
+)
+
+// hash applies the hasher to data
+func (h StorageHasherV11) hash(data []byte) ([]byte, error) {
+	switch h {
+	case HasherBlake2_128:
+		return Blake2_128(data), nil
+	case HasherBlake2_256:
+		return Blake2_256(data), nil
+	case HasherBlake2_128Concat:
+		return append(Blake2_128(data), data...), nil
+	case HasherTwox128:
+		return createMultiXxhash(data, 2), nil
+	case HasherTwox256:
+		return createMultiXxhash(data, 4), nil
+	case HasherTwox64Concat:
+		return append(createMultiXxhash(data, 1), data...), nil
+	case HasherIdentity:
+		return data, nil
+	}
+	return nil, errors.New("hash function type not supported")
+}
+
+func (h *StorageHasherV11) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	if b > byte(HasherIdentity) {
+		return fmt.Errorf("unknown storage hasher %d", b)
+	}
+
+	*h = StorageHasherV11(b)
+	return nil
+}
+
+type ModuleConstantMetadataV11 struct {
+	Name          string
+	Type          string
+	Value         []byte
+	Documentation []string
+}
+
+func (m *ModuleConstantMetadataV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Type)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Value)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Documentation)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type ErrorMetadataV11 struct {
+	Name          string
+	Documentation []string
+}
+
+func (m *ErrorMetadataV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Name)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.Documentation)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ExtrinsicMetadataV11 describes the extrinsic format of the runtime
+type ExtrinsicMetadataV11 struct {
+	Version          uint8
+	SignedExtensions []string
+}
+
+func (m *ExtrinsicMetadataV11) Decode(decoder scale.Decoder) error {
+	err := decoder.Decode(&m.Version)
+	if err != nil {
+		return err
+	}
+
+	err = decoder.Decode(&m.SignedExtensions)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// key creates the key of a V11 storage entry: twox128(prefix) ++ twox128(name) ++ hasher(key)
+func (s *StorageEntryMetadataV11) key(module string, key []byte) (StorageKey, error) {
+	k := append(createMultiXxhash([]byte(module), 2), createMultiXxhash([]byte(s.Name), 2)...)
+	if s.IsDoubleMap() {
+		return nil, fmt.Errorf("%s %s is a double map, use NewStorageDoubleMapKey", module, s.Name)
+	}
+
+	if s.IsMap() && key != nil {
+		hashed, err := s.Map.Hasher.hash(key)
+		if err != nil {
+			return nil, err
+		}
+		k = append(k, hashed...)
+	}
+
+	return k, nil
+}
+
+// newStorageDoubleMapKeyV11 creates the key of a V11 double map entry:
+// twox128(prefix) ++ twox128(name) ++ hasher(key1) ++ key2Hasher(key2)
+func newStorageDoubleMapKeyV11(meta *MetadataV11, module, fn string, key1, key2 []byte) (StorageKey, error) {
+	entry, err := meta.findStorageEntry(module, fn)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.IsDoubleMap() {
+		return nil, fmt.Errorf("%s %s is not a double map", module, fn)
+	}
+
+	hashed1, err := entry.DoubleMap.Hasher.hash(key1)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed2, err := entry.DoubleMap.Key2Hasher.hash(key2)
+	if err != nil {
+		return nil, err
+	}
+
+	k := append(createMultiXxhash([]byte(module), 2), createMultiXxhash([]byte(fn), 2)...)
+	k = append(k, hashed1...)
+	return append(k, hashed2...), nil
+}
@@ -0,0 +1,302 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/testrpc"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeTestMetadataV11(t *testing.T) *MetadataVersioned {
+	b, err := hexutil.Decode(testrpc.GetTestMetaDataV11())
+	assert.NoError(t, err)
+
+	m := NewMetadataVersioned()
+	err = scale.NewDecoder(bytes.NewReader(b)).Decode(m)
+	assert.NoError(t, err)
+	return m
+}
+
+func TestMetadataV11_Decode(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	assert.Equal(t, uint8(11), m.Version)
+
+	meta := m.MetadataV11
+	assert.Len(t, meta.Modules, 4)
+	assert.Equal(t, "System", meta.Modules[0].Name)
+	assert.Equal(t, "Account", meta.Modules[0].Storage.Items[0].Name)
+	assert.Equal(t, HasherBlake2_128Concat, meta.Modules[0].Storage.Items[0].Map.Hasher)
+	assert.Equal(t, "DispatchError", meta.Modules[0].Events[1].Args[0])
+	assert.Equal(t, "InvalidSpecName", meta.Modules[0].Errors[0].Name)
+	assert.False(t, meta.Modules[1].HasEvents)
+	assert.Equal(t, "MinimumPeriod", meta.Modules[1].Constants[0].Name)
+	assert.Equal(t, []byte{0xb8, 0x0b, 0, 0, 0, 0, 0, 0}, meta.Modules[1].Constants[0].Value)
+	assert.Equal(t, HasherTwox64Concat, meta.Modules[3].Storage.Items[0].DoubleMap.Key2Hasher)
+	assert.Equal(t, uint8(4), meta.Extrinsic.Version)
+	assert.Equal(t, "ChargeTransactionPayment", meta.Extrinsic.SignedExtensions[6])
+}
+
+func TestMetadataVersioned_Decode_unsupported(t *testing.T) {
+	err := scale.NewDecoder(bytes.NewReader([]byte{0x6d, 0x65, 0x74, 0x61, 0x05})).Decode(NewMetadataVersioned())
+	assert.EqualError(t, err, "unsupported metadata version 5")
+}
+
+func TestMetadataV11_MethodIndex(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	assert.Equal(t, MethodIDX{1, 0}, m.MethodIndex("Timestamp.set"))
+	assert.Equal(t, MethodIDX{2, 1}, m.MethodIndex("Balances.set_balance"))
+	assert.Equal(t, MethodIDX{2, 0}, NewMethod("Balances.transfer", nil, *m).CallIndex)
+}
+
+func TestNewStorageKey_V11(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	key, err := NewStorageKey(*m, "Timestamp", "Now", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xf0c365c3cf59d671eb72da0e7a4113c49f1f0515f462cdcf84e0f1d6045dfcbb", hexutil.Encode(key))
+
+	_, err = NewStorageKey(*m, "Timestamp", "Unknown", nil)
+	assert.EqualError(t, err, "no meta data found for module Timestamp function Unknown")
+}
+
+func TestNewStorageDoubleMapKey(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	alice, _ := hexutil.Decode(AlicePubKey)
+	era := []byte{5, 0, 0, 0}
+	key, err := NewStorageDoubleMapKey(*m, "Staking", "ErasStakers", era, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x5f3e4907f716ac89b6347d15ececedca8bde0a0ea8864605e3b68ed9cb2da01b"+
+		"39b9d2792f8bd4c305000000518366b5b1bc7c99d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d",
+		hexutil.Encode(key))
+
+	_, err = NewStorageKey(*m, "Staking", "ErasStakers", era)
+	assert.EqualError(t, err, "Staking ErasStakers is a double map, use NewStorageDoubleMapKey")
+
+	_, err = NewStorageDoubleMapKey(*m, "Timestamp", "Now", era, alice)
+	assert.EqualError(t, err, "Timestamp Now is not a double map")
+}
+
+func TestMetadataVersioned_BuildCallIndex(t *testing.T) {
+	v4 := NewMetadataVersioned()
+	err := scale.NewDecoder(bytes.NewReader(hexutil.MustDecode(testrpc.GetTestMetaData()))).Decode(v4)
+	assert.NoError(t, err)
+
+	for _, m := range []*MetadataVersioned{v4, decodeTestMetadataV11(t)} {
+		var names []string
+		for _, mod := range m.Metadata.Modules {
+			for _, c := range mod.Calls {
+				names = append(names, mod.Name+"."+c.Name)
+			}
+		}
+		for _, mod := range m.MetadataV11.Modules {
+			for _, c := range mod.Calls {
+				names = append(names, mod.Name+"."+c.Name)
+			}
+		}
+		names = append(names, "Unknown.call", "Balances.unknown")
+		assert.True(t, len(names) > 2)
+
+		searched := make([]MethodIDX, len(names))
+		for i, n := range names {
+			searched[i] = m.MethodIndex(n)
+		}
+
+		m.BuildCallIndex()
+		for i, n := range names {
+			assert.Equal(t, searched[i], m.MethodIndex(n), n)
+		}
+	}
+}
+
+func TestMetadataVersioned_Calls(t *testing.T) {
+	v4 := NewMetadataVersioned()
+	err := scale.NewDecoder(bytes.NewReader(hexutil.MustDecode(testrpc.GetTestMetaData()))).Decode(v4)
+	assert.NoError(t, err)
+
+	for _, m := range []*MetadataVersioned{v4, decodeTestMetadataV11(t)} {
+		calls := m.Calls()
+		assert.True(t, len(calls) > 2)
+		for _, c := range calls {
+			idx, err := m.FindCall(c.Name)
+			assert.NoError(t, err)
+			assert.Equal(t, idx, c.Index, c.Name)
+
+			name, args, err := m.FindCallArgs(c.Index)
+			assert.NoError(t, err)
+			assert.Equal(t, name, c.Name)
+			assert.Equal(t, args, c.Args)
+		}
+	}
+
+	calls := decodeTestMetadataV11(t).Calls()
+	assert.Equal(t, "Timestamp.set", calls[1].Name)
+	assert.Equal(t, "Balances.transfer", calls[2].Name)
+	assert.Equal(t, MethodIDX{2, 0}, calls[2].Index)
+	assert.Equal(t, []FunctionArgumentMetadata{{Name: "dest", Type: "<T::Lookup as StaticLookup>::Source"},
+		{Name: "value", Type: "Compact<T::Balance>"}}, calls[2].Args)
+	assert.NotEmpty(t, calls[2].Documentation)
+}
+
+func TestMetadataDiff(t *testing.T) {
+	from := decodeTestMetadataV11(t)
+	assert.Empty(t, MetadataDiff(from, decodeTestMetadataV11(t)))
+
+	// the Timestamp module loses its calls, the modules after it move up and Balances gains a call
+	to := decodeTestMetadataV11(t)
+	for i, mod := range to.MetadataV11.Modules {
+		switch mod.Name {
+		case "Timestamp":
+			to.MetadataV11.Modules[i].HasCalls = false
+		case "Balances":
+			to.MetadataV11.Modules[i].Calls = append(mod.Calls, FunctionMetaData{Name: "transfer_all"})
+		}
+	}
+
+	changes := MetadataDiff(from, to)
+	assert.Equal(t, CallIndexChange{Name: "Timestamp.set", Old: &MethodIDX{1, 0}}, changes[0])
+	assert.Equal(t, CallIndexChange{Name: "Balances.transfer", Old: &MethodIDX{2, 0}, New: &MethodIDX{1, 0}},
+		changes[1])
+	added := changes[len(changes)-1]
+	assert.Equal(t, "Balances.transfer_all", added.Name)
+	assert.Nil(t, added.Old)
+	assert.Equal(t, uint8(1), added.New.SectionIndex)
+
+	// calls of modules before Timestamp keep their index
+	for _, c := range changes {
+		if c.Old != nil {
+			assert.NotEqual(t, uint8(0), c.Old.SectionIndex, c.Name)
+		}
+	}
+
+	// the other direction reports the reverse changes
+	reverse := MetadataDiff(to, from)
+	assert.Len(t, reverse, len(changes))
+}
+
+func TestNewStorageKey_Blake2_128Concat(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	// System.Account of Alice, as computed by polkadot-js
+	key, err := NewStorageKey(*m, "System", "Account", hexutil.MustDecode(AlicePubKey))
+	assert.NoError(t, err)
+	assert.Equal(t, "0x26aa394eea5630e07c48ae0c9558cef7b99d880ec681799c0cf30e8886371da9"+
+		"de1e86a9a8c739864cf3cc5ec2bea59fd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d",
+		hexutil.Encode(key))
+}
+
+func TestStorageHasherV11_hash(t *testing.T) {
+	data := []byte("abc")
+	for _, test := range []struct {
+		hasher StorageHasherV11
+		hash   string
+	}{
+		{HasherBlake2_128, "0xcf4ab791c62b8d2b2109c90275287816"},
+		{HasherBlake2_256, "0xbddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		{HasherBlake2_128Concat, "0xcf4ab791c62b8d2b2109c90275287816616263"},
+		{HasherTwox128, "0x990977adf52cbc440889329981caa9be"},
+		{HasherTwox64Concat, "0x990977adf52cbc44616263"},
+		{HasherIdentity, "0x616263"},
+	} {
+		h, err := test.hasher.hash(data)
+		assert.NoError(t, err)
+		assert.Equal(t, test.hash, hexutil.Encode(h))
+
+		// metadata v4 declares the same hashers with other indexes
+		for i, v4 := range storageHashersV4 {
+			if v4 == test.hasher {
+				h, err = TypMap{Hasher: uint8(i)}.hash(data)
+				assert.NoError(t, err)
+				assert.Equal(t, test.hash, hexutil.Encode(h))
+			}
+		}
+	}
+
+	_, err := TypMap{Hasher: 5}.hash(data)
+	assert.EqualError(t, err, "unknown storage hasher 5")
+}
+
+func TestMetadataVersioned_Find(t *testing.T) {
+	v4 := NewMetadataVersioned()
+	err := scale.NewDecoder(bytes.NewReader(hexutil.MustDecode(testrpc.GetTestMetaData()))).Decode(v4)
+	assert.NoError(t, err)
+
+	// v4 modules are named in lower case
+	for _, test := range []struct {
+		meta     *MetadataVersioned
+		balances string
+	}{
+		{v4, "balances"},
+		{decodeTestMetadataV11(t), "Balances"},
+	} {
+		m := test.meta
+		idx, err := m.FindCall(test.balances + ".transfer")
+		assert.NoError(t, err)
+		assert.Equal(t, m.MethodIndex(test.balances+".transfer"), idx)
+
+		_, err = m.FindCall(test.balances + ".unknown")
+		assert.EqualError(t, err, "call unknown not found in module "+test.balances)
+		_, err = m.FindCall("Unknown.call")
+		assert.EqualError(t, err, "module Unknown not found")
+		_, err = m.FindCall("transfer")
+		assert.Error(t, err)
+
+		entry, err := m.FindStorageEntry("System", "BlockHash")
+		assert.NoError(t, err)
+		assert.True(t, entry.IsMap())
+		assert.False(t, entry.IsDoubleMap())
+
+		entry, err = m.FindStorageEntry("System", "Unknown")
+		assert.EqualError(t, err, "no meta data found for module System function Unknown")
+		assert.Nil(t, entry)
+
+		_, err = m.FindEventArgs(EventID{0, 99})
+		assert.Error(t, err)
+	}
+
+	// System.ExtrinsicFailed
+	args, err := decodeTestMetadataV11(t).FindEventArgs(EventID{0, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DispatchError", "DispatchInfo"}, args)
+
+	for _, test := range []struct {
+		meta    *MetadataVersioned
+		hashers []StorageHasherV11
+	}{
+		{v4, []StorageHasherV11{HasherBlake2_256}},
+		{decodeTestMetadataV11(t), []StorageHasherV11{HasherTwox64Concat}},
+	} {
+		entry, err := test.meta.FindStorageEntry("System", "BlockHash")
+		assert.NoError(t, err)
+		assert.False(t, entry.IsPlain())
+		hashers, err := entry.Hashers()
+		assert.NoError(t, err)
+		assert.Equal(t, test.hashers, hashers)
+		assert.Equal(t, []string{"T::BlockNumber"}, entry.KeyTypes())
+		assert.Equal(t, "T::Hash", entry.ValueType())
+
+		entry, err = test.meta.FindStorageEntry("Timestamp", "Now")
+		assert.NoError(t, err)
+		assert.True(t, entry.IsPlain())
+		hashers, err = entry.Hashers()
+		assert.NoError(t, err)
+		assert.Empty(t, hashers)
+		assert.Empty(t, entry.KeyTypes())
+		assert.Equal(t, "T::Moment", entry.ValueType())
+	}
+
+	entry, err := decodeTestMetadataV11(t).FindStorageEntry("Staking", "ErasStakers")
+	assert.NoError(t, err)
+	assert.True(t, entry.IsDoubleMap())
+	hashers, err := entry.Hashers()
+	assert.NoError(t, err)
+	assert.Equal(t, []StorageHasherV11{HasherTwox64Concat, HasherTwox64Concat}, hashers)
+	assert.Equal(t, []string{"EraIndex", "T::AccountId"}, entry.KeyTypes())
+	assert.Equal(t, "Exposure<T::AccountId, BalanceOf<T>>", entry.ValueType())
+
+	key, err := NewStorageKey(*v4, "System", "AccountNonce", hexutil.MustDecode(AlicePubKey))
+	assert.NoError(t, err)
+	assert.Equal(t, "0x5c54163a1c72509b5250f0a30b9001fdee9d9b48388b06921f1b210e81e3a1f0", hexutil.Encode(key))
+}
@@ -0,0 +1,34 @@
+package substrate
+
+import (
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+)
+
+// Moment is a timestamp in milliseconds since the unix epoch, the type of Timestamp.Now. It is SCALE encoded as a
+// u64, calls such as Timestamp.set take it as Compact<Moment>, see UCompact.
+type Moment struct {
+	time.Time
+}
+
+// NewMoment creates a Moment, the time is truncated to milliseconds
+func NewMoment(t time.Time) Moment {
+	return Moment{t.Truncate(time.Millisecond)}
+}
+
+func (m *Moment) Decode(decoder scale.Decoder) error {
+	var ms uint64
+	err := decoder.Decode(&ms)
+	if err != nil {
+		return err
+	}
+
+	m.Time = time.Unix(int64(ms/1000), int64(ms%1000)*int64(time.Millisecond))
+	return nil
+}
+
+func (m Moment) Encode(encoder scale.Encoder) error {
+	ms := m.Unix()*1000 + int64(m.Nanosecond())/int64(time.Millisecond)
+	return encoder.Encode(uint64(ms))
+}
@@ -0,0 +1,28 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoment_EncodeDecode(t *testing.T) {
+	m := NewMoment(time.Date(2020, 5, 1, 12, 30, 15, 123456789, time.UTC))
+	assert.Equal(t, 123000000, m.Nanosecond())
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(m)
+	assert.NoError(t, err)
+	// 1588336215123 ms
+	assert.Equal(t, []byte{0x53, 0x44, 0x37, 0xd0, 0x71, 0x01, 0, 0}, buf.Bytes())
+
+	var dec Moment
+	err = scale.NewDecoder(&buf).Decode(&dec)
+	assert.NoError(t, err)
+	assert.True(t, m.Equal(dec.Time))
+}
@@ -0,0 +1,57 @@
+package substrate
+
+import (
+	"fmt"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// SignatureOptions are the inputs of a signed extrinsic that an Author fetches from the node otherwise, see
+// SignExtrinsicOffline. They can be queried on an online machine and carried over to the signing machine.
+type SignatureOptions struct {
+	// Nonce is the account nonce of the signer, see system.AccountNonce. Each extrinsic of the signer needs the next
+	// nonce, extrinsics with a stale nonce are rejected.
+	Nonce uint64
+	// GenesisHash is the 32 byte hash of block 0 of the chain, see Chain.GetBlockHash
+	GenesisHash Hash
+	// SpecVersion and TransactionVersion are the versions of the runtime the extrinsic is submitted to, see
+	// State.GetRuntimeVersion. The extrinsic is rejected after a runtime upgrade that changes them.
+	SpecVersion        uint32
+	TransactionVersion uint32
+	// Tip is paid to the block author on top of the fees, in the smallest unit of the balance
+	Tip UCompact
+	// UseMultiAddress encodes the signer as MultiAddress, for runtimes that replaced Address with MultiAddress
+	UseMultiAddress bool
+	// SignedExtensions and CustomSignedExtensions are the signed extensions of the runtime, see
+	// ExtrinsicPayloadV4.SignedExtensions
+	SignedExtensions       []string
+	CustomSignedExtensions map[string]SignedExtensionData
+}
+
+// SignExtrinsicOffline signs the method with the pair and returns the hex encoded extrinsic in the version 4 format,
+// without connecting to a node. The method must have its call index set, eg: from NewMethod with metadata saved on an
+// online machine. The extrinsic is immortal, it can be submitted later with Author.SubmitEncodedExtrinsic or any
+// other client that calls author_submitExtrinsic.
+func SignExtrinsicOffline(pair signature.KeyringPair, method Method, opts SignatureOptions) (string, error) {
+	if len(opts.GenesisHash) != 32 {
+		return "", fmt.Errorf("expected a genesis hash of 32 bytes, got %d", len(opts.GenesisHash))
+	}
+
+	e := NewExtrinsicWithKey(pair, opts.Nonce, opts.GenesisHash, method)
+	e.Version4 = true
+	e.SpecVersion = opts.SpecVersion
+	e.TransactionVersion = opts.TransactionVersion
+	e.Tip = opts.Tip
+	e.UseMultiAddress = opts.UseMultiAddress
+	e.SignedExtensions = opts.SignedExtensions
+	e.CustomSignedExtensions = opts.CustomSignedExtensions
+
+	b, err := scale.EncodeToBytes(*e)
+	if err != nil {
+		return "", err
+	}
+
+	return hexutil.Encode(b), nil
+}
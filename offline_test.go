@@ -0,0 +1,74 @@
+// +build tests
+
+package substrate
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/signature"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignExtrinsicOffline(t *testing.T) {
+	pair, err := signature.NewKeyringPairFromSeed(bytes.Repeat([]byte{0x01}, 32), signature.ED25519,
+		ss58.SubstratePrefix)
+	assert.NoError(t, err)
+
+	// Balances.transfer to Alice of 1000
+	m := decodeTestMetadataV11(t)
+	dest, err := scale.EncodeToBytes(*NewAddress(hexutil.MustDecode(AlicePubKey)))
+	assert.NoError(t, err)
+	method := NewMethod("Balances.transfer", Encoded(append(dest, 0xa1, 0x0f)), *m)
+
+	opts := SignatureOptions{
+		Nonce:              5,
+		GenesisHash:        Hash(hexutil.MustDecode(testBlockHash)),
+		SpecVersion:        1,
+		TransactionVersion: 2,
+		Tip:                NewUCompact(big.NewInt(10)),
+		SignedExtensions:   NodeTemplateSignedExtensions,
+	}
+	enc, err := SignExtrinsicOffline(pair, method, opts)
+	assert.NoError(t, err)
+
+	// ed25519 signatures are deterministic
+	again, err := SignExtrinsicOffline(pair, method, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, enc, again)
+
+	e, err := NewExtrinsicFromHex(enc)
+	assert.NoError(t, err)
+	assert.True(t, e.IsSigned())
+	assert.True(t, e.Version4)
+	assert.Equal(t, *NewAddress(pair.PublicKey()), e.SignatureV4.Signer)
+	assert.Equal(t, uint64(5), e.SignatureV4.Nonce)
+	assert.Equal(t, int64(10), e.SignatureV4.Tip.Int64())
+	assert.Equal(t, MethodIDX{2, 0}, e.Method.CallIndex)
+	assert.Equal(t, Encoded(append(dest, 0xa1, 0x0f)), e.Method.Args)
+
+	// the signature is valid for the payload of the options
+	payload := ExtrinsicPayloadV4{
+		Method:             method,
+		Era:                NewImmortalEra(),
+		Nonce:              5,
+		Tip:                opts.Tip,
+		SpecVersion:        1,
+		TransactionVersion: 2,
+		SignedExtensions:   NodeTemplateSignedExtensions,
+	}
+	copy(payload.GenesisHash[:], opts.GenesisHash)
+	payload.BlockHash = payload.GenesisHash
+	b, err := scale.EncodeToBytes(payload)
+	assert.NoError(t, err)
+	assert.True(t, e.SignatureV4.Signature.IsEd25519)
+	assert.True(t, signature.Verify(pair.PublicKey(), b, e.SignatureV4.Signature))
+
+	opts.GenesisHash = opts.GenesisHash[:31]
+	_, err = SignExtrinsicOffline(pair, method, opts)
+	assert.EqualError(t, err, "expected a genesis hash of 32 bytes, got 31")
+}
@@ -0,0 +1,50 @@
+package substrate
+
+import (
+	"bytes"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type Payment struct {
+	client Client
+}
+
+func NewPaymentRPC(client Client) *Payment {
+	return &Payment{client: client}
+}
+
+// RuntimeDispatchInfo is the result of payment_queryInfo
+type RuntimeDispatchInfo struct {
+	Weight uint64        `json:"weight"`
+	Class  DispatchClass `json:"class"`
+	// PartialFee is the fee of the extrinsic without the tip
+	PartialFee U128 `json:"partialFee"`
+}
+
+// QueryInfo returns the weight, class and fee of the extrinsic at the given block, or the best block if at is nil
+func (p *Payment) QueryInfo(ext Extrinsic, at *Hash) (*RuntimeDispatchInfo, error) {
+	bb := new(bytes.Buffer)
+	err := scale.NewEncoder(bb).Encode(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.queryInfo(hexutil.Encode(bb.Bytes()), at)
+}
+
+func (p *Payment) queryInfo(extrinsic string, at *Hash) (*RuntimeDispatchInfo, error) {
+	var info RuntimeDispatchInfo
+	var err error
+	if at != nil {
+		err = p.client.Call(&info, "payment_queryInfo", extrinsic, at.String())
+	} else {
+		err = p.client.Call(&info, "payment_queryInfo", extrinsic)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
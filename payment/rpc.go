@@ -0,0 +1,11 @@
+package payment
+
+import (
+	"github.com/centrifuge/go-substrate-rpc-client"
+)
+
+// QueryInfo estimates the fee of the extrinsic at the given block, or the best block if at is nil
+func QueryInfo(client substrate.Client, ext substrate.Extrinsic, at *substrate.Hash) (*substrate.RuntimeDispatchInfo,
+	error) {
+	return substrate.NewPaymentRPC(client).QueryInfo(ext, at)
+}
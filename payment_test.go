@@ -0,0 +1,47 @@
+// +build tests
+
+package substrate
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayment_queryInfo(t *testing.T) {
+	testServer.SetQueryInfo(`{"weight":195000000,"class":"operational","partialFee":"1000000000000000000000000000000"}`)
+	p := NewPaymentRPC(testClient)
+
+	info, err := p.queryInfo("0x00", nil)
+	assert.NoError(t, err)
+	e30, _ := new(big.Int).SetString("1000000000000000000000000000000", 10)
+	assert.Equal(t, uint64(195000000), info.Weight)
+	assert.Equal(t, DispatchClass{IsOperational: true}, info.Class)
+	assert.Equal(t, 0, e30.Cmp(info.PartialFee.Int))
+
+	testServer.SetQueryInfo(`{"weight":10000,"class":"normal","partialFee":125000000}`)
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	info, err = p.queryInfo("0x00", &hash)
+	assert.NoError(t, err)
+	assert.Equal(t, DispatchClass{IsNormal: true}, info.Class)
+	assert.Equal(t, int64(125000000), info.PartialFee.Int64())
+
+	testServer.SetQueryInfo(`{"weight":10000,"class":"unknown","partialFee":0}`)
+	_, err = p.queryInfo("0x00", nil)
+	assert.EqualError(t, err, "unknown dispatch class unknown")
+}
+
+func TestRuntimeDispatchInfo_JSON(t *testing.T) {
+	var info RuntimeDispatchInfo
+	err := json.Unmarshal([]byte(`{"weight":10000,"class":"mandatory","partialFee":"0x3e8"}`), &info)
+	assert.NoError(t, err)
+	assert.Equal(t, DispatchClass{IsMandatory: true}, info.Class)
+	assert.Equal(t, int64(1000), info.PartialFee.Int64())
+
+	b, err := json.Marshal(info)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"weight":10000,"class":"mandatory","partialFee":1000}`, string(b))
+}
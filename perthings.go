@@ -0,0 +1,91 @@
+package substrate
+
+import (
+	"math"
+	"math/big"
+)
+
+// Perbill is a fraction in parts per billion, eg: the commission of a validator. It is SCALE encoded as a u32.
+type Perbill uint32
+
+// Permill is a fraction in parts per million. It is SCALE encoded as a u32.
+type Permill uint32
+
+// Percent is a fraction in parts per hundred. It is SCALE encoded as a u8.
+type Percent uint8
+
+const (
+	perbillAccuracy = 1000000000
+	permillAccuracy = 1000000
+	percentAccuracy = 100
+)
+
+// NewPerbill creates a Perbill from a fraction between 0 and 1, it is rounded to the nearest part and clamped
+func NewPerbill(f float64) Perbill {
+	return Perbill(fromFloat(f, perbillAccuracy))
+}
+
+// Float64 returns the fraction between 0 and 1
+func (p Perbill) Float64() float64 {
+	return float64(p) / perbillAccuracy
+}
+
+// Mul returns the fraction of x, eg: the commission of a reward. It is rounded like the runtime does, to the nearest
+// integer and down if both are equally near.
+func (p Perbill) Mul(x *big.Int) *big.Int {
+	return mulFraction(x, uint64(p), perbillAccuracy)
+}
+
+// NewPermill creates a Permill from a fraction between 0 and 1, it is rounded to the nearest part and clamped
+func NewPermill(f float64) Permill {
+	return Permill(fromFloat(f, permillAccuracy))
+}
+
+// Float64 returns the fraction between 0 and 1
+func (p Permill) Float64() float64 {
+	return float64(p) / permillAccuracy
+}
+
+// Mul returns the fraction of x, see Perbill.Mul
+func (p Permill) Mul(x *big.Int) *big.Int {
+	return mulFraction(x, uint64(p), permillAccuracy)
+}
+
+// NewPercent creates a Percent from a fraction between 0 and 1, it is rounded to the nearest part and clamped
+func NewPercent(f float64) Percent {
+	return Percent(fromFloat(f, percentAccuracy))
+}
+
+// Float64 returns the fraction between 0 and 1
+func (p Percent) Float64() float64 {
+	return float64(p) / percentAccuracy
+}
+
+// Mul returns the fraction of x, see Perbill.Mul
+func (p Percent) Mul(x *big.Int) *big.Int {
+	return mulFraction(x, uint64(p), percentAccuracy)
+}
+
+// fromFloat returns the parts of f for the given accuracy, f is clamped to [0, 1]
+func fromFloat(f float64, accuracy uint64) uint64 {
+	if !(f > 0) {
+		return 0
+	}
+	if f >= 1 {
+		return accuracy
+	}
+	return uint64(math.Round(f * float64(accuracy)))
+}
+
+// mulFraction returns x * parts / accuracy, rounded to the nearest integer and down on a tie
+func mulFraction(x *big.Int, parts, accuracy uint64) *big.Int {
+	acc := new(big.Int).SetUint64(accuracy)
+	q, r := new(big.Int).QuoRem(new(big.Int).Mul(x, new(big.Int).SetUint64(parts)), acc, new(big.Int))
+	if r.Lsh(r.Abs(r), 1).Cmp(acc) > 0 {
+		if x.Sign() < 0 {
+			return q.Sub(q, big.NewInt(1))
+		}
+		return q.Add(q, big.NewInt(1))
+	}
+	return q
+}
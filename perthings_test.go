@@ -0,0 +1,56 @@
+// +build tests
+
+package substrate
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerbill(t *testing.T) {
+	p := NewPerbill(0.1)
+	assert.Equal(t, Perbill(100000000), p)
+	assert.Equal(t, 0.1, p.Float64())
+	assert.Equal(t, Perbill(1000000000), NewPerbill(1.5))
+	assert.Equal(t, Perbill(0), NewPerbill(-1))
+
+	b, err := scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0xe1, 0xf5, 0x05}, b)
+
+	var dec Perbill
+	err = scale.DecodeFromBytes(b, &dec)
+	assert.NoError(t, err)
+	assert.Equal(t, p, dec)
+
+	assert.Equal(t, big.NewInt(100), p.Mul(big.NewInt(1000)))
+	// 10% of 15 is 1.5, ties are rounded down
+	assert.Equal(t, big.NewInt(1), p.Mul(big.NewInt(15)))
+	assert.Equal(t, big.NewInt(2), NewPerbill(0.11).Mul(big.NewInt(15)))
+	assert.Equal(t, big.NewInt(-2), NewPerbill(0.11).Mul(big.NewInt(-15)))
+}
+
+func TestPermill(t *testing.T) {
+	p := NewPermill(0.25)
+	assert.Equal(t, Permill(250000), p)
+	assert.Equal(t, 0.25, p.Float64())
+	assert.Equal(t, big.NewInt(250), p.Mul(big.NewInt(1000)))
+
+	b, err := scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x90, 0xd0, 0x03, 0x00}, b)
+}
+
+func TestPercent(t *testing.T) {
+	p := NewPercent(0.5)
+	assert.Equal(t, Percent(50), p)
+	assert.Equal(t, 0.5, p.Float64())
+	assert.Equal(t, big.NewInt(3), p.Mul(big.NewInt(7)))
+
+	b, err := scale.EncodeToBytes(p)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{50}, b)
+}
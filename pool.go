@@ -0,0 +1,74 @@
+package substrate
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/centrifuge/go-substrate-rpc-client/jsonrpc"
+)
+
+// ClientPool is a Client that spreads the calls over several websocket connections, so concurrent go routines don't
+// wait for each other's requests on a single connection. The metadata is cached once for all connections.
+type ClientPool struct {
+	*client
+	conns []*jsonrpc.Client
+}
+
+// NewClientPool opens size connections to the websocket endpoint of a node, see Connect
+func NewClientPool(url string, size int, opts ...jsonrpc.Option) (*ClientPool, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("pool size must be at least 1, got %d", size)
+	}
+
+	conns := make([]*jsonrpc.Client, 0, size)
+	rr := &roundRobin{}
+	for i := 0; i < size; i++ {
+		c, err := jsonrpc.Dial(url, opts...)
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, c)
+		rr.clients = append(rr.clients, c)
+	}
+
+	return &ClientPool{client: &client{rpcClient: rr}, conns: conns}, nil
+}
+
+// Close closes all connections of the pool
+func (p *ClientPool) Close() {
+	for _, c := range p.conns {
+		c.Close()
+	}
+}
+
+// roundRobin sends each call over the next client in turn
+type roundRobin struct {
+	clients []rpcClient
+	next    uint64
+}
+
+func (r *roundRobin) pick() rpcClient {
+	n := atomic.AddUint64(&r.next, 1)
+	return r.clients[n%uint64(len(r.clients))]
+}
+
+func (r *roundRobin) Call(result interface{}, method string, args ...interface{}) error {
+	return r.pick().Call(result, method, args...)
+}
+
+func (r *roundRobin) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return r.pick().CallContext(ctx, result, method, args...)
+}
+
+func (r *roundRobin) Subscribe(ctx context.Context, subscribeMethod, unsubscribeMethod string, channel interface{},
+	args ...interface{}) (*jsonrpc.Subscription, error) {
+	return r.pick().Subscribe(ctx, subscribeMethod, unsubscribeMethod, channel, args...)
+}
+
+func (r *roundRobin) CallBatch(requests []jsonrpc.Request) ([]jsonrpc.Response, error) {
+	return r.pick().CallBatch(requests)
+}
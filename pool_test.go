@@ -0,0 +1,80 @@
+// +build tests
+
+package substrate
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/jsonrpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingClient counts the calls it receives
+type countingClient struct {
+	calls int
+}
+
+func (c *countingClient) Call(result interface{}, method string, args ...interface{}) error {
+	c.calls++
+	return nil
+}
+
+func (c *countingClient) CallContext(ctx context.Context, result interface{}, method string,
+	args ...interface{}) error {
+	c.calls++
+	return nil
+}
+
+func (c *countingClient) Subscribe(ctx context.Context, subscribeMethod, unsubscribeMethod string,
+	channel interface{}, args ...interface{}) (*jsonrpc.Subscription, error) {
+	c.calls++
+	return nil, nil
+}
+
+func (c *countingClient) CallBatch(requests []jsonrpc.Request) ([]jsonrpc.Response, error) {
+	c.calls++
+	return nil, nil
+}
+
+func TestRoundRobin(t *testing.T) {
+	clients := []*countingClient{{}, {}, {}}
+	rr := &roundRobin{}
+	for _, c := range clients {
+		rr.clients = append(rr.clients, c)
+	}
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, rr.Call(nil, "test"))
+		assert.NoError(t, rr.CallContext(context.Background(), nil, "test"))
+		_, err := rr.Subscribe(context.Background(), "test_subscribe", "test_unsubscribe", nil)
+		assert.NoError(t, err)
+		_, err = rr.CallBatch(nil)
+		assert.NoError(t, err)
+	}
+
+	for _, c := range clients {
+		assert.Equal(t, 4, c.calls)
+	}
+}
+
+func TestNewClientPool(t *testing.T) {
+	_, err := NewClientPool(rpcURL, 0)
+	assert.Error(t, err)
+
+	p, err := NewClientPool(rpcURL, 3)
+	assert.NoError(t, err)
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.MetaData(false)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
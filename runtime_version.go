@@ -0,0 +1,75 @@
+package substrate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// RuntimeVersion is the version of the runtime as returned by state_getRuntimeVersion. Extrinsics must be signed
+// for the SpecVersion and TransactionVersion of the runtime that executes them, newer extrinsic formats include both
+// in the signed payload.
+type RuntimeVersion struct {
+	APIs               []RuntimeVersionAPI `json:"apis"`
+	AuthoringVersion   uint32              `json:"authoringVersion"`
+	ImplName           string              `json:"implName"`
+	ImplVersion        uint32              `json:"implVersion"`
+	SpecName           string              `json:"specName"`
+	SpecVersion        uint32              `json:"specVersion"`
+	TransactionVersion uint32              `json:"transactionVersion"`
+}
+
+// APIVersion returns the version of the runtime API with the given name, eg: "Metadata" or "TransactionPaymentApi".
+// ok is false if the runtime doesn't implement the API, calls of its methods with State.Call would fail.
+func (r RuntimeVersion) APIVersion(name string) (version uint32, ok bool) {
+	// the id of an API is the blake2b-64 hash of its name
+	id, err := blake2bHash([]byte(name), 8)
+	if err != nil {
+		return 0, false
+	}
+
+	for _, api := range r.APIs {
+		if api.APIID == hexutil.Encode(id) {
+			return api.Version, true
+		}
+	}
+	return 0, false
+}
+
+// RuntimeVersionAPI is the 8 byte id of a runtime API and the version the runtime implements
+type RuntimeVersionAPI struct {
+	APIID   string
+	Version uint32
+}
+
+// UnmarshalJSON decodes the [id, version] tuple of the RPC
+func (r *RuntimeVersionAPI) UnmarshalJSON(data []byte) error {
+	var tuple []json.RawMessage
+	err := json.Unmarshal(data, &tuple)
+	if err != nil {
+		return err
+	}
+
+	if len(tuple) != 2 {
+		return fmt.Errorf("expected an [id, version] tuple, got %s", data)
+	}
+
+	var id hexutil.Bytes
+	err = json.Unmarshal(tuple[0], &id)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(tuple[1], &r.Version)
+	if err != nil {
+		return err
+	}
+
+	r.APIID = hexutil.Encode(id)
+	return nil
+}
+
+func (r RuntimeVersionAPI) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{r.APIID, r.Version})
+}
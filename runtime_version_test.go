@@ -0,0 +1,52 @@
+// +build tests
+
+package substrate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testRuntimeVersion is the runtime version of a kusama node, shortened to two APIs
+const testRuntimeVersion = `{"apis":[["0xdf6acb689907609b",3],["0x37e397fc7c91f5e4",1]],"authoringVersion":2,` +
+	`"implName":"parity-kusama","implVersion":0,"specName":"kusama","specVersion":2026,"transactionVersion":4}`
+
+func TestRuntimeVersion_UnmarshalJSON(t *testing.T) {
+	var v RuntimeVersion
+	err := json.Unmarshal([]byte(testRuntimeVersion), &v)
+	assert.NoError(t, err)
+	assert.Equal(t, RuntimeVersion{
+		APIs:               []RuntimeVersionAPI{{"0xdf6acb689907609b", 3}, {"0x37e397fc7c91f5e4", 1}},
+		AuthoringVersion:   2,
+		ImplName:           "parity-kusama",
+		SpecName:           "kusama",
+		SpecVersion:        2026,
+		TransactionVersion: 4,
+	}, v)
+
+	b, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, testRuntimeVersion, string(b))
+
+	var api RuntimeVersionAPI
+	assert.Error(t, json.Unmarshal([]byte(`["0xdf6acb689907609b"]`), &api))
+}
+
+func TestRuntimeVersion_APIVersion(t *testing.T) {
+	var v RuntimeVersion
+	err := json.Unmarshal([]byte(testRuntimeVersion), &v)
+	assert.NoError(t, err)
+
+	version, ok := v.APIVersion("Core")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(3), version)
+
+	version, ok = v.APIVersion("Metadata")
+	assert.True(t, ok)
+	assert.Equal(t, uint32(1), version)
+
+	_, ok = v.APIVersion("TransactionPaymentApi")
+	assert.False(t, ok)
+}
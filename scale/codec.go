@@ -20,8 +20,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
+	"math/big"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 // Implementation for Parity codec in Go.
@@ -69,9 +73,7 @@ func (pe Encoder) PushByte(b byte) error {
 //   nn nn nn 11 [ / zz zz zz zz ]{4 + n}									(2**30 ... 2**536 - 1)	(u32, u64, u128, U256, U512, U520) straight LE-encoded
 // Rust implementation: see impl<'a> Encode for CompactRef<'a, u64>
 func (pe Encoder) EncodeUintCompact(v uint64) error {
-
-	// TODO: handle numbers wider than 64 bits (byte slices?)
-	// Currently, Rust implementation only seems to support u128
+	// numbers wider than 64 bits are handled by EncodeBigUintCompact
 
 	if v < 1<<30 {
 		if v < 1<<6 {
@@ -115,8 +117,50 @@ func (pe Encoder) EncodeUintCompact(v uint64) error {
 	return nil
 }
 
+// EncodeBigUintCompact writes an unsigned integer of up to 536 bits to the stream using the compact encoding, see
+// EncodeUintCompact. Values that don't fit into 64 bits are written in the big integer mode with as many little
+// endian bytes as needed.
+// Rust implementation: see impl<'a> Encode for CompactRef<'a, u128>
+func (pe Encoder) EncodeBigUintCompact(v *big.Int) error {
+	if v.Sign() < 0 {
+		return fmt.Errorf("cannot compact-encode a negative value %s", v)
+	}
+
+	if v.IsUint64() {
+		return pe.EncodeUintCompact(v.Uint64())
+	}
+
+	// big.Int bytes are big endian
+	b := v.Bytes()
+	n := len(b)
+	if n > 67 {
+		return fmt.Errorf("value %s does not fit into 536 bits", v)
+	}
+
+	err := pe.PushByte(byte(n-4)<<2 + 3)
+	if err != nil {
+		return err
+	}
+
+	le := make([]byte, n)
+	for i := range b {
+		le[i] = b[n-1-i]
+	}
+	return pe.Write(le)
+}
+
 // Encode a value to the stream.
 func (pe Encoder) Encode(value interface{}) error {
+	// Nil pointers are rejected before the Encodeable check, a value receiver Encode would panic on them
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return errors.New("Encoding null pointers not supported; consider using Option type")
+	}
+
+	// Types with their own encoding take precedence, whatever their kind is
+	if encodeable, ok := value.(Encodeable); ok {
+		return encodeable.Encode(pe)
+	}
+
 	t := reflect.TypeOf(value)
 	tk := t.Kind()
 	switch tk {
@@ -195,15 +239,38 @@ func (pe Encoder) Encode(value interface{}) error {
 			return err
 		}
 
+	// Structs without their own encoding are tuples, their fields are encoded in order. Pointer fields are
+	// Option<T>, nil is None. Structs with tagged variants are enums, see enumVariants.
 	case reflect.Struct:
-		encodeable := reflect.TypeOf((*Encodeable)(nil)).Elem()
-		if t.Implements(encodeable) {
-			err := value.(Encodeable).Encode(pe)
+		rv := reflect.ValueOf(value)
+		variants, isEnum, err := enumVariants(t)
+		if err != nil {
+			return err
+		}
+		if isEnum {
+			return pe.encodeEnum(rv, variants)
+		}
+		for i := 0; i < rv.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				return fmt.Errorf("Type %s has the unexported field %s and must implement Encodeable", t,
+					t.Field(i).Name)
+			}
+			var err error
+			if f := rv.Field(i); f.Kind() == reflect.Ptr {
+				err = pe.EncodeOption(!f.IsNil(), f.Interface())
+			} else {
+				err = pe.Encode(f.Interface())
+			}
 			if err != nil {
 				return err
 			}
-		} else {
-			return fmt.Errorf("Type %s does not support Encodeable interface", t)
+		}
+
+	// Maps are encoded like BTreeMap, as a Vec<(K, V)> sorted by the keys
+	case reflect.Map:
+		err := pe.encodeMap(reflect.ValueOf(value))
+		if err != nil {
+			return err
 		}
 
 	// Currently unsupported types
@@ -217,8 +284,6 @@ func (pe Encoder) Encode(value interface{}) error {
 		fallthrough
 	case reflect.Interface:
 		fallthrough
-	case reflect.Map:
-		fallthrough
 	case reflect.UnsafePointer:
 		fallthrough
 	case reflect.Invalid:
@@ -229,6 +294,95 @@ func (pe Encoder) Encode(value interface{}) error {
 	return nil
 }
 
+// encodeMap writes the entries of the map m as a Vec<(K, V)> sorted by the keys, in the order a BTreeMap of the
+// corresponding Rust type would have, see compareMapKeys
+func (pe Encoder) encodeMap(m reflect.Value) error {
+	type entry struct {
+		k     reflect.Value
+		key   []byte
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, m.Len())
+	for _, k := range m.MapKeys() {
+		b, err := EncodeToBytes(k.Interface())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{k: k, key: b, value: m.MapIndex(k)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if c, ok := compareMapKeys(entries[i].k, entries[j].k); ok {
+			return c < 0
+		}
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	err := pe.EncodeUintCompact(uint64(len(entries)))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err = pe.Write(e.key)
+		if err != nil {
+			return err
+		}
+		err = pe.Encode(e.value.Interface())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareMapKeys compares two map keys like Rust's Ord: booleans and numbers by their value, strings and arrays
+// lexicographically. ok is false for other kinds, they are ordered by their encoding.
+func compareMapKeys(a, b reflect.Value) (c int, ok bool) {
+	switch a.Kind() {
+	case reflect.Bool:
+		x, y := a.Bool(), b.Bool()
+		switch {
+		case x == y:
+			return 0, true
+		case !x:
+			return -1, true
+		default:
+			return 1, true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, y := a.Int(), b.Int()
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, y := a.Uint(), b.Uint()
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			c, ok := compareMapKeys(a.Index(i), b.Index(i))
+			if !ok || c != 0 {
+				return c, ok
+			}
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
 // EncodeOption stores optionally present value to the stream.
 func (pe Encoder) EncodeOption(hasValue bool, value interface{}) error {
 	if !hasValue {
@@ -249,6 +403,27 @@ func (pe Encoder) EncodeOption(hasValue bool, value interface{}) error {
 	return nil
 }
 
+// EncodeResult stores a Result<T, E> to the stream, the ok value if isOk is true and the error value otherwise.
+// A nil value is encoded as unit type (), eg: the ok value of Result<(), E>.
+func (pe Encoder) EncodeResult(isOk bool, ok interface{}, err interface{}) error {
+	value := err
+	prefix := byte(1)
+	if isOk {
+		value = ok
+		prefix = 0
+	}
+
+	e := pe.PushByte(prefix)
+	if e != nil {
+		return e
+	}
+
+	if value == nil {
+		return nil
+	}
+	return pe.Encode(value)
+}
+
 // Decoder is a wraper around a Reader that allows decoding data items from a stream.
 type Decoder struct {
 	reader io.Reader
@@ -270,6 +445,21 @@ func (pd Decoder) Read(bytes []byte) error {
 	return nil
 }
 
+// ReadAll reads the remaining bytes of the stream
+func (pd Decoder) ReadAll() ([]byte, error) {
+	return ioutil.ReadAll(pd.reader)
+}
+
+// Remaining returns the number of bytes left in the stream. It is only known if the reader reports its length, like
+// bytes.Reader, bytes.Buffer and strings.Reader do, otherwise ok is false.
+func (pd Decoder) Remaining() (n int, ok bool) {
+	r, ok := pd.reader.(interface{ Len() int })
+	if !ok {
+		return 0, false
+	}
+	return r.Len(), true
+}
+
 // ReadOneByte reads a next byte from the stream.
 // Named so to avoid a linter warning about a clash with io.ByteReader.ReadByte
 func (pd Decoder) ReadOneByte() (byte, error) {
@@ -301,6 +491,13 @@ func (pd Decoder) DecodeIntoReflectValue(target reflect.Value) error {
 		return fmt.Errorf("Unsettable value %v", t)
 	}
 
+	// Types with their own decoding take precedence, whatever their kind is
+	if target.CanAddr() {
+		if decodeable, ok := target.Addr().Interface().(Decodeable); ok {
+			return decodeable.Decode(pd)
+		}
+	}
+
 	switch t.Kind() {
 
 	// Boolean and numbers are trivially decoded via binary.Read
@@ -336,13 +533,13 @@ func (pd Decoder) DecodeIntoReflectValue(target reflect.Value) error {
 		intHolder := reflect.New(t)
 		intPointer := intHolder.Interface()
 		err := binary.Read(pd.reader, binary.LittleEndian, intPointer)
-		if err != nil && err != io.EOF {
+		if err != nil {
 			return err
 		}
 		target.Set(intHolder.Elem())
 
-	// If you want to replicate Option<T> behavior in Rust, see OptionBool and an
-	// example type OptionInt8 in tests.
+	// Pointer fields of structs are decoded as Option<T>, see the struct case. For other values, see OptionBool
+	// and an example type OptionInt8 in tests.
 	case reflect.Ptr:
 		err := pd.DecodeIntoReflectValue(target.Elem())
 		if err != nil {
@@ -353,13 +550,23 @@ func (pd Decoder) DecodeIntoReflectValue(target reflect.Value) error {
 	case reflect.Array:
 		fallthrough
 	case reflect.Slice:
-		codedLen64, _ := pd.DecodeUintCompact()
+		codedLen64, err := pd.DecodeUintCompact()
+		if err != nil {
+			return err
+		}
 		if codedLen64 > math.MaxUint32 {
 			return errors.New("Encoded array length is higher than allowed by the protocol (32-bit unsigned integer)")
 		}
 		if codedLen64 > uint64(maxInt) {
 			return errors.New("Encoded array length is higher than allowed by the platform")
 		}
+		// every item takes at least one byte, unless it has no data at all
+		if t.Elem().Size() > 0 {
+			err = pd.checkRemaining(codedLen64)
+			if err != nil {
+				return err
+			}
+		}
 		codedLen := int(codedLen64)
 		targetLen := target.Len()
 		if codedLen != targetLen {
@@ -377,6 +584,10 @@ func (pd Decoder) DecodeIntoReflectValue(target reflect.Value) error {
 				}
 			}
 		}
+		// bytes are read at once instead of one by one
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && codedLen > 0 {
+			return pd.Read(target.Bytes())
+		}
 		for i := 0; i < codedLen; i++ {
 			err := pd.DecodeIntoReflectValue(target.Index(i))
 			if err != nil {
@@ -393,19 +604,67 @@ func (pd Decoder) DecodeIntoReflectValue(target reflect.Value) error {
 		}
 		target.SetString(string(b))
 
+	// Structs without their own decoding are tuples, their fields are decoded in order
 	case reflect.Struct:
-		encodeable := reflect.TypeOf((*Decodeable)(nil)).Elem()
+		decodeable := reflect.TypeOf((*Decodeable)(nil)).Elem()
 		ptrType := reflect.PtrTo(t)
-		if ptrType.Implements(encodeable) {
+		if ptrType.Implements(decodeable) {
 			ptrVal := reflect.New(t)
 			err := ptrVal.Interface().(Decodeable).Decode(pd)
 			if err != nil {
 				return err
 			}
 			target.Set(ptrVal.Elem())
-		} else {
-			return fmt.Errorf("Type %s does not support Decodeable interface", ptrType)
+			break
+		}
+
+		variants, isEnum, err := enumVariants(t)
+		if err != nil {
+			return err
+		}
+		if isEnum {
+			return pd.decodeEnum(target, variants)
+		}
+		for i := 0; i < target.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				return fmt.Errorf("Type %s has the unexported field %s and must implement Decodeable", t,
+					t.Field(i).Name)
+			}
+			var err error
+			if f := target.Field(i); f.Kind() == reflect.Ptr {
+				err = pd.decodePointerOption(f)
+			} else {
+				err = pd.DecodeIntoReflectValue(f)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+	// Maps are decoded from a Vec<(K, V)>, like BTreeMap
+	case reflect.Map:
+		n, err := pd.DecodeUintCompact()
+		if err != nil {
+			return err
+		}
+		if n > math.MaxUint32 {
+			return errors.New("Encoded map length is higher than allowed by the protocol (32-bit unsigned integer)")
+		}
+		m := reflect.MakeMap(t)
+		for i := uint64(0); i < n; i++ {
+			k := reflect.New(t.Key()).Elem()
+			err = pd.DecodeIntoReflectValue(k)
+			if err != nil {
+				return err
+			}
+			v := reflect.New(t.Elem()).Elem()
+			err = pd.DecodeIntoReflectValue(v)
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(k, v)
 		}
+		target.Set(m)
 
 	// Currently unsupported types
 	case reflect.Complex64:
@@ -418,8 +677,6 @@ func (pd Decoder) DecodeIntoReflectValue(target reflect.Value) error {
 		fallthrough
 	case reflect.Interface:
 		fallthrough
-	case reflect.Map:
-		fallthrough
 	case reflect.UnsafePointer:
 		fallthrough
 	case reflect.Invalid:
@@ -430,7 +687,10 @@ func (pd Decoder) DecodeIntoReflectValue(target reflect.Value) error {
 
 // DecodeUintCompact decodes a compact-encoded integer. See EncodeUintCompact method.
 func (pd Decoder) DecodeUintCompact() (uint64, error) {
-	b, _ := pd.ReadOneByte()
+	b, err := pd.ReadOneByte()
+	if err != nil {
+		return 0, err
+	}
 	mode := b & 3
 	switch mode {
 	case 0:
@@ -477,15 +737,100 @@ func (pd Decoder) DecodeUintCompact() (uint64, error) {
 	}
 }
 
+// DecodeLength decodes the compact encoded length of a sequence whose items take at least one byte each, like the
+// length prefix of Bytes. A length that exceeds the remaining bytes of the stream is rejected before anything is
+// allocated for it, so that malformed input can't exhaust the memory.
+func (pd Decoder) DecodeLength() (int, error) {
+	n, err := pd.DecodeUintCompact()
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxUint32 {
+		return 0, errors.New("Encoded length is higher than allowed by the protocol (32-bit unsigned integer)")
+	}
+	if n > uint64(maxInt) {
+		return 0, errors.New("Encoded length is higher than allowed by the platform")
+	}
+	err = pd.checkRemaining(n)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// checkRemaining returns an error if the stream is known to have less than n bytes left
+func (pd Decoder) checkRemaining(n uint64) error {
+	r, ok := pd.Remaining()
+	if ok && n > uint64(r) {
+		return fmt.Errorf("Encoded length %d exceeds the %d remaining bytes", n, r)
+	}
+	return nil
+}
+
+// DecodeBigUintCompact decodes a compact-encoded integer of up to 536 bits. See EncodeBigUintCompact method.
+func (pd Decoder) DecodeBigUintCompact() (*big.Int, error) {
+	b, err := pd.ReadOneByte()
+	if err != nil {
+		return nil, err
+	}
+
+	// everything but the big integer mode with more than 8 bytes fits into an uint64
+	if b&3 != 3 || b>>2 <= 4 {
+		v, err := Decoder{io.MultiReader(bytes.NewReader([]byte{b}), pd.reader)}.DecodeUintCompact()
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetUint64(v), nil
+	}
+
+	buf := make([]byte, b>>2+4)
+	err = pd.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// reverse the little endian bytes for big.Int
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// decodePointerOption decodes an Option<T> into the pointer target, it is set to nil for None
+func (pd Decoder) decodePointerOption(target reflect.Value) error {
+	b, err := pd.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	case 1:
+		v := reflect.New(target.Type().Elem())
+		err = pd.DecodeIntoReflectValue(v.Elem())
+		if err != nil {
+			return err
+		}
+		target.Set(v)
+		return nil
+	}
+	return fmt.Errorf("Unknown byte prefix for encoded Option: %d", b)
+}
+
 // DecodeOption decodes a optionally available value into a boolean presence field and a value.
 func (pd Decoder) DecodeOption(hasValue *bool, valuePointer interface{}) error {
-	b, _ := pd.ReadOneByte()
+	b, err := pd.ReadOneByte()
+	if err != nil {
+		return err
+	}
 	switch b {
 	case 0:
 		*hasValue = false
 	case 1:
 		*hasValue = true
-		err := pd.Decode(valuePointer)
+		err = pd.Decode(valuePointer)
 		if err != nil {
 			return err
 		}
@@ -495,6 +840,32 @@ func (pd Decoder) DecodeOption(hasValue *bool, valuePointer interface{}) error {
 	return nil
 }
 
+// DecodeResult decodes a Result<T, E> into a boolean ok field and either the ok or the error value. A nil pointer
+// is decoded as unit type (), eg: the ok value of Result<(), E>.
+func (pd Decoder) DecodeResult(isOk *bool, okPointer interface{}, errPointer interface{}) error {
+	b, err := pd.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	var valuePointer interface{}
+	switch b {
+	case 0:
+		*isOk = true
+		valuePointer = okPointer
+	case 1:
+		*isOk = false
+		valuePointer = errPointer
+	default:
+		return fmt.Errorf("Unknown byte prefix for encoded Result: %d", b)
+	}
+
+	if valuePointer == nil {
+		return nil
+	}
+	return pd.Decode(valuePointer)
+}
+
 // Encodeable is an interface that defines a custom encoding rules for a data type.
 // Should be defined for structs (not pointers to them).
 // See OptionBool for an example implementation.
@@ -565,6 +936,21 @@ func (o *OptionBool) Decode(decoder Decoder) error {
 	return nil
 }
 
+// EncodeToBytes encodes the value and returns the encoded bytes
+func EncodeToBytes(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := NewEncoder(&buffer).Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// DecodeFromBytes decodes the bytes into target, which must be a pointer
+func DecodeFromBytes(b []byte, target interface{}) error {
+	return NewDecoder(bytes.NewReader(b)).Decode(target)
+}
+
 // ToKeyedVec replicates the behaviour of Rust's to_keyed_vec helper.
 func ToKeyedVec(value interface{}, prependKey []byte) ([]byte, error) {
 	var buffer = bytes.NewBuffer(prependKey)
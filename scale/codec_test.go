@@ -17,6 +17,7 @@
 package scale
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"math"
@@ -65,6 +66,42 @@ func TestSliceOfBytesEncodedAsExpected(t *testing.T) {
 	assertEqual(t, hexify(encodeToBytes(t, value)), "28 00 01 01 02 03 05 08 0d 15 22")
 }
 
+func TestSliceOfSliceOfBytesEncodedAsExpected(t *testing.T) {
+	for _, test := range []struct {
+		value   [][]byte
+		encoded string
+	}{
+		{[][]byte{}, "00"},
+		{[][]byte{{}}, "04 00"},
+		{[][]byte{{1}, {}, {2, 3, 4}}, "0c 04 01 00 0c 02 03 04"},
+	} {
+		assertEqual(t, hexify(encodeToBytes(t, test.value)), test.encoded)
+
+		// empty Vecs are decoded as nil
+		var decoded [][]byte
+		err := DecodeFromBytes(encodeToBytes(t, test.value), &decoded)
+		assert.NoError(t, err)
+		assert.Len(t, decoded, len(test.value))
+		for i := range test.value {
+			assert.Equal(t, len(test.value[i]), len(decoded[i]))
+			if len(test.value[i]) > 0 {
+				assert.Equal(t, test.value[i], decoded[i])
+			}
+		}
+	}
+
+	// as a struct field
+	type proofs struct {
+		Proofs [][]byte
+	}
+	value := proofs{Proofs: [][]byte{{1, 2}, {3}}}
+	assertRoundtrip(t, value)
+	assertEqual(t, hexify(encodeToBytes(t, value)), "08 08 01 02 04 03")
+
+	err := DecodeFromBytes([]byte{0x08, 0x08, 0x01, 0x02, 0x04}, &value)
+	assert.Error(t, err)
+}
+
 func TestArrayOfBytesEncodedAsExpected(t *testing.T) {
 	value := [10]byte{0, 1, 1, 2, 3, 5, 8, 13, 21, 34}
 	assertRoundtrip(t, value)
@@ -120,12 +157,83 @@ func TestSliceOfOptionInt8EncodedAsExpected(t *testing.T) {
 	assertEqual(t, hexify(encodeToBytes(t, value)), "0c 01 01 01 ff 00")
 }
 
+// ResultUint16String is an example implementation of a "Result" type, mirroring Result<u16, String> in Rust version.
+type ResultUint16String struct {
+	isOk bool
+	ok   uint16
+	err  string
+}
+
+func (r ResultUint16String) Encode(encoder Encoder) error {
+	return encoder.EncodeResult(r.isOk, r.ok, r.err)
+}
+
+func (r *ResultUint16String) Decode(decoder Decoder) error {
+	return decoder.DecodeResult(&r.isOk, &r.ok, &r.err)
+}
+
+func TestSliceOfResultEncodedAsExpected(t *testing.T) {
+	value := []ResultUint16String{{isOk: true, ok: 258}, {err: "ab"}}
+	assertRoundtrip(t, value)
+	assertEqual(t, hexify(encodeToBytes(t, value)), "08 00 02 01 01 08 61 62")
+}
+
+// resultUnitUint8 mirrors Result<(), u8> in Rust version
+type resultUnitUint8 struct {
+	isOk bool
+	err  uint8
+}
+
+func (r resultUnitUint8) Encode(encoder Encoder) error {
+	return encoder.EncodeResult(r.isOk, nil, r.err)
+}
+
+func (r *resultUnitUint8) Decode(decoder Decoder) error {
+	return decoder.DecodeResult(&r.isOk, nil, &r.err)
+}
+
+func TestResultOfUnitEncodedAsExpected(t *testing.T) {
+	assertRoundtrip(t, resultUnitUint8{isOk: true})
+	assertRoundtrip(t, resultUnitUint8{err: 7})
+	assertEqual(t, hexify(encodeToBytes(t, resultUnitUint8{isOk: true})), "00")
+	assertEqual(t, hexify(encodeToBytes(t, resultUnitUint8{err: 7})), "01 07")
+
+	var r resultUnitUint8
+	err := Decoder{bytes.NewReader([]byte{2})}.Decode(&r)
+	assert.EqualError(t, err, "Unknown byte prefix for encoded Result: 2")
+}
+
 func TestSliceOfOptionBoolEncodedAsExpected(t *testing.T) {
 	value := []OptionBool{NewOptionBool(true), NewOptionBool(false), NewOptionBoolEmpty()}
 	assertRoundtrip(t, value)
 	assertEqual(t, hexify(encodeToBytes(t, value)), "0c 01 02 00")
 }
 
+// fixedBytes4 is an array type with its own encoding, it is encoded without a length prefix
+type fixedBytes4 [4]byte
+
+func (f fixedBytes4) Encode(encoder Encoder) error {
+	return encoder.Write(f[:])
+}
+
+func (f *fixedBytes4) Decode(decoder Decoder) error {
+	return decoder.Read(f[:])
+}
+
+func TestSliceOfEncodeableArrayEncodedAsExpected(t *testing.T) {
+	value := []fixedBytes4{{1, 2, 3, 4}, {5, 6, 7, 8}}
+	assertRoundtrip(t, value)
+	assertEqual(t, hexify(encodeToBytes(t, value)), "08 01 02 03 04 05 06 07 08")
+}
+
+func TestNilPointerCannotBeEncoded(t *testing.T) {
+	_, err := EncodeToBytes((*fixedBytes4)(nil))
+	assert.EqualError(t, err, "Encoding null pointers not supported; consider using Option type")
+
+	_, err = EncodeToBytes([]*fixedBytes4{{1, 2, 3, 4}, nil})
+	assert.EqualError(t, err, "Encoding null pointers not supported; consider using Option type")
+}
+
 func TestSliceOfStringEncodedAsExpected(t *testing.T) {
 	value := []string{
 		"Hamlet",
@@ -164,3 +272,186 @@ func TestCompactIntegersEncodedAsExpected(t *testing.T) {
 		assertEqual(t, decoded, value)
 	}
 }
+
+type testTuple struct {
+	Amount uint64
+	Flag   OptionBool
+	Inner  testInnerTuple
+	Names  []string
+}
+
+type testInnerTuple struct {
+	A int8
+	B []byte
+}
+
+func TestStructEncodedAsTuple(t *testing.T) {
+	value := testTuple{
+		Amount: 1,
+		Flag:   NewOptionBool(true),
+		Inner:  testInnerTuple{A: -1, B: []byte{0xab}},
+		Names:  []string{"a"},
+	}
+	assertRoundtrip(t, value)
+	assertEqual(t, hexify(encodeToBytes(t, value)), "01 00 00 00 00 00 00 00 01 ff 04 ab 04 04 61")
+
+	b, err := EncodeToBytes(value)
+	assert.NoError(t, err)
+	var decoded testTuple
+	err = DecodeFromBytes(b, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	err = DecodeFromBytes(b[:5], &decoded)
+	assert.Error(t, err)
+}
+
+func TestStructWithUnexportedFieldCannotBeEncoded(t *testing.T) {
+	value := struct {
+		A uint8
+		b uint8
+	}{1, 2}
+	_, err := EncodeToBytes(value)
+	assert.EqualError(t, err, "Type struct { A uint8; b uint8 } has the unexported field b and must implement Encodeable")
+
+	err = DecodeFromBytes([]byte{1, 2}, &value)
+	assert.EqualError(t, err, "Type struct { A uint8; b uint8 } has the unexported field b and must implement Decodeable")
+}
+
+func TestDecoderRemaining(t *testing.T) {
+	dec := NewDecoder(bytes.NewReader([]byte{0x01, 0x02, 0x03}))
+	n, ok := dec.Remaining()
+	assert.True(t, ok)
+	assert.Equal(t, 3, n)
+
+	_, err := dec.ReadOneByte()
+	assert.NoError(t, err)
+	n, _ = dec.Remaining()
+	assert.Equal(t, 2, n)
+
+	rest, err := dec.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x02, 0x03}, rest)
+	n, _ = dec.Remaining()
+	assert.Equal(t, 0, n)
+
+	_, ok = NewDecoder(bufio.NewReader(bytes.NewReader(nil))).Remaining()
+	assert.False(t, ok)
+}
+
+func TestMapEncodedAsSortedVec(t *testing.T) {
+	// BTreeMap<[u8; 2], u32>, sorted by the keys
+	value := map[[2]byte]uint32{{0x02, 0x00}: 2, {0x01, 0xff}: 1}
+	b, err := EncodeToBytes(value)
+	assert.NoError(t, err)
+	// arrays have a length prefix in this codec
+	assertEqual(t, hexify(b), "08 08 01 ff 01 00 00 00 08 02 00 02 00 00 00")
+
+	var decoded map[[2]byte]uint32
+	err = DecodeFromBytes(b, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	// keys are ordered by their value like in Rust, not by their little endian encoding
+	b, err = EncodeToBytes(map[uint16]bool{0x0100: true, 0x0002: false})
+	assert.NoError(t, err)
+	assertEqual(t, hexify(b), "08 02 00 00 00 01 01")
+
+	// BTreeMap<u32, u8> from [(1, 0xaa), (256, 0xbb)] encodes to 0x0801000000aa00010000bb in Rust
+	b, err = EncodeToBytes(map[uint32]uint8{256: 0xbb, 1: 0xaa})
+	assert.NoError(t, err)
+	assertEqual(t, hexify(b), "08 01 00 00 00 aa 00 01 00 00 bb")
+
+	b, err = EncodeToBytes(map[int8]uint8{1: 0xaa, -1: 0xbb})
+	assert.NoError(t, err)
+	assertEqual(t, hexify(b), "08 ff bb 01 aa")
+
+	// strings are ordered lexicographically, not by their length prefix
+	b, err = EncodeToBytes(map[string]bool{"b": true, "aa": false})
+	assert.NoError(t, err)
+	assertEqual(t, hexify(b), "08 08 61 61 00 04 62 01")
+
+	b, err = EncodeToBytes(map[string]uint8{})
+	assert.NoError(t, err)
+	assertEqual(t, hexify(b), "00")
+
+	err = DecodeFromBytes([]byte{0x04, 0x01}, &decoded)
+	assert.Error(t, err)
+}
+
+func TestDecodeTruncatedInput(t *testing.T) {
+	type item struct {
+		Values  []uint16
+		Data    []byte
+		Name    string
+		Entries map[uint8][]byte
+		Opt     *uint32
+	}
+	opt := uint32(7)
+	value := item{
+		Values:  []uint16{1, 2, 300},
+		Data:    bytes.Repeat([]byte{0xab}, 70),
+		Name:    "truncated",
+		Entries: map[uint8][]byte{1: {0x01}, 2: {0x02, 0x03}},
+		Opt:     &opt,
+	}
+	b, err := EncodeToBytes(value)
+	assert.NoError(t, err)
+
+	var decoded item
+	err = DecodeFromBytes(b, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	// every prefix of the encoding is missing bytes the decoder needs
+	for i := 0; i < len(b); i++ {
+		var decoded item
+		assert.Error(t, DecodeFromBytes(b[:i], &decoded), fmt.Sprintf("prefix of %v bytes", i))
+	}
+
+	// compact integers of every mode
+	for _, v := range []uint64{1, 1 << 6, 1 << 14, 1 << 30, 1 << 40} {
+		var buf bytes.Buffer
+		err := NewEncoder(&buf).EncodeUintCompact(v)
+		assert.NoError(t, err)
+		b := buf.Bytes()
+		for i := 0; i < len(b); i++ {
+			_, err := NewDecoder(bytes.NewReader(b[:i])).DecodeUintCompact()
+			assert.Error(t, err, fmt.Sprintf("prefix of %v bytes of %v", i, v))
+		}
+	}
+}
+
+func TestDecodeLengthExceedingInput(t *testing.T) {
+	// lengths of 2^30 - 1 and 2^32 - 1 items followed by a few bytes only, nothing is allocated for them
+	for _, test := range []struct {
+		input []byte
+		err   string
+	}{
+		{[]byte{0xfe, 0xff, 0xff, 0xff, 0x01, 0x02, 0x03}, "Encoded length 1073741823 exceeds the 3 remaining bytes"},
+		{[]byte{0x03, 0xff, 0xff, 0xff, 0xff, 0x01, 0x02}, "Encoded length 4294967295 exceeds the 2 remaining bytes"},
+	} {
+		var b []byte
+		assert.EqualError(t, DecodeFromBytes(test.input, &b), test.err)
+		var s string
+		assert.EqualError(t, DecodeFromBytes(test.input, &s), test.err)
+		var u []uint64
+		assert.EqualError(t, DecodeFromBytes(test.input, &u), test.err)
+
+		_, err := NewDecoder(bytes.NewReader(test.input)).DecodeLength()
+		assert.EqualError(t, err, test.err)
+	}
+
+	n, err := NewDecoder(bytes.NewReader([]byte{0x08, 0x01, 0x02})).DecodeLength()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = NewDecoder(bytes.NewReader([]byte{0x07, 0xff, 0xff, 0xff, 0xff, 0xff})).DecodeLength()
+	assert.EqualError(t, err, "Encoded length is higher than allowed by the protocol (32-bit unsigned integer)")
+
+	// items without data don't take any bytes
+	var empty []struct{}
+	err = DecodeFromBytes([]byte{0x0c}, &empty)
+	assert.NoError(t, err)
+	assert.Len(t, empty, 3)
+}
@@ -0,0 +1,122 @@
+package scale
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Enums follow the convention of a bool IsX field for each variant X, followed by an AsX field with the payload of
+// the variant if it has one. Structs whose IsX fields are tagged with `scale:"enum"` are encoded as the index of the
+// set variant followed by its payload, without their own Encode and Decode methods. The first variant has the index
+// 0 and each further variant the index after the previous one, an index can be set explicitly with
+// `scale:"enum=N"`, eg: for enums with removed variants. Other fields are not encoded. Payloads are encoded like any
+// other value, so a fixed array payload gets a length prefix, use a type with its own Encode and Decode instead.
+//
+//	type MultiAddress struct {
+//		IsID    bool `scale:"enum"`
+//		AsID    AccountID
+//		IsIndex bool `scale:"enum"`
+//		AsIndex uint32
+//	}
+
+// enumVariant is a variant of an enum, payload is the index of the AsX field or -1 if the variant has none
+type enumVariant struct {
+	name    string
+	index   byte
+	flag    int
+	payload int
+}
+
+// enumVariants returns the variants of the enum t in their order, ok is false if t is not a tagged enum
+func enumVariants(t reflect.Type) (variants []enumVariant, ok bool, err error) {
+	next := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, tagged := f.Tag.Lookup("scale")
+		if !tagged || (tag != "enum" && !strings.HasPrefix(tag, "enum=")) {
+			continue
+		}
+
+		if f.Type.Kind() != reflect.Bool || !strings.HasPrefix(f.Name, "Is") {
+			return nil, false, fmt.Errorf("enum variant %s of %s must be a bool field named IsX", f.Name, t)
+		}
+
+		if tag != "enum" {
+			next, err = strconv.Atoi(strings.TrimPrefix(tag, "enum="))
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid index of enum variant %s of %s: %v", f.Name, t, err)
+			}
+		}
+		if next < 0 || next > 255 {
+			return nil, false, fmt.Errorf("index %v of enum variant %s of %s does not fit into a byte", next, f.Name, t)
+		}
+
+		v := enumVariant{name: strings.TrimPrefix(f.Name, "Is"), index: byte(next), flag: i, payload: -1}
+		if p, ok := t.FieldByName("As" + v.name); ok && len(p.Index) == 1 {
+			v.payload = p.Index[0]
+		}
+		variants = append(variants, v)
+		next++
+	}
+
+	return variants, len(variants) > 0, nil
+}
+
+// encodeEnum encodes the index of the set variant of the enum v and its payload
+func (pe Encoder) encodeEnum(v reflect.Value, variants []enumVariant) error {
+	var set *enumVariant
+	for i := range variants {
+		if !v.Field(variants[i].flag).Bool() {
+			continue
+		}
+		if set != nil {
+			return fmt.Errorf("enum %s has the variants %s and %s set", v.Type(), set.name, variants[i].name)
+		}
+		set = &variants[i]
+	}
+	if set == nil {
+		return fmt.Errorf("enum %s has no variant set", v.Type())
+	}
+
+	err := pe.PushByte(set.index)
+	if err != nil {
+		return err
+	}
+	if set.payload < 0 {
+		return nil
+	}
+	return pe.Encode(v.Field(set.payload).Interface())
+}
+
+// decodeEnum sets the variant of the enum target with the decoded index and decodes its payload
+func (pd Decoder) decodeEnum(target reflect.Value, variants []enumVariant) error {
+	index, err := pd.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	// other fields, such as options of the enum, are kept
+	for _, v := range variants {
+		target.Field(v.flag).SetBool(false)
+		if v.payload >= 0 {
+			f := target.Field(v.payload)
+			f.Set(reflect.Zero(f.Type()))
+		}
+	}
+
+	for _, v := range variants {
+		if v.index != index {
+			continue
+		}
+
+		target.Field(v.flag).SetBool(true)
+		if v.payload < 0 {
+			return nil
+		}
+		return pd.DecodeIntoReflectValue(target.Field(v.payload))
+	}
+
+	return fmt.Errorf("unknown variant %v of enum %s", index, target.Type())
+}
@@ -0,0 +1,85 @@
+// +build tests
+
+package scale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEnum struct {
+	IsNone  bool `scale:"enum"`
+	IsIndex bool `scale:"enum"`
+	AsIndex uint32
+	// the variant with index 2 was removed
+	IsPair bool `scale:"enum=3"`
+	AsPair struct {
+		A uint8
+		B []byte
+	}
+	IsLast bool `scale:"enum"`
+
+	// Verbose is an option of the enum, it is not encoded
+	Verbose bool
+}
+
+func TestEnumEncodedAsIndexAndPayload(t *testing.T) {
+	pair := testEnum{IsPair: true}
+	pair.AsPair.A = 7
+	pair.AsPair.B = []byte{0xab}
+	for _, test := range []struct {
+		value   testEnum
+		encoded string
+	}{
+		{testEnum{IsNone: true}, "00"},
+		{testEnum{IsIndex: true, AsIndex: 42}, "01 2a 00 00 00"},
+		{pair, "03 07 04 ab"},
+		{testEnum{IsLast: true}, "04"},
+	} {
+		b, err := EncodeToBytes(test.value)
+		assert.NoError(t, err)
+		assertEqual(t, hexify(b), test.encoded)
+
+		var dec testEnum
+		err = DecodeFromBytes(b, &dec)
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, dec)
+	}
+
+	// the previous variant is reset, the options are kept
+	dec := testEnum{IsIndex: true, AsIndex: 42, Verbose: true}
+	err := DecodeFromBytes([]byte{0}, &dec)
+	assert.NoError(t, err)
+	assert.Equal(t, testEnum{IsNone: true, Verbose: true}, dec)
+
+	err = DecodeFromBytes([]byte{2}, &dec)
+	assert.EqualError(t, err, "unknown variant 2 of enum scale.testEnum")
+
+	_, err = EncodeToBytes(testEnum{})
+	assert.EqualError(t, err, "enum scale.testEnum has no variant set")
+
+	_, err = EncodeToBytes(testEnum{IsNone: true, IsLast: true})
+	assert.EqualError(t, err, "enum scale.testEnum has the variants None and Last set")
+}
+
+func TestEnumInvalidTags(t *testing.T) {
+	type notBool struct {
+		IsA uint8 `scale:"enum"`
+	}
+	_, err := EncodeToBytes(notBool{})
+	assert.EqualError(t, err, "enum variant IsA of scale.notBool must be a bool field named IsX")
+
+	type badIndex struct {
+		IsA bool `scale:"enum=x"`
+	}
+	_, err = EncodeToBytes(badIndex{IsA: true})
+	assert.Error(t, err)
+
+	type tooLarge struct {
+		IsA bool `scale:"enum=256"`
+	}
+	var dec tooLarge
+	err = DecodeFromBytes([]byte{0}, &dec)
+	assert.EqualError(t, err, "index 256 of enum variant IsA of scale.tooLarge does not fit into a byte")
+}
@@ -0,0 +1,170 @@
+package scale
+
+import "fmt"
+
+// Since Go does not support generics, Option<T> types for commonly used T are defined below.
+// All of them follow the Rust encoding of Option: a leading 0x00 for None, 0x01 followed by the value for Some.
+
+// OptionUint32 is a structure that can store a uint32 or a missing value, mirroring Option<u32>.
+type OptionUint32 struct {
+	hasValue bool
+	value    uint32
+}
+
+// NewOptionUint32Empty creates an OptionUint32 without a value.
+func NewOptionUint32Empty() OptionUint32 {
+	return OptionUint32{false, 0}
+}
+
+// NewOptionUint32 creates an OptionUint32 with a value.
+func NewOptionUint32(value uint32) OptionUint32 {
+	return OptionUint32{true, value}
+}
+
+// IsNone returns true if the option doesn't hold a value.
+func (o OptionUint32) IsNone() bool {
+	return !o.hasValue
+}
+
+// Unwrap returns a flag that indicates whether a value is present and the stored value
+func (o OptionUint32) Unwrap() (ok bool, value uint32) {
+	return o.hasValue, o.value
+}
+
+func (o OptionUint32) Encode(encoder Encoder) error {
+	return encoder.EncodeOption(o.hasValue, o.value)
+}
+
+func (o *OptionUint32) Decode(decoder Decoder) error {
+	return decoder.DecodeOption(&o.hasValue, &o.value)
+}
+
+// OptionUint64 is a structure that can store a uint64 or a missing value, mirroring Option<u64>.
+type OptionUint64 struct {
+	hasValue bool
+	value    uint64
+}
+
+// NewOptionUint64Empty creates an OptionUint64 without a value.
+func NewOptionUint64Empty() OptionUint64 {
+	return OptionUint64{false, 0}
+}
+
+// NewOptionUint64 creates an OptionUint64 with a value.
+func NewOptionUint64(value uint64) OptionUint64 {
+	return OptionUint64{true, value}
+}
+
+// IsNone returns true if the option doesn't hold a value.
+func (o OptionUint64) IsNone() bool {
+	return !o.hasValue
+}
+
+// Unwrap returns a flag that indicates whether a value is present and the stored value
+func (o OptionUint64) Unwrap() (ok bool, value uint64) {
+	return o.hasValue, o.value
+}
+
+func (o OptionUint64) Encode(encoder Encoder) error {
+	return encoder.EncodeOption(o.hasValue, o.value)
+}
+
+func (o *OptionUint64) Decode(decoder Decoder) error {
+	return decoder.DecodeOption(&o.hasValue, &o.value)
+}
+
+// OptionBytes is a structure that can store a byte slice or a missing value, mirroring Option<Vec<u8>>.
+// The value is length prefixed.
+type OptionBytes struct {
+	hasValue bool
+	value    []byte
+}
+
+// NewOptionBytesEmpty creates an OptionBytes without a value.
+func NewOptionBytesEmpty() OptionBytes {
+	return OptionBytes{false, nil}
+}
+
+// NewOptionBytes creates an OptionBytes with a value.
+func NewOptionBytes(value []byte) OptionBytes {
+	return OptionBytes{true, value}
+}
+
+// IsNone returns true if the option doesn't hold a value.
+func (o OptionBytes) IsNone() bool {
+	return !o.hasValue
+}
+
+// Unwrap returns a flag that indicates whether a value is present and the stored value
+func (o OptionBytes) Unwrap() (ok bool, value []byte) {
+	return o.hasValue, o.value
+}
+
+func (o OptionBytes) Encode(encoder Encoder) error {
+	return encoder.EncodeOption(o.hasValue, o.value)
+}
+
+func (o *OptionBytes) Decode(decoder Decoder) error {
+	return decoder.DecodeOption(&o.hasValue, &o.value)
+}
+
+// OptionBytes32 is a structure that can store a fixed 32 byte value or a missing value, mirroring Option<H256>.
+// Unlike OptionBytes, the value is not length prefixed.
+type OptionBytes32 struct {
+	hasValue bool
+	value    [32]byte
+}
+
+// NewOptionBytes32Empty creates an OptionBytes32 without a value.
+func NewOptionBytes32Empty() OptionBytes32 {
+	return OptionBytes32{false, [32]byte{}}
+}
+
+// NewOptionBytes32 creates an OptionBytes32 with a value.
+func NewOptionBytes32(value [32]byte) OptionBytes32 {
+	return OptionBytes32{true, value}
+}
+
+// IsNone returns true if the option doesn't hold a value.
+func (o OptionBytes32) IsNone() bool {
+	return !o.hasValue
+}
+
+// Unwrap returns a flag that indicates whether a value is present and the stored value
+func (o OptionBytes32) Unwrap() (ok bool, value [32]byte) {
+	return o.hasValue, o.value
+}
+
+func (o OptionBytes32) Encode(encoder Encoder) error {
+	if !o.hasValue {
+		return encoder.PushByte(0)
+	}
+
+	err := encoder.PushByte(1)
+	if err != nil {
+		return err
+	}
+	return encoder.Write(o.value[:])
+}
+
+func (o *OptionBytes32) Decode(decoder Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		o.hasValue = false
+		o.value = [32]byte{}
+	case 1:
+		o.hasValue = true
+		err = decoder.Read(o.value[:])
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Unknown byte prefix for encoded OptionBytes32: %d", b)
+	}
+	return nil
+}
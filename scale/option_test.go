@@ -0,0 +1,88 @@
+// +build tests
+
+package scale
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionUint32EncodedAsExpected(t *testing.T) {
+	assertRoundtrip(t, NewOptionUint32(42))
+	assertRoundtrip(t, NewOptionUint32Empty())
+	assertEqual(t, hexify(encodeToBytes(t, NewOptionUint32(42))), "01 2a 00 00 00")
+	assertEqual(t, hexify(encodeToBytes(t, NewOptionUint32Empty())), "00")
+}
+
+func TestOptionUint32Decode(t *testing.T) {
+	var o OptionUint32
+	err := NewDecoder(bytes.NewReader([]byte{0})).Decode(&o)
+	assert.NoError(t, err)
+	assert.True(t, o.IsNone())
+
+	err = NewDecoder(bytes.NewReader([]byte{1, 7, 0, 0, 0})).Decode(&o)
+	assert.NoError(t, err)
+	ok, v := o.Unwrap()
+	assert.True(t, ok)
+	assert.Equal(t, uint32(7), v)
+
+	err = NewDecoder(bytes.NewReader([]byte{2})).Decode(&o)
+	assert.Error(t, err)
+}
+
+func TestOptionUint64EncodedAsExpected(t *testing.T) {
+	assertRoundtrip(t, NewOptionUint64(1<<40))
+	assertRoundtrip(t, NewOptionUint64Empty())
+	assertEqual(t, hexify(encodeToBytes(t, NewOptionUint64(1))), "01 01 00 00 00 00 00 00 00")
+}
+
+func TestOptionBytesEncodedAsExpected(t *testing.T) {
+	assertRoundtrip(t, NewOptionBytes([]byte{1, 2, 3}))
+	assertRoundtrip(t, NewOptionBytesEmpty())
+	assertEqual(t, hexify(encodeToBytes(t, NewOptionBytes([]byte{1, 2, 3}))), "01 0c 01 02 03")
+}
+
+func TestOptionBytes32EncodedAsExpected(t *testing.T) {
+	var h [32]byte
+	h[0] = 0xff
+	assertRoundtrip(t, NewOptionBytes32(h))
+	assertRoundtrip(t, NewOptionBytes32Empty())
+	assertEqual(t, len(encodeToBytes(t, NewOptionBytes32(h))), 33)
+	assertEqual(t, hexify(encodeToBytes(t, NewOptionBytes32Empty())), "00")
+}
+
+func TestPointerFieldEncodedAsOption(t *testing.T) {
+	type args struct {
+		A uint8
+		B *uint32
+	}
+
+	v := uint32(7)
+	for _, test := range []struct {
+		value   args
+		encoded string
+	}{
+		{args{A: 1, B: &v}, "01 01 07 00 00 00"},
+		{args{A: 1}, "01 00"},
+	} {
+		b, err := EncodeToBytes(test.value)
+		assert.NoError(t, err)
+		assertEqual(t, hexify(b), test.encoded)
+
+		var dec args
+		err = DecodeFromBytes(b, &dec)
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, dec)
+	}
+
+	// a set pointer is reset by None
+	dec := args{B: &v}
+	err := DecodeFromBytes([]byte{1, 0}, &dec)
+	assert.NoError(t, err)
+	assert.Nil(t, dec.B)
+
+	err = DecodeFromBytes([]byte{1, 2}, &dec)
+	assert.Error(t, err)
+}
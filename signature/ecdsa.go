@@ -0,0 +1,98 @@
+package signature
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/minio/blake2b-simd"
+)
+
+// ecdsaPair is a secp256k1 pair, its public key is the 33 byte compressed key and its account id is the blake2b-256
+// of the public key
+type ecdsaPair struct {
+	publicKey  []byte
+	privateKey *ecdsa.PrivateKey
+	network    uint8
+	meta       map[string]interface{}
+}
+
+func newEcdsaPair(privateKey []byte, network uint8) (*ecdsaPair, error) {
+	priv, err := crypto.ToECDSA(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdsaPair{
+		publicKey:  crypto.CompressPubkey(&priv.PublicKey),
+		privateKey: priv,
+		network:    network,
+		meta:       make(map[string]interface{}),
+	}, nil
+}
+
+func (p *ecdsaPair) Type() SupportedKeyType {
+	return ECDSA
+}
+
+func (p *ecdsaPair) Address() string {
+	accountID := blake2b.Sum256(p.publicKey)
+	return ss58.Encode(accountID[:], p.network)
+}
+
+func (p *ecdsaPair) Meta() map[string]interface{} {
+	return p.meta
+}
+
+func (p *ecdsaPair) SetMeta(meta map[string]interface{}) {
+	p.meta = meta
+}
+
+func (p *ecdsaPair) IsLocked() bool {
+	return p.privateKey == nil
+}
+
+// Lock removes the private key from memory, the pair can only be used for verification afterwards
+func (p *ecdsaPair) Lock() {
+	if p.privateKey == nil {
+		return
+	}
+	p.privateKey.D.SetInt64(0)
+	p.privateKey = nil
+}
+
+func (p *ecdsaPair) PublicKey() []byte {
+	return p.publicKey
+}
+
+// Sign signs the blake2b-256 hash of the message, the signature is r, s and the recovery id
+func (p *ecdsaPair) Sign(message []byte) (MultiSignature, error) {
+	if p.IsLocked() {
+		return MultiSignature{}, errors.New("cannot sign with a locked pair")
+	}
+
+	hash := blake2b.Sum256(message)
+	sig, err := crypto.Sign(hash[:], p.privateKey)
+	if err != nil {
+		return MultiSignature{}, err
+	}
+
+	s := MultiSignature{IsEcdsa: true}
+	copy(s.AsEcdsa[:], sig)
+	return s, nil
+}
+
+func (p *ecdsaPair) Verify(message []byte, signature MultiSignature) bool {
+	return Verify(p.publicKey, message, signature)
+}
+
+// verifyEcdsa verifies the signature of the blake2b-256 hash of message against the compressed public key
+func verifyEcdsa(publicKey []byte, message []byte, signature [65]byte) bool {
+	if len(publicKey) != 33 {
+		return false
+	}
+
+	hash := blake2b.Sum256(message)
+	return crypto.VerifySignature(publicKey, hash[:], signature[:64])
+}
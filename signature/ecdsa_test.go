@@ -0,0 +1,63 @@
+// +build tests
+
+package signature
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyringPairFromSeed_ECDSA(t *testing.T) {
+	// the private key 1, its public key is the generator point
+	priv := make([]byte, 32)
+	priv[31] = 1
+	p, err := NewKeyringPairFromSeed(priv, ECDSA, ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, ECDSA, p.Type())
+	assert.Equal(t, "0x0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", hexutil.Encode(p.PublicKey()))
+	accountID := hexutil.MustDecode("0x2975f1d28b92b6e84499b83b0797ef5235553eeb7edaa0cea243c1128c2fe737")
+	assert.Equal(t, ss58.Encode(accountID, ss58.SubstratePrefix), p.Address())
+
+	msg := []byte("anchor")
+	sig, err := p.Sign(msg)
+	assert.NoError(t, err)
+	assert.True(t, sig.IsEcdsa)
+	assert.True(t, sig.AsEcdsa[64] <= 1)
+	assert.True(t, p.Verify(msg, sig))
+	assert.True(t, Verify(p.PublicKey(), msg, sig))
+	assert.False(t, Verify(p.PublicKey(), []byte("other"), sig))
+
+	p.Lock()
+	_, err = p.Sign(msg)
+	assert.Error(t, err)
+	assert.True(t, p.Verify(msg, sig))
+
+	// locking a locked pair is a no-op
+	assert.NotPanics(t, p.Lock)
+	assert.True(t, p.IsLocked())
+
+	_, err = NewKeyringPairFromSeed(make([]byte, 32), ECDSA, ss58.SubstratePrefix)
+	assert.Error(t, err)
+}
+
+func TestMultiSignature_EncodeDecode_ECDSA(t *testing.T) {
+	s := MultiSignature{IsEcdsa: true}
+	for i := range s.AsEcdsa {
+		s.AsEcdsa[i] = byte(i)
+	}
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(s)
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte{2}, s.AsEcdsa[:]...), buf.Bytes())
+
+	var dec MultiSignature
+	err = scale.NewDecoder(&buf).Decode(&dec)
+	assert.NoError(t, err)
+	assert.Equal(t, s, dec)
+}
@@ -2,19 +2,44 @@ package signature
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/minio/blake2b-simd"
 	"golang.org/x/crypto/ed25519"
 )
 
 const DEV_PHRASE = "bottom drive obey lake curtain smoke basket hold race lonely fit walk"
 
+// SupportedKeyType is the crypto scheme of a keyring pair, the zero value stands for the default SR25519
 type SupportedKeyType int
 
 const (
 	ED25519 SupportedKeyType = iota + 1
+	// ECDSA is secp256k1 as used by EVM compatible chains, messages are hashed with blake2b-256 before signing
+	ECDSA
+	// SR25519 is schnorrkel on ristretto25519, the default scheme of substrate accounts, see KeyringPairFromMnemonic
+	SR25519
 )
 
+func (t SupportedKeyType) String() string {
+	switch t {
+	case ED25519:
+		return "ed25519"
+	case ECDSA:
+		return "ecdsa"
+	case SR25519:
+		return "sr25519"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
 type Keyring struct {
 	Type  SupportedKeyType
 	Pairs map[string]KeyringPair
@@ -24,66 +49,264 @@ func (kr *Keyring) AddFromURI(SURI string, meta map[string]interface{}, tp Suppo
 
 }
 
+// AddPair adds the pair to the keyring, keyed by its address
+func (kr *Keyring) AddPair(pair KeyringPair) {
+	if kr.Pairs == nil {
+		kr.Pairs = make(map[string]KeyringPair)
+	}
+	kr.Pairs[pair.Address()] = pair
+}
+
+// KeyringPair is a key pair of one of the supported crypto schemes, see NewKeyringPairFromSeed.
+//
+// Signatures carry their scheme since ed25519 and ecdsa pairs were added: Sign returns a MultiSignature, with the raw
+// signature in the As* field of the scheme, and an error if the pair is locked. Verify takes a MultiSignature. The
+// unimplemented DecodePkcs8, EncodePkcs8 and Json methods were removed.
 type KeyringPair interface {
+	// Type returns the crypto scheme of the pair
 	Type() SupportedKeyType
 	Address() string
-	DecodePkcs8(passphrase string, encoded []byte)
-	EncodePkcs8(passphrase string) []byte
 	Meta() map[string]interface{}
 	IsLocked() bool
 	Lock()
 	PublicKey() []byte
 	SetMeta(meta map[string]interface{})
-	Sign(message []byte) []byte
-	// TODO
-	Json(passphrase string)
-	Verify(message []byte, signature []byte) bool
+	Sign(message []byte) (MultiSignature, error)
+	Verify(message []byte, signature MultiSignature) bool
+}
+
+// NewKeyringPairFromSeed creates a keyring pair of the given crypto scheme from a 32 byte seed, for ECDSA the seed
+// is the raw secp256k1 private key, for SR25519 it is the mini secret key that subkey prints as secret seed. network
+// is the SS58 prefix used for the address of the pair, eg: ss58.SubstratePrefix. A zero tp creates an SR25519 pair.
+func NewKeyringPairFromSeed(seed []byte, tp SupportedKeyType, network uint8) (KeyringPair, error) {
+	if tp == 0 {
+		tp = SR25519
+	}
+
+	switch tp {
+	case ED25519:
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("expected a seed of %d bytes, got %d", ed25519.SeedSize, len(seed))
+		}
+		priv := ed25519.NewKeyFromSeed(seed)
+		return &ed25519Pair{
+			publicKey:  priv.Public().(ed25519.PublicKey),
+			privateKey: priv,
+			network:    network,
+			meta:       make(map[string]interface{}),
+		}, nil
+	case ECDSA:
+		return newEcdsaPair(seed, network)
+	case SR25519:
+		if len(seed) != 32 {
+			return nil, fmt.Errorf("expected a seed of 32 bytes, got %d", len(seed))
+		}
+		var b [32]byte
+		copy(b[:], seed)
+		msk, err := schnorrkel.NewMiniSecretKeyFromRaw(b)
+		if err != nil {
+			return nil, err
+		}
+		return newSr25519Pair(msk, network), nil
+	default:
+		return nil, fmt.Errorf("key type %s not supported", tp)
+	}
+}
+
+type ed25519Pair struct {
+	publicKey  ed25519.PublicKey
+	privateKey ed25519.PrivateKey
+	network    uint8
+	meta       map[string]interface{}
+}
+
+func (p *ed25519Pair) Type() SupportedKeyType {
+	return ED25519
+}
+
+func (p *ed25519Pair) Address() string {
+	return ss58.Encode(p.publicKey, p.network)
+}
+
+func (p *ed25519Pair) Meta() map[string]interface{} {
+	return p.meta
+}
+
+func (p *ed25519Pair) SetMeta(meta map[string]interface{}) {
+	p.meta = meta
+}
+
+func (p *ed25519Pair) IsLocked() bool {
+	return p.privateKey == nil
+}
+
+// Lock removes the private key from memory, the pair can only be used for verification afterwards
+func (p *ed25519Pair) Lock() {
+	for i := range p.privateKey {
+		p.privateKey[i] = 0
+	}
+	p.privateKey = nil
+}
+
+func (p *ed25519Pair) PublicKey() []byte {
+	return p.publicKey
 }
 
-func sign(privKey []byte, msg []byte) {
-	ed25519.Sign(privKey, msg)
+func (p *ed25519Pair) Sign(message []byte) (MultiSignature, error) {
+	if p.IsLocked() {
+		return MultiSignature{}, errors.New("cannot sign with a locked pair")
+	}
+
+	s := MultiSignature{IsEd25519: true}
+	copy(s.AsEd25519[:], ed25519.Sign(p.privateKey, message))
+	return s, nil
+}
+
+func (p *ed25519Pair) Verify(message []byte, signature MultiSignature) bool {
+	return Verify(p.publicKey, message, signature)
 }
 
-var reCapture = regexp.MustCompile("^(\\w+( \\w+)*)((//?[^/]+)*)(///(.*))?$")
-var reJunction = regexp.MustCompile("//(/?)([^/]+)/g")
+// Verify verifies the signature of the message against the public key, using the crypto scheme of the signature
+func Verify(publicKey []byte, message []byte, signature MultiSignature) bool {
+	switch {
+	case signature.IsEd25519:
+		if len(publicKey) != ed25519.PublicKeySize {
+			return false
+		}
+		return ed25519.Verify(publicKey, message, signature.AsEd25519[:])
+	case signature.IsSr25519:
+		return verifySr25519(publicKey, message, signature.AsSr25519)
+	case signature.IsEcdsa:
+		return verifyEcdsa(publicKey, message, signature.AsEcdsa)
+	default:
+		return false
+	}
+}
+
+// MultiSignature is a signature of one of the supported crypto schemes, see MultiSignature in sr_primitives.
+// Only one of the Is* fields must be set.
+type MultiSignature struct {
+	IsEd25519 bool
+	AsEd25519 [64]byte
+	IsSr25519 bool
+	AsSr25519 [64]byte
+	IsEcdsa   bool
+	AsEcdsa   [65]byte
+}
+
+func (m *MultiSignature) Decode(decoder scale.Decoder) error {
+	b, err := decoder.ReadOneByte()
+	if err != nil {
+		return err
+	}
+
+	switch b {
+	case 0:
+		m.IsEd25519 = true
+		err = decoder.Read(m.AsEd25519[:])
+	case 1:
+		m.IsSr25519 = true
+		err = decoder.Read(m.AsSr25519[:])
+	case 2:
+		m.IsEcdsa = true
+		err = decoder.Read(m.AsEcdsa[:])
+	default:
+		return fmt.Errorf("unknown signature type %d", b)
+	}
+
+	return err
+}
+
+func (m MultiSignature) Encode(encoder scale.Encoder) error {
+	var err error
+	switch {
+	case m.IsEd25519:
+		err = encoder.PushByte(0)
+		if err != nil {
+			return err
+		}
+		err = encoder.Write(m.AsEd25519[:])
+	case m.IsSr25519:
+		err = encoder.PushByte(1)
+		if err != nil {
+			return err
+		}
+		err = encoder.Write(m.AsSr25519[:])
+	case m.IsEcdsa:
+		err = encoder.PushByte(2)
+		if err != nil {
+			return err
+		}
+		err = encoder.Write(m.AsEcdsa[:])
+	default:
+		return errors.New("signature type not set")
+	}
+
+	return err
+}
+
+// reCapture splits a SURI into the secret, the derivation path and the password
+var reCapture = regexp.MustCompile("^([\\w ]+)?((//?[^/]+)*)(///(.*))?$")
+var reJunction = regexp.MustCompile("/(/?)([^/]+)")
 
 // extractKey Extracts the phrase, path and password from a SURI format for specifying secret keys
 // `<secret>/<soft-key>//<hard-key>///<password>` (the `///password` may be omitted, and
-// `/<soft-key>` and `//<hard-key>` maybe repeated and mixed). The secret can be a hex string,
-// mnemonic phrase or a string (to be padded)
+// `/<soft-key>` and `//<hard-key>` maybe repeated and mixed). The secret can be a hex string or
+// mnemonic phrase, it is empty for URIs like `//Alice` that are derived from DEV_PHRASE
 func extractKey(suri string) (password, phrase string, path []DeriveJunction, err error) {
 	matches := reCapture.FindStringSubmatch(suri)
 	if len(matches) < 6 {
 		return "", "", nil, errors.New("suri is not correct")
 	}
 
-	// TODO
-	//path, err = extractKeyPath(matches[3])
-	//if err != nil {
-	//	return "", "", nil, err
-	//}
-	//fmt.Println(matches[6], matches[3], matches[1])
-	// TODO process the path
-	return matches[6], matches[1], path, nil
+	path, err = extractKeyPath(matches[2])
+	if err != nil {
+		return "", "", nil, err
+	}
+	return matches[5], strings.TrimSpace(matches[1]), path, nil
 }
 
+// DeriveJunction is a soft (`/x`) or hard (`//x`) step of a derivation path, identified by its chain code
 type DeriveJunction struct {
-	chainCode []byte
+	chainCode [32]byte
 	isHard    bool
 }
 
-//func extractKeyPath(s string) ([]DeriveJunction, error) {
-//	parts := reJunction.FindStringSubmatch(s)
-//	path := make([]DeriveJunction, 1)
-//	constructed := ""
-//
-//	if len(parts) > 0 {
-//		constructed = strings.Join(parts, "")
-//		for _, p := range parts {
-//			append(path, )
-//		}
-//	}
-//}
+// newDeriveJunction creates the junction like substrate does: numbers are SCALE encoded as u64 and other ids as
+// strings, ids longer than the chain code are hashed with blake2b-256
+func newDeriveJunction(id string, isHard bool) (DeriveJunction, error) {
+	var b []byte
+	var err error
+	if n, perr := strconv.ParseUint(id, 10, 64); perr == nil {
+		b, err = scale.EncodeToBytes(n)
+	} else {
+		b, err = scale.EncodeToBytes(id)
+	}
+	if err != nil {
+		return DeriveJunction{}, err
+	}
+
+	j := DeriveJunction{isHard: isHard}
+	if len(b) > len(j.chainCode) {
+		h := blake2b.Sum256(b)
+		b = h[:]
+	}
+	copy(j.chainCode[:], b)
+	return j, nil
+}
+
+// extractKeyPath parses a derivation path like `//Alice//stash/0` into its junctions
+func extractKeyPath(s string) ([]DeriveJunction, error) {
+	var path []DeriveJunction
+	for _, parts := range reJunction.FindAllStringSubmatch(s, -1) {
+		j, err := newDeriveJunction(parts[2], parts[1] == "/")
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, j)
+	}
+	return path, nil
+}
 
 /**
   /**
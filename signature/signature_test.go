@@ -2,10 +2,129 @@
 
 package signature
 
-import "testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/scale"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/minio/blake2b-simd"
+	"github.com/stretchr/testify/assert"
+)
 
 func TestExtractKey(t *testing.T) {
-	extractKey("hello world//1/DOT///password")
+	password, phrase, path, err := extractKey("hello world//1/DOT///password")
+	assert.NoError(t, err)
+	assert.Equal(t, "password", password)
+	assert.Equal(t, "hello world", phrase)
+	assert.Len(t, path, 2)
+	// numbers are u64, other ids SCALE encoded strings, padded to the chain code length
+	assert.Equal(t, DeriveJunction{chainCode: [32]byte{0x01}, isHard: true}, path[0])
+	assert.Equal(t, DeriveJunction{chainCode: [32]byte{0x0c, 'D', 'O', 'T'}, isHard: false}, path[1])
+
+	password, phrase, path, err = extractKey("hello world//Alice")
+	assert.NoError(t, err)
+	assert.Empty(t, password)
+	assert.Equal(t, "hello world", phrase)
+	assert.Equal(t, []DeriveJunction{{chainCode: [32]byte{0x14, 'A', 'l', 'i', 'c', 'e'}, isHard: true}}, path)
+
+	_, phrase, path, err = extractKey("//Alice//stash")
+	assert.NoError(t, err)
+	assert.Empty(t, phrase)
+	assert.Len(t, path, 2)
+	assert.True(t, path[1].isHard)
+
+	_, phrase, path, err = extractKey(DEV_PHRASE)
+	assert.NoError(t, err)
+	assert.Equal(t, DEV_PHRASE, phrase)
+	assert.Empty(t, path)
+
+	// ids longer than the chain code are hashed
+	_, _, path, err = extractKey("//" + strings.Repeat("a", 32))
+	assert.NoError(t, err)
+	enc, err := scale.EncodeToBytes(strings.Repeat("a", 32))
+	assert.NoError(t, err)
+	assert.Equal(t, DeriveJunction{chainCode: blake2b.Sum256(enc), isHard: true}, path[0])
+
+	_, _, _, err = extractKey("hello/world/")
+	assert.EqualError(t, err, "suri is not correct")
+}
+
+// test vector 1 of RFC 8032
+const (
+	testSeed      = "0x9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+	testPubKey    = "0xd75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	testSignature = "0xe5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"
+)
+
+func TestNewKeyringPairFromSeed_ED25519(t *testing.T) {
+	seed, _ := hexutil.Decode(testSeed)
+	p, err := NewKeyringPairFromSeed(seed, ED25519, ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, ED25519, p.Type())
+	assert.Equal(t, testPubKey, hexutil.Encode(p.PublicKey()))
+	assert.Equal(t, ss58.Encode(p.PublicKey(), ss58.SubstratePrefix), p.Address())
+
+	sig, err := p.Sign([]byte{})
+	assert.NoError(t, err)
+	assert.True(t, sig.IsEd25519)
+	assert.Equal(t, testSignature, hexutil.Encode(sig.AsEd25519[:]))
+	assert.True(t, p.Verify([]byte{}, sig))
+	assert.True(t, Verify(p.PublicKey(), []byte{}, sig))
+	assert.False(t, Verify(p.PublicKey(), []byte{1}, sig))
+
+	p.Lock()
+	assert.True(t, p.IsLocked())
+	_, err = p.Sign([]byte{})
+	assert.Error(t, err)
+	assert.True(t, p.Verify([]byte{}, sig))
+}
+
+func TestNewKeyringPairFromSeed_invalid(t *testing.T) {
+	_, err := NewKeyringPairFromSeed([]byte{1, 2, 3}, ED25519, ss58.SubstratePrefix)
+	assert.Error(t, err)
+
+	_, err = NewKeyringPairFromSeed(make([]byte, 32), SupportedKeyType(42), ss58.SubstratePrefix)
+	assert.EqualError(t, err, "key type unknown(42) not supported")
+}
+
+func TestMultiSignature_EncodeDecode(t *testing.T) {
+	ed25519 := MultiSignature{IsEd25519: true}
+	copy(ed25519.AsEd25519[:], hexutil.MustDecode(testSignature))
+	sr25519 := MultiSignature{IsSr25519: true}
+	copy(sr25519.AsSr25519[:], hexutil.MustDecode(testSignature))
+	// ecdsa signatures have a recovery id in the last byte
+	ecdsa := MultiSignature{IsEcdsa: true}
+	copy(ecdsa.AsEcdsa[:], append(hexutil.MustDecode(testSignature), 0x01))
+
+	for _, test := range []struct {
+		sig     MultiSignature
+		encoded []byte
+	}{
+		{ed25519, append([]byte{0}, ed25519.AsEd25519[:]...)},
+		{sr25519, append([]byte{1}, sr25519.AsSr25519[:]...)},
+		{ecdsa, append([]byte{2}, ecdsa.AsEcdsa[:]...)},
+	} {
+		var buf bytes.Buffer
+		err := scale.NewEncoder(&buf).Encode(test.sig)
+		assert.NoError(t, err)
+		assert.Equal(t, test.encoded, buf.Bytes())
+
+		var dec MultiSignature
+		err = scale.NewDecoder(&buf).Decode(&dec)
+		assert.NoError(t, err)
+		assert.Equal(t, test.sig, dec)
+	}
+
+	var buf bytes.Buffer
+	err := scale.NewEncoder(&buf).Encode(MultiSignature{})
+	assert.Error(t, err)
 
-	extractKey("hello world//Alice")
+	var dec MultiSignature
+	err = scale.NewDecoder(bytes.NewReader(append([]byte{2}, ecdsa.AsEcdsa[:64]...))).Decode(&dec)
+	assert.Error(t, err)
+	err = scale.NewDecoder(bytes.NewReader([]byte{3})).Decode(&dec)
+	assert.EqualError(t, err, "unknown signature type 3")
 }
@@ -0,0 +1,184 @@
+package signature
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// sr25519SigningContext is the signing context substrate uses for all sr25519 signatures
+var sr25519SigningContext = []byte("substrate")
+
+// sr25519Pair is a schnorrkel pair on ristretto25519, the scheme of accounts created by subkey and the polkadot-js
+// extension
+type sr25519Pair struct {
+	publicKey *schnorrkel.PublicKey
+	secretKey *schnorrkel.SecretKey
+	network   uint8
+	meta      map[string]interface{}
+}
+
+// KeyringPairFromMnemonic creates an sr25519 keyring pair from a BIP39 mnemonic phrase, eg: DEV_PHRASE. The key is
+// derived with substrate-bip39 without a password, the result matches `subkey inspect "<phrase>"`. network is the
+// SS58 prefix used for the address of the pair.
+func KeyringPairFromMnemonic(phrase string, network uint8) (KeyringPair, error) {
+	msk, err := schnorrkel.MiniSecretKeyFromMnemonic(phrase, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return newSr25519Pair(msk, network), nil
+}
+
+// KeyringPairFromURI creates an sr25519 keyring pair from a secret URI as subkey accepts it,
+// `<secret>/<soft-key>//<hard-key>///<password>`. The secret is a mnemonic phrase or a hex encoded 32 byte seed, it
+// is DEV_PHRASE if omitted, eg: "//Alice" and "//Alice//stash" are the dev accounts of substrate nodes. network is
+// the SS58 prefix used for the address of the pair.
+func KeyringPairFromURI(suri string, network uint8) (KeyringPair, error) {
+	password, phrase, path, err := extractKey(suri)
+	if err != nil {
+		return nil, err
+	}
+
+	if phrase == "" {
+		phrase = DEV_PHRASE
+	}
+
+	var msk *schnorrkel.MiniSecretKey
+	if strings.HasPrefix(phrase, "0x") {
+		seed, err := hexutil.Decode(phrase)
+		if err != nil {
+			return nil, err
+		}
+		if len(seed) != 32 {
+			return nil, fmt.Errorf("expected a seed of 32 bytes, got %d", len(seed))
+		}
+		var b [32]byte
+		copy(b[:], seed)
+		msk, err = schnorrkel.NewMiniSecretKeyFromRaw(b)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		msk, err = schnorrkel.MiniSecretKeyFromMnemonic(phrase, password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// junctions are derived without additional data, like substrate does
+	secret := msk.ExpandEd25519()
+	for _, j := range path {
+		var ek *schnorrkel.ExtendedKey
+		if j.isHard {
+			ek, err = schnorrkel.DeriveKeyHard(secret, []byte{}, j.chainCode)
+		} else {
+			ek, err = schnorrkel.DeriveKeySimple(secret, []byte{}, j.chainCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		secret, err = ek.Secret()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pub, err := secret.Public()
+	if err != nil {
+		return nil, err
+	}
+
+	return &sr25519Pair{
+		publicKey: pub,
+		secretKey: secret,
+		network:   network,
+		meta:      make(map[string]interface{}),
+	}, nil
+}
+
+func newSr25519Pair(msk *schnorrkel.MiniSecretKey, network uint8) *sr25519Pair {
+	return &sr25519Pair{
+		publicKey: msk.Public(),
+		secretKey: msk.ExpandEd25519(),
+		network:   network,
+		meta:      make(map[string]interface{}),
+	}
+}
+
+func (p *sr25519Pair) Type() SupportedKeyType {
+	return SR25519
+}
+
+func (p *sr25519Pair) Address() string {
+	return ss58.Encode(p.PublicKey(), p.network)
+}
+
+func (p *sr25519Pair) Meta() map[string]interface{} {
+	return p.meta
+}
+
+func (p *sr25519Pair) SetMeta(meta map[string]interface{}) {
+	p.meta = meta
+}
+
+func (p *sr25519Pair) IsLocked() bool {
+	return p.secretKey == nil
+}
+
+// Lock removes the secret key from the pair, the pair can only be used for verification afterwards
+func (p *sr25519Pair) Lock() {
+	p.secretKey = nil
+}
+
+func (p *sr25519Pair) PublicKey() []byte {
+	pub := p.publicKey.Encode()
+	return pub[:]
+}
+
+// Sign signs the message in the substrate signing context, signatures are randomized and differ on every call
+func (p *sr25519Pair) Sign(message []byte) (MultiSignature, error) {
+	if p.IsLocked() {
+		return MultiSignature{}, errors.New("cannot sign with a locked pair")
+	}
+
+	sig, err := p.secretKey.Sign(schnorrkel.NewSigningContext(sr25519SigningContext, message))
+	if err != nil {
+		return MultiSignature{}, err
+	}
+
+	return MultiSignature{IsSr25519: true, AsSr25519: sig.Encode()}, nil
+}
+
+func (p *sr25519Pair) Verify(message []byte, signature MultiSignature) bool {
+	return Verify(p.PublicKey(), message, signature)
+}
+
+// verifySr25519 verifies the signature of message in the substrate signing context against the public key
+func verifySr25519(publicKey []byte, message []byte, signature [64]byte) bool {
+	if len(publicKey) != 32 {
+		return false
+	}
+
+	var b [32]byte
+	copy(b[:], publicKey)
+	pub := new(schnorrkel.PublicKey)
+	err := pub.Decode(b)
+	if err != nil {
+		return false
+	}
+
+	sig := new(schnorrkel.Signature)
+	err = sig.Decode(signature)
+	if err != nil {
+		return false
+	}
+
+	ok, err := pub.Verify(sig, schnorrkel.NewSigningContext(sr25519SigningContext, message))
+	return err == nil && ok
+}
@@ -0,0 +1,117 @@
+// +build tests
+
+package signature
+
+import (
+	"testing"
+
+	"github.com/centrifuge/go-substrate-rpc-client/ss58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// output of subkey inspect "bottom drive obey lake curtain smoke basket hold race lonely fit walk"
+const (
+	testDevSeed    = "0xfac7959dbfe72f052e5a0c3c8d6530f202b02fd8f9f5ca3580ec8deb7797479e"
+	testDevPubKey  = "0x46ebddef8cd9bb167dc30878d7113b7e168e6f0646beffd77d69d39bad76b47a"
+	testDevAddress = "5DfhGyQdFobKM8NsWvEeAKk5EQQgYe9AydgJ7rMB6E1EqRzV"
+)
+
+func TestKeyringPairFromMnemonic(t *testing.T) {
+	p, err := KeyringPairFromMnemonic(DEV_PHRASE, ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, SR25519, p.Type())
+	assert.Equal(t, testDevPubKey, hexutil.Encode(p.PublicKey()))
+	assert.Equal(t, testDevAddress, p.Address())
+
+	msg := []byte("anchor")
+	sig, err := p.Sign(msg)
+	assert.NoError(t, err)
+	assert.True(t, sig.IsSr25519)
+	assert.True(t, p.Verify(msg, sig))
+	assert.True(t, Verify(p.PublicKey(), msg, sig))
+	assert.False(t, Verify(p.PublicKey(), []byte("other"), sig))
+
+	p.Lock()
+	_, err = p.Sign(msg)
+	assert.Error(t, err)
+	assert.True(t, p.Verify(msg, sig))
+
+	_, err = KeyringPairFromMnemonic("bottom drive obey lake", ss58.SubstratePrefix)
+	assert.Error(t, err)
+}
+
+func TestNewKeyringPairFromSeed_SR25519(t *testing.T) {
+	seed, _ := hexutil.Decode(testDevSeed)
+	p, err := NewKeyringPairFromSeed(seed, SR25519, ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, testDevPubKey, hexutil.Encode(p.PublicKey()))
+
+	_, err = NewKeyringPairFromSeed(seed[1:], SR25519, ss58.SubstratePrefix)
+	assert.Error(t, err)
+
+	// sr25519 is the default
+	p, err = NewKeyringPairFromSeed(seed, 0, ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, SR25519, p.Type())
+	assert.Equal(t, testDevPubKey, hexutil.Encode(p.PublicKey()))
+}
+
+func TestKeyringPairFromURI(t *testing.T) {
+	// output of subkey inspect for the dev accounts
+	for _, test := range []struct {
+		suri, pubKey, address string
+	}{
+		{"//Alice", "0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d",
+			"5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"},
+		{"//Bob", "0x8eaf04151687736326c9fea17e25fc5287613693c912909cb226aa4794f26a48",
+			"5FHneW46xGXgs5mUiveU4sbTyGBzmstUspZC92UhjJM694ty"},
+		{"//Alice//stash", "0xbe5ddb1579b72e84524fc29e78609e3caf42e85aa118ebfe0b0ad404b5bdd25f",
+			"5GNJqTPyNqANBkUVMN1LPPrxXnFouWXoe2wNSmmEoLctxiZY"},
+		{DEV_PHRASE + "//Alice", "0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d",
+			"5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY"},
+		{DEV_PHRASE, testDevPubKey, testDevAddress},
+		{testDevSeed, testDevPubKey, testDevAddress},
+	} {
+		p, err := KeyringPairFromURI(test.suri, ss58.SubstratePrefix)
+		assert.NoError(t, err, test.suri)
+		assert.Equal(t, SR25519, p.Type())
+		assert.Equal(t, test.pubKey, hexutil.Encode(p.PublicKey()), test.suri)
+		assert.Equal(t, test.address, p.Address(), test.suri)
+	}
+
+	// soft junctions can be derived from the public key alone, the keys are the soft derivations of the public keys
+	// of //Alice and //Alice//stash above
+	for _, test := range []struct {
+		suri, pubKey string
+	}{
+		{"//Alice/foo", "0xf2d22c92a77441efe17f5f4e5e9fc535df36943a88f81ab941cbecfe1b55367f"},
+		{"//Alice/foo/1", "0xa28f26a5486250a97b62f1b8d382c2a0391546608d804f2f1f4bf9a3dfadd53e"},
+		{"//Alice//stash/foo", "0x06b6a1c3df9b6c1ddacc0281ce4244397f18f756ad2290913ab2c433b891913e"},
+	} {
+		p, err := KeyringPairFromURI(test.suri, ss58.SubstratePrefix)
+		assert.NoError(t, err, test.suri)
+		assert.Equal(t, test.pubKey, hexutil.Encode(p.PublicKey()), test.suri)
+	}
+
+	// soft derived pairs sign like any other pair
+	p, err := KeyringPairFromURI("//Alice/foo", ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	msg := []byte("anchor")
+	sig, err := p.Sign(msg)
+	assert.NoError(t, err)
+	assert.True(t, Verify(p.PublicKey(), msg, sig))
+
+	hard, err := KeyringPairFromURI("//Alice//foo", ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	assert.NotEqual(t, p.PublicKey(), hard.PublicKey())
+
+	// the password changes the key
+	p, err = KeyringPairFromURI("//Alice///password", ss58.SubstratePrefix)
+	assert.NoError(t, err)
+	assert.NotEqual(t, "0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d",
+		hexutil.Encode(p.PublicKey()))
+
+	_, err = KeyringPairFromURI("0x0102//Alice", ss58.SubstratePrefix)
+	assert.EqualError(t, err, "expected a seed of 32 bytes, got 2")
+}
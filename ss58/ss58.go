@@ -0,0 +1,150 @@
+// Package ss58 implements the SS58 address format used by substrate based chains.
+// See https://github.com/paritytech/substrate/wiki/External-Address-Format-(SS58)
+package ss58
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/minio/blake2b-simd"
+)
+
+const (
+	// PolkadotPrefix is the network prefix for polkadot addresses
+	PolkadotPrefix uint8 = 0
+
+	// KusamaPrefix is the network prefix for kusama addresses
+	KusamaPrefix uint8 = 2
+
+	// SubstratePrefix is the network prefix for generic substrate addresses
+	SubstratePrefix uint8 = 42
+)
+
+const (
+	publicKeyLength = 32
+	checksumLength  = 2
+)
+
+var checksumPrefix = []byte("SS58PRE")
+
+// Encode returns the SS58 representation of the given 32 byte public key for the network prefix. Prefixes below 64
+// take one byte, larger ones the two byte form.
+func Encode(pubKey []byte, prefix uint8) string {
+	payload := append(encodePrefix(prefix), pubKey...)
+	sum := checksum(payload)
+	return base58Encode(append(payload, sum[:checksumLength]...))
+}
+
+// Decode decodes an SS58 address into its public key and network prefix. The checksum is validated.
+func Decode(address string) (pubKey []byte, prefix uint8, err error) {
+	b, err := base58Decode(address)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// the first byte tells whether the prefix takes one or two bytes
+	prefixLength := 1
+	if len(b) > 0 && b[0] >= 64 {
+		prefixLength = 2
+	}
+	if len(b) != prefixLength+publicKeyLength+checksumLength {
+		return nil, 0, fmt.Errorf("unsupported address length %d", len(b))
+	}
+
+	payload := b[:prefixLength+publicKeyLength]
+	sum := checksum(payload)
+	if !bytes.Equal(sum[:checksumLength], b[prefixLength+publicKeyLength:]) {
+		return nil, 0, errors.New("invalid address checksum")
+	}
+
+	prefix, err = decodePrefix(payload[:prefixLength])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return payload[prefixLength:], prefix, nil
+}
+
+// encodePrefix returns the one or two byte form of prefix. The two byte form starts with the marker bits 0b01 and the
+// bits 2-7 of the prefix, followed by its bits 0-1 and the bits 8-13, which are always 0 for a uint8 prefix.
+func encodePrefix(prefix uint8) []byte {
+	if prefix < 64 {
+		return []byte{prefix}
+	}
+
+	return []byte{prefix>>2 | 0x40, prefix << 6}
+}
+
+// decodePrefix returns the prefix of the one or two byte form b, prefixes above 255 are not supported
+func decodePrefix(b []byte) (uint8, error) {
+	if len(b) == 1 {
+		return b[0], nil
+	}
+
+	if b[0] >= 128 {
+		return 0, fmt.Errorf("reserved address prefix byte %d", b[0])
+	}
+	if b[1]&0x3f != 0 {
+		return 0, fmt.Errorf("unsupported address prefix %d", uint16(b[0]&0x3f)<<2|uint16(b[1]>>6)|uint16(b[1]&0x3f)<<8)
+	}
+
+	return b[0]<<2 | b[1]>>6, nil
+}
+
+func checksum(payload []byte) [64]byte {
+	b := make([]byte, 0, len(checksumPrefix)+len(payload))
+	b = append(b, checksumPrefix...)
+	return blake2b.Sum512(append(b, payload...))
+}
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var bigRadix = big.NewInt(58)
+
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	res := make([]byte, 0, len(b)*138/100+1)
+	for x.Sign() > 0 {
+		x.DivMod(x, bigRadix, mod)
+		res = append(res, alphabet[mod.Int64()])
+	}
+
+	// leading zero bytes are encoded as the first character of the alphabet
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		res = append(res, alphabet[0])
+	}
+
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return string(res)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	if s == "" {
+		return nil, errors.New("empty address")
+	}
+
+	x := new(big.Int)
+	for _, c := range []byte(s) {
+		i := bytes.IndexByte([]byte(alphabet), c)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		x.Mul(x, bigRadix)
+		x.Add(x, big.NewInt(int64(i)))
+	}
+
+	var zeros int
+	for zeros < len(s) && s[zeros] == alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
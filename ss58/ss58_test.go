@@ -0,0 +1,65 @@
+// +build tests
+
+package ss58
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const alicePubKey = "0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"
+
+func TestEncode(t *testing.T) {
+	b, _ := hexutil.Decode(alicePubKey)
+	assert.Equal(t, "5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQY", Encode(b, SubstratePrefix))
+	assert.Equal(t, "15oF4uVJwmo4TdGW7VfQxNLavjCXviqxT9S1MgbjMNHr6Sp5", Encode(b, PolkadotPrefix))
+	assert.Equal(t, "HNZata7iMYWmk5RvZRTiAsSDhV8366zq2YGb3tLH5Upf74F", Encode(b, KusamaPrefix))
+	// two byte prefixes
+	assert.Equal(t, "cEaNSpz4PxFcZ7nT1VEKrKewH67rfx6MfcM6yKojyyPz7qaqp", Encode(b, 64))
+	assert.Equal(t, "yGHXkYLYqxijLKKfd9Q2CB9shRVu8rPNBS53wvwGTutYg4zTg", Encode(b, 255))
+}
+
+func TestEncodePrefix(t *testing.T) {
+	assert.Equal(t, []byte{0x3f}, encodePrefix(63))
+	assert.Equal(t, []byte{0x50, 0x00}, encodePrefix(64))
+	assert.Equal(t, []byte{0x7f, 0xc0}, encodePrefix(255))
+}
+
+func TestDecode(t *testing.T) {
+	for _, prefix := range []uint8{PolkadotPrefix, KusamaPrefix, SubstratePrefix, 63, 64, 65, 128, 255} {
+		b, _ := hexutil.Decode(alicePubKey)
+		s := Encode(b, prefix)
+		pub, p, err := Decode(s)
+		assert.NoError(t, err)
+		assert.Equal(t, prefix, p)
+		assert.Equal(t, alicePubKey, hexutil.Encode(pub))
+		assert.Equal(t, s, Encode(pub, p))
+	}
+}
+
+func TestDecode_invalid(t *testing.T) {
+	// wrong checksum
+	_, _, err := Decode("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKutQZ")
+	assert.Error(t, err)
+
+	// invalid character
+	_, _, err = Decode("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHGKut0Y")
+	assert.Error(t, err)
+
+	// wrong length
+	_, _, err = Decode("5GrwvaEF5zXb26Fz9rcQpDWS57CtERHpNehXCPcNoHG")
+	assert.Error(t, err)
+
+	_, _, err = Decode("")
+	assert.Error(t, err)
+
+	// prefix 256 doesn't fit into a uint8
+	_, _, err = Decode("VByeGLMtP8r8BYQpNX1sb2VtAW8GYCbtFAeXJwsA2ur3MNRdq")
+	assert.EqualError(t, err, "unsupported address prefix 256")
+
+	// first prefix byte 0x80 is reserved
+	_, _, err = Decode("yNfuy5qCeZLhrK5ZK7UKiLpttnZ3xN7fVWGWMJgRjYcbt5ZVR")
+	assert.EqualError(t, err, "reserved address prefix byte 128")
+}
@@ -2,11 +2,15 @@ package substrate
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
 	"strings"
+	"time"
 
+	"github.com/centrifuge/go-substrate-rpc-client/jsonrpc"
 	"github.com/centrifuge/go-substrate-rpc-client/scale"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/minio/blake2b-simd"
@@ -74,6 +78,69 @@ func (m *MetadataV4) MethodIndex(method string) MethodIDX {
 	return MethodIDX{sIDX, mIDX}
 }
 
+// calls returns the calls of all modules in the order of their indices, the module index counts the modules with
+// calls only. It is the only place that assigns call indices, the lookups of MetadataVersioned build on it.
+func (m *MetadataV4) calls() []CallMetadata {
+	var calls []CallMetadata
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if mod.CallsOptional != 1 {
+			continue
+		}
+		for ci, call := range mod.Calls {
+			calls = append(calls, newCallMetadata(mod.Name, MethodIDX{mi, uint8(ci)}, call))
+		}
+		mi++
+	}
+	return calls
+}
+
+// FindEventNamesForEventID returns the module and event name of the event with the given id, the module index
+// counts the modules with events only
+func (m *MetadataV4) FindEventNamesForEventID(eventID EventID) (string, string, error) {
+	mod, event, err := m.findEvent(eventID)
+	if err != nil {
+		return "", "", err
+	}
+	return mod, event.Name, nil
+}
+
+// findEvent returns the module name and the metadata of the event with the given id
+func (m *MetadataV4) findEvent(eventID EventID) (string, *EventMetadata, error) {
+	mi := uint8(0)
+	for _, mod := range m.Modules {
+		if mod.EventsOptional != 1 {
+			continue
+		}
+		if mi != eventID[0] {
+			mi++
+			continue
+		}
+		if int(eventID[1]) >= len(mod.Events) {
+			return "", nil, fmt.Errorf("event index %v for module %v out of range", eventID[1], mod.Name)
+		}
+		return mod.Name, &mod.Events[eventID[1]], nil
+	}
+	return "", nil, fmt.Errorf("module index %v out of range", eventID[0])
+}
+
+// findStorageEntry returns the storage function fn of the module with the storage prefix module
+func (m *MetadataV4) findStorageEntry(module, fn string) (*StorageFunctionMetadata, error) {
+	for _, mod := range m.Modules {
+		if mod.Prefix != module {
+			continue
+		}
+
+		for i := range mod.Storage {
+			if mod.Storage[i].Name == fn {
+				return &mod.Storage[i], nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no meta data found for module %s function %s", module, fn)
+}
+
 func (m *MetadataV4) Decode(decoder scale.Decoder) error {
 	err := decoder.Decode(&m.Modules)
 	if err != nil {
@@ -169,6 +236,27 @@ func (t TypMap) HashFunc() (hash.Hash, error) {
 	return nil, errors.New("hash function type not supported")
 }
 
+// storageHashersV4 are the storage hashers of metadata v4 by their index
+var storageHashersV4 = []StorageHasherV11{HasherBlake2_128, HasherBlake2_256, HasherTwox128, HasherTwox256,
+	HasherTwox64Concat}
+
+// hashers returns the hasher declared in the metadata
+func (t TypMap) hashers() ([]StorageHasherV11, error) {
+	if int(t.Hasher) >= len(storageHashersV4) {
+		return nil, fmt.Errorf("unknown storage hasher %d", t.Hasher)
+	}
+	return []StorageHasherV11{storageHashersV4[t.Hasher]}, nil
+}
+
+// hash applies the hasher declared in the metadata to data
+func (t TypMap) hash(data []byte) ([]byte, error) {
+	hashers, err := t.hashers()
+	if err != nil {
+		return nil, err
+	}
+	return hashers[0].hash(data)
+}
+
 func (m *TypMap) Decode(decoder scale.Decoder) error {
 	err := decoder.Decode(&m.Hasher)
 	if err != nil {
@@ -241,14 +329,67 @@ type StorageFunctionMetadata struct {
 	Documentation []string
 }
 
-func (s StorageFunctionMetadata) isMap() bool {
+func (s StorageFunctionMetadata) IsMap() bool {
 	return s.Type == 1
 }
 
-func (s StorageFunctionMetadata) isDMap() bool {
+func (s StorageFunctionMetadata) IsDoubleMap() bool {
 	return s.Type == 2
 }
 
+func (s StorageFunctionMetadata) IsPlain() bool {
+	return s.Type == 0
+}
+
+// Hashers returns the hashers of the keys, none for plain entries. The hasher of the second key of double maps is
+// declared as code in metadata v4 and is not supported.
+func (s StorageFunctionMetadata) Hashers() ([]StorageHasherV11, error) {
+	switch {
+	case s.IsMap():
+		return s.Map.hashers()
+	case s.IsDoubleMap():
+		return nil, fmt.Errorf("the hashers of the double map %s are not supported in metadata v4", s.Name)
+	}
+	return nil, nil
+}
+
+// KeyTypes returns the type names of the keys, none for plain entries
+func (s StorageFunctionMetadata) KeyTypes() []string {
+	switch {
+	case s.IsMap():
+		return []string{s.Map.Key}
+	case s.IsDoubleMap():
+		return []string{s.DMap.Key, s.DMap.Key2}
+	}
+	return nil
+}
+
+// ValueType returns the type name of the value
+func (s StorageFunctionMetadata) ValueType() string {
+	switch {
+	case s.IsMap():
+		return s.Map.Value
+	case s.IsDoubleMap():
+		return s.DMap.Value
+	}
+	return s.Plane
+}
+
+// key creates the key of a V4 storage function, the hash of "prefix name" with the key appended
+func (s *StorageFunctionMetadata) key(module string, key []byte) (StorageKey, error) {
+	afn := []byte(module + " " + s.Name)
+	// TODO why is add length prefix step in JS client doesn't add anything to the hashed key?
+	if s.IsMap() {
+		return s.Map.hash(append(afn, key...))
+	}
+
+	// TODO define hashing for 2 keys of double maps
+	if key != nil {
+		return createMultiXxhash(append(afn, key...), 2), nil
+	}
+	return createMultiXxhash(append(afn), 2), nil
+}
+
 func (m *StorageFunctionMetadata) Decode(decoder scale.Decoder) error {
 	err := decoder.Decode(&m.Name)
 	if err != nil {
@@ -356,12 +497,16 @@ func (m *ModuleMetaData) Decode(decoder scale.Decoder) error {
 	return nil
 }
 
-// MetadataVersioned only supports v4
+// MetadataVersioned supports v4 and v11, Version tells which of Metadata and MetadataV11 is set
 type MetadataVersioned struct {
 	// 1635018093
 	MagicNumber uint32
 	Version     uint8
 	Metadata    MetadataV4
+	MetadataV11 MetadataV11
+
+	// callIndex maps module.call to the call index, see BuildCallIndex
+	callIndex map[string]MethodIDX
 }
 
 func NewMetadataVersioned() *MetadataVersioned {
@@ -373,13 +518,19 @@ func (m *MetadataVersioned) Decode(decoder scale.Decoder) error {
 	if err != nil {
 		return err
 	}
-	// we need to decide which struct to use based on the following number(enum), for now its hardcoded
 	err = decoder.Decode(&m.Version)
 	if err != nil {
 		return err
 	}
 
-	err = decoder.Decode(&m.Metadata)
+	switch m.Version {
+	case 4:
+		err = decoder.Decode(&m.Metadata)
+	case 11:
+		err = decoder.Decode(&m.MetadataV11)
+	default:
+		err = fmt.Errorf("unsupported metadata version %d", m.Version)
+	}
 	if err != nil {
 		return err
 	}
@@ -387,6 +538,218 @@ func (m *MetadataVersioned) Decode(decoder scale.Decoder) error {
 	return nil
 }
 
+// MethodIndex returns the call index of method, given as module.call
+func (m *MetadataVersioned) MethodIndex(method string) MethodIDX {
+	if idx, ok := m.callIndex[method]; ok {
+		return idx
+	}
+
+	if m.Version == 11 {
+		return m.MetadataV11.MethodIndex(method)
+	}
+	return m.Metadata.MethodIndex(method)
+}
+
+// BuildCallIndex precomputes the call indexes of all calls, so MethodIndex doesn't need to search the modules. It
+// must be called before the metadata is shared between go routines.
+func (m *MetadataVersioned) BuildCallIndex() {
+	var names []string
+	if m.Version == 11 {
+		for _, mod := range m.MetadataV11.Modules {
+			for _, c := range mod.Calls {
+				names = append(names, mod.Name+"."+c.Name)
+			}
+		}
+	} else {
+		for _, mod := range m.Metadata.Modules {
+			for _, c := range mod.Calls {
+				names = append(names, mod.Name+"."+c.Name)
+			}
+		}
+	}
+
+	// the index is built with the search, so both always return the same call index
+	m.callIndex = nil
+	index := make(map[string]MethodIDX, len(names))
+	for _, n := range names {
+		index[n] = m.MethodIndex(n)
+	}
+	m.callIndex = index
+}
+
+// FindEventNamesForEventID returns the module and event name of the event with the given id
+func (m *MetadataVersioned) FindEventNamesForEventID(eventID EventID) (string, string, error) {
+	if m.Version == 11 {
+		return m.MetadataV11.FindEventNamesForEventID(eventID)
+	}
+	return m.Metadata.FindEventNamesForEventID(eventID)
+}
+
+// FindCall returns the call index of call, given as module.call. Unlike MethodIndex it fails if the call doesn't
+// exist.
+func (m *MetadataVersioned) FindCall(call string) (MethodIDX, error) {
+	if idx, ok := m.callIndex[call]; ok {
+		return idx, nil
+	}
+
+	s := strings.Split(call, ".")
+	if len(s) != 2 {
+		return MethodIDX{}, fmt.Errorf("expected a call as module.call, got %s", call)
+	}
+
+	var moduleFound bool
+	for _, c := range m.Calls() {
+		if c.Name == call {
+			return c.Index, nil
+		}
+		moduleFound = moduleFound || strings.HasPrefix(c.Name, s[0]+".")
+	}
+	if moduleFound {
+		return MethodIDX{}, fmt.Errorf("call %s not found in module %s", s[1], s[0])
+	}
+	return MethodIDX{}, fmt.Errorf("module %s not found", s[0])
+}
+
+// FindEventArgs returns the argument types of the event with the given id, eg: AccountId and Balance
+func (m *MetadataVersioned) FindEventArgs(eventID EventID) ([]string, error) {
+	var event *EventMetadata
+	var err error
+	if m.Version == 11 {
+		_, event, err = m.MetadataV11.findEvent(eventID)
+	} else {
+		_, event, err = m.Metadata.findEvent(eventID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return event.Args, nil
+}
+
+// FindCallArgs returns the module and call name and the arguments of the call with the given index, eg:
+// Balances.transfer with the arguments dest and value
+func (m *MetadataVersioned) FindCallArgs(idx MethodIDX) (string, []FunctionArgumentMetadata, error) {
+	var module string
+	for _, c := range m.Calls() {
+		if c.Index == idx {
+			return c.Name, c.Args, nil
+		}
+		if c.Index.SectionIndex == idx.SectionIndex {
+			module = strings.SplitN(c.Name, ".", 2)[0]
+		}
+	}
+	if module != "" {
+		return "", nil, fmt.Errorf("call index %v for module %v out of range", idx.MethodIndex, module)
+	}
+	return "", nil, fmt.Errorf("module index %v out of range", idx.SectionIndex)
+}
+
+// CallMetadata is a call of a module with its index, see MetadataVersioned.Calls
+type CallMetadata struct {
+	// Name is the module and call name, eg: Balances.transfer
+	Name          string
+	Index         MethodIDX
+	Args          []FunctionArgumentMetadata
+	Documentation []string
+}
+
+func newCallMetadata(module string, idx MethodIDX, call FunctionMetaData) CallMetadata {
+	return CallMetadata{Name: module + "." + call.Name, Index: idx, Args: call.Args, Documentation: call.Documentation}
+}
+
+// Calls returns all calls of the runtime in the order of their indices, eg: to list the available extrinsics. Use
+// FindCall to check whether a single call exists.
+func (m *MetadataVersioned) Calls() []CallMetadata {
+	if m.Version == 11 {
+		return m.MetadataV11.calls()
+	}
+	return m.Metadata.calls()
+}
+
+// CallIndexChange is a call whose index differs between two runtime versions, see MetadataDiff. Old is nil for
+// calls that were added and New is nil for calls that were removed.
+type CallIndexChange struct {
+	// Name is the module and call name, eg: Balances.transfer
+	Name string
+	Old  *MethodIDX
+	New  *MethodIDX
+}
+
+// MetadataDiff returns the calls whose index changed from the metadata from to the metadata to, eg: after a runtime
+// upgrade to find out whether cached call indices are stale. Changed and removed calls are in the order of from,
+// followed by the added calls in the order of to.
+func MetadataDiff(from, to *MetadataVersioned) []CallIndexChange {
+	toCalls := to.Calls()
+	toIndices := make(map[string]MethodIDX, len(toCalls))
+	for _, c := range toCalls {
+		toIndices[c.Name] = c.Index
+	}
+
+	var changes []CallIndexChange
+	fromNames := make(map[string]bool)
+	for _, c := range from.Calls() {
+		fromNames[c.Name] = true
+		old := c.Index
+		idx, ok := toIndices[c.Name]
+		if !ok {
+			changes = append(changes, CallIndexChange{Name: c.Name, Old: &old})
+		} else if idx != old {
+			changes = append(changes, CallIndexChange{Name: c.Name, Old: &old, New: &idx})
+		}
+	}
+
+	for _, c := range toCalls {
+		if !fromNames[c.Name] {
+			idx := c.Index
+			changes = append(changes, CallIndexChange{Name: c.Name, New: &idx})
+		}
+	}
+
+	return changes
+}
+
+// ExtrinsicVersion returns the version of the extrinsic format of the runtime, eg: 4, or 0 if the metadata doesn't
+// describe it, as before metadata v11
+func (m *MetadataVersioned) ExtrinsicVersion() uint8 {
+	if m.Version == 11 {
+		return m.MetadataV11.Extrinsic.Version
+	}
+	return 0
+}
+
+// StorageEntry is the metadata of a storage entry of any metadata version, see MetadataVersioned.FindStorageEntry
+type StorageEntry interface {
+	IsPlain() bool
+	IsMap() bool
+	IsDoubleMap() bool
+	// Hashers returns the hashers of the keys in order, none for plain entries
+	Hashers() ([]StorageHasherV11, error)
+	// KeyTypes returns the type names of the keys in order, none for plain entries
+	KeyTypes() []string
+	// ValueType returns the type name of the value
+	ValueType() string
+	// key creates the storage key of the entry in the storage of module, key is nil for plain entries
+	key(module string, key []byte) (StorageKey, error)
+}
+
+// FindStorageEntry returns the storage entry fn of the module with the storage prefix module
+func (m *MetadataVersioned) FindStorageEntry(module, fn string) (StorageEntry, error) {
+	// return the entries as they are found to not wrap a nil pointer in the interface
+	if m.Version == 11 {
+		entry, err := m.MetadataV11.findStorageEntry(module, fn)
+		if err != nil {
+			return nil, err
+		}
+		return entry, nil
+	}
+
+	entry, err := m.Metadata.findStorageEntry(module, fn)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
 type State struct {
 	client Client
 }
@@ -425,77 +788,437 @@ func (s *State) MetaData(blockHash Hash) (*MetadataVersioned, error) {
 
 type StorageKey []byte
 
+// NewStorageKey creates the key of the storage entry fn of module, key is the key of map entries and nil for plain
+// entries
 func NewStorageKey(meta MetadataVersioned, module string, fn string, key []byte) (StorageKey, error) {
-	var fnMeta *StorageFunctionMetadata
-	for _, m := range meta.Metadata.Modules {
-		if m.Prefix == module {
-			for _, s := range m.Storage {
-				if s.Name == fn {
-					fnMeta = &s
-					break
-				}
-			}
-		}
+	entry, err := meta.FindStorageEntry(module, fn)
+	if err != nil {
+		return nil, err
 	}
-	if fnMeta == nil {
-		return nil, fmt.Errorf("no meta data found for module %s function %s", module, fn)
+
+	return entry.key(module, key)
+}
+
+// NewStorageDoubleMapKey creates the key of a double map storage entry, key1 and key2 are hashed with the hashers
+// declared in the metadata. Only metadata v11 is supported.
+func NewStorageDoubleMapKey(meta MetadataVersioned, module string, fn string, key1, key2 []byte) (StorageKey, error) {
+	if meta.Version != 11 {
+		return nil, fmt.Errorf("double map storage keys are not supported for metadata v%d", meta.Version)
 	}
 
-	var hasher hash.Hash
-	var err error
-	if fnMeta.isMap() {
-		hasher, err = fnMeta.Map.HashFunc()
-		if err != nil {
-			return nil, err
-		}
-	} else if fnMeta.isDMap() {
-		// TODO define hashing for 2 keys
+	return newStorageDoubleMapKeyV11(&meta.MetadataV11, module, fn, key1, key2)
+}
+
+// NewStorageKeyPrefix creates the prefix all keys of a storage entry start with, twox128(module) ++ twox128(fn). It
+// can be passed to GetKeysPaged to iterate the keys of a map. Only metadata v11 is supported, the keys of older
+// metadata have no common prefix.
+func NewStorageKeyPrefix(meta MetadataVersioned, module string, fn string) (StorageKey, error) {
+	if meta.Version != 11 {
+		return nil, fmt.Errorf("storage key prefixes are not supported for metadata v%d", meta.Version)
 	}
 
-	afn := []byte(module + " " + fn)
-	// TODO why is add length prefix step in JS client doesn't add anything to the hashed key?
-	if hasher != nil {
-		hasher.Write(append(afn, key...))
-		return hasher.Sum(nil), nil
-	} else {
-		if key != nil {
-			return createMultiXxhash(append(afn, key...), 2), nil
-		}
-		return createMultiXxhash(append(afn), 2), nil
+	_, err := meta.MetadataV11.findStorageEntry(module, fn)
+	if err != nil {
+		return nil, err
 	}
+
+	return append(createMultiXxhash([]byte(module), 2), createMultiXxhash([]byte(fn), 2)...), nil
+}
+
+// NewStorageKeyFromHex decodes a hex encoded storage key, eg: as shown by polkadot-js
+func NewStorageKeyFromHex(s string) (StorageKey, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	return StorageKey(b), nil
+}
+
+// Hex returns the key hex encoded with 0x prefix
+func (s StorageKey) Hex() string {
+	return hexutil.Encode(s)
 }
 
 func (s StorageKey) Encode(encoder scale.Encoder) error {
-	return encoder.Encode(s)
+	return encoder.Encode([]byte(s))
 }
 
+// StorageData is the SCALE encoded value of a storage entry
 type StorageData []byte
 
+// Decoder returns a decoder for the value. Values of Vec types, see StorageEntry.ValueType, start with the compact
+// number of elements and are decoded into a slice, plain types are decoded into the type itself.
 func (s StorageData) Decoder() *scale.Decoder {
 	buf := bytes.NewBuffer(s[:])
 	return scale.NewDecoder(buf)
 }
 
+// ErrStorageNotFound is returned by Storage if nothing is stored at the key, see StorageOption to tell this case apart
+// without an error
+var ErrStorageNotFound = errors.New("empty result")
+
 func (s *State) Storage(key StorageKey, block []byte) (StorageData, error) {
-	var res string
-	var err error
+	return s.StorageContext(context.Background(), key, block)
+}
+
+// StorageContext is like Storage, but returns ctx.Err() once the context is done without waiting for the response
+func (s *State) StorageContext(ctx context.Context, key StorageKey, block []byte) (StorageData, error) {
+	data, ok, err := s.StorageOptionContext(ctx, key, block)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrStorageNotFound
+	}
+
+	return data, nil
+}
+
+// StorageOption returns the value stored at key, at the given block or the best block if block is nil. ok is false if
+// nothing is stored at key, unlike a value that is stored but empty or all zeros.
+func (s *State) StorageOption(key StorageKey, block []byte) (data StorageData, ok bool, err error) {
+	return s.StorageOptionContext(context.Background(), key, block)
+}
+
+// StorageOptionContext is like StorageOption, but returns ctx.Err() once the context is done without waiting for the
+// response
+func (s *State) StorageOptionContext(ctx context.Context, key StorageKey, block []byte) (data StorageData, ok bool,
+	err error) {
+	// the result is null if nothing is stored at key
+	var res *string
 	if block != nil {
-		err = s.client.Call(&res, "state_getStorage", hexutil.Encode(key), hexutil.Encode(block))
+		err = s.client.CallContext(ctx, &res, "state_getStorage", hexutil.Encode(key), hexutil.Encode(block))
+	} else {
+		err = s.client.CallContext(ctx, &res, "state_getStorage", hexutil.Encode(key))
+	}
+	if err != nil || res == nil {
+		return nil, false, err
+	}
+
+	data, err = hexutil.Decode(*res)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// StorageChangeSet is the result of state_queryStorageAt and the notification of state_subscribeStorage, the values
+// of the storage keys at Block
+type StorageChangeSet struct {
+	Block   Hash             `json:"block"`
+	Changes []KeyValueOption `json:"changes"`
+}
+
+// KeyValueOption is a storage key with its value, HasStorageData is false if there is no value stored at the key
+type KeyValueOption struct {
+	StorageKey     StorageKey
+	HasStorageData bool
+	StorageData    StorageData
+}
+
+// UnmarshalJSON decodes the [key, value] tuple of the RPC, the value is null if nothing is stored
+func (kv *KeyValueOption) UnmarshalJSON(data []byte) error {
+	var tuple []*hexutil.Bytes
+	err := json.Unmarshal(data, &tuple)
+	if err != nil {
+		return err
+	}
+
+	if len(tuple) != 2 || tuple[0] == nil {
+		return fmt.Errorf("expected a [key, value] tuple, got %s", data)
+	}
+
+	kv.StorageKey = StorageKey(*tuple[0])
+	kv.HasStorageData = tuple[1] != nil
+	if kv.HasStorageData {
+		kv.StorageData = StorageData(*tuple[1])
+	}
+	return nil
+}
+
+// QueryStorageAt returns the values of all keys in one call, at the given block or the best block if at is nil.
+// Each change set contains all keys, keys without a value have HasStorageData set to false.
+func (s *State) QueryStorageAt(keys []StorageKey, at *Hash) ([]StorageChangeSet, error) {
+	hexKeys := make([]string, len(keys))
+	for i, k := range keys {
+		hexKeys[i] = hexutil.Encode(k)
+	}
+
+	var res []StorageChangeSet
+	var err error
+	if at != nil {
+		err = s.client.Call(&res, "state_queryStorageAt", hexKeys, at.String())
+	} else {
+		err = s.client.Call(&res, "state_queryStorageAt", hexKeys)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range res {
+		res[i].Changes = withMissingKeys(hexKeys, res[i].Changes)
+	}
+
+	return res, nil
+}
+
+// GetStorageSize returns the size in bytes of the value stored at key, at the given block or the best block if at is
+// nil. ok is false if nothing is stored at key. It is cheaper than Storage to check if a value exists.
+func (s *State) GetStorageSize(key StorageKey, at *Hash) (size uint64, ok bool, err error) {
+	var res *uint64
+	if at != nil {
+		err = s.client.Call(&res, "state_getStorageSize", hexutil.Encode(key), at.String())
 	} else {
-		err = s.client.Call(&res, "state_getStorage", hexutil.Encode(key))
+		err = s.client.Call(&res, "state_getStorageSize", hexutil.Encode(key))
+	}
+	if err != nil || res == nil {
+		return 0, false, err
 	}
 
+	return *res, true, nil
+}
+
+// GetStorageHash returns the blake2b-256 hash of the value stored at key, at the given block or the best block if at
+// is nil. The hash is empty if nothing is stored at key.
+func (s *State) GetStorageHash(key StorageKey, at *Hash) (Hash, error) {
+	var res Hash
+	var err error
+	if at != nil {
+		err = s.client.Call(&res, "state_getStorageHash", hexutil.Encode(key), at.String())
+	} else {
+		err = s.client.Call(&res, "state_getStorageHash", hexutil.Encode(key))
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if res == "" {
-		return nil, errors.New("empty result")
+	return res, nil
+}
+
+// Call calls the runtime api method, eg: Core_version, with the SCALE encoded args at the given block, or at the
+// best block if at is nil. The result is SCALE encoded.
+func (s *State) Call(method string, args []byte, at *Hash) ([]byte, error) {
+	var res string
+	var err error
+	if at != nil {
+		err = s.client.Call(&res, "state_call", method, hexutil.Encode(args), at.String())
+	} else {
+		err = s.client.Call(&res, "state_call", method, hexutil.Encode(args))
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	return hexutil.Decode(res)
 }
 
+// GetRuntimeVersion returns the runtime version at the given block, or at the best block if at is nil
+func (s *State) GetRuntimeVersion(at *Hash) (*RuntimeVersion, error) {
+	var res RuntimeVersion
+	var err error
+	if at != nil {
+		err = s.client.Call(&res, "state_getRuntimeVersion", at.String())
+	} else {
+		err = s.client.Call(&res, "state_getRuntimeVersion")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetEvents returns the events of System.Events at the given block, or at the best block if at is nil. The events
+// are returned in the order they were emitted, which groups them by phase: initialization, the extrinsics in the
+// order of the block and finalization, see ExtrinsicEventRecords. Only the events of EventRecords are supported,
+// use DecodeEventRecordList with a custom target for other modules.
+func (s *State) GetEvents(meta *MetadataVersioned, at *Hash) ([]EventRecord, error) {
+	key, err := NewStorageKey(*meta, "System", "Events", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var block []byte
+	if at != nil {
+		block = *at
+	}
+
+	raw, err := s.Storage(key, block)
+	if err != nil {
+		return nil, err
+	}
+
+	var events EventRecords
+	return EventRecordsRaw(raw).DecodeEventRecordList(meta, &events)
+}
+
+// Timestamp returns the time of the given block, or of the best block if at is nil, as stored in Timestamp.Now
+func (s *State) Timestamp(meta *MetadataVersioned, at *Hash) (time.Time, error) {
+	key, err := NewStorageKey(*meta, "Timestamp", "Now", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var block []byte
+	if at != nil {
+		block = *at
+	}
+
+	raw, err := s.Storage(key, block)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var m Moment
+	err = raw.Decoder().Decode(&m)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return m.Time, nil
+}
+
+// StorageSubscription delivers the changes of the watched storage keys
+type StorageSubscription struct {
+	sub     *jsonrpc.Subscription
+	channel chan StorageChangeSet
+}
+
+// Chan returns the channel the change sets are delivered to, it is closed once the subscription ends
+func (s *StorageSubscription) Chan() <-chan StorageChangeSet {
+	return s.channel
+}
+
+// Err returns a channel that receives the error that ended the subscription, if any
+func (s *StorageSubscription) Err() <-chan error {
+	return s.sub.Err()
+}
+
+// Resubscribed returns a channel that is signalled after the subscription was renewed on a reconnect, changes made
+// in the meantime are missing, see jsonrpc.Subscription.Resubscribed. The first change set after the signal contains
+// the current values of all keys again.
+func (s *StorageSubscription) Resubscribed() <-chan struct{} {
+	return s.sub.Resubscribed()
+}
+
+// Unsubscribe ends the subscription
+func (s *StorageSubscription) Unsubscribe() {
+	s.sub.Unsubscribe()
+}
+
+// SubscribeStorage subscribes to changes of the given keys. The first change set contains the current values of all
+// keys, the following ones only the keys that changed in a block. Removed values have HasStorageData set to false.
+func (s *State) SubscribeStorage(keys []StorageKey) (*StorageSubscription, error) {
+	hexKeys := make([]string, len(keys))
+	for i, k := range keys {
+		hexKeys[i] = hexutil.Encode(k)
+	}
+
+	ch := make(chan StorageChangeSet)
+	sub, err := s.client.Subscribe(context.Background(), "state_subscribeStorage", "state_unsubscribeStorage", ch,
+		hexKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StorageSubscription{sub: sub, channel: ch}, nil
+}
+
+// GetKeysPaged returns up to count keys with the given prefix that come after startKey, or the first keys if startKey
+// is nil, at the given block or the best block if at is nil. A page with less than count keys is the last one, to
+// iterate all keys pass the last key of a page as startKey of the next call.
+func (s *State) GetKeysPaged(prefix StorageKey, count uint32, startKey *StorageKey, at *Hash) ([]StorageKey, error) {
+	args := []interface{}{hexutil.Encode(prefix), count}
+	if startKey != nil || at != nil {
+		var start *string
+		if startKey != nil {
+			k := hexutil.Encode(*startKey)
+			start = &k
+		}
+		args = append(args, start)
+	}
+	if at != nil {
+		args = append(args, at.String())
+	}
+
+	var res []hexutil.Bytes
+	err := s.client.Call(&res, "state_getKeysPaged", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]StorageKey, len(res))
+	for i, k := range res {
+		keys[i] = StorageKey(k)
+	}
+	return keys, nil
+}
+
+// NewChildStorageKey creates the key of a default child trie from its id, eg: the trie id of a contract. The key is
+// the id prefixed with ":child_storage:default:".
+func NewChildStorageKey(trieID []byte) StorageKey {
+	return append([]byte(":child_storage:default:"), trieID...)
+}
+
+// GetChildStorage returns the value stored at key in the child trie with the given key, see NewChildStorageKey, at the
+// given block or the best block if at is nil. ok is false if nothing is stored at key.
+func (s *State) GetChildStorage(childKey, key StorageKey, at *Hash) (data StorageData, ok bool, err error) {
+	args := []interface{}{hexutil.Encode(childKey), hexutil.Encode(key)}
+	if at != nil {
+		args = append(args, at.String())
+	}
+
+	var res *hexutil.Bytes
+	err = s.client.Call(&res, "childstate_getStorage", args...)
+	if err != nil || res == nil {
+		return nil, false, err
+	}
+
+	return StorageData(*res), true, nil
+}
+
+// GetChildKeys returns the keys with the given prefix in the child trie with the given key, see NewChildStorageKey,
+// at the given block or the best block if at is nil
+func (s *State) GetChildKeys(childKey, prefix StorageKey, at *Hash) ([]StorageKey, error) {
+	args := []interface{}{hexutil.Encode(childKey), hexutil.Encode(prefix)}
+	if at != nil {
+		args = append(args, at.String())
+	}
+
+	var res []hexutil.Bytes
+	err := s.client.Call(&res, "childstate_getKeys", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]StorageKey, len(res))
+	for i, k := range res {
+		keys[i] = StorageKey(k)
+	}
+	return keys, nil
+}
+
+// withMissingKeys returns the changes in the order of keys, adding the keys that are not part of changes without a
+// value
+func withMissingKeys(keys []string, changes []KeyValueOption) []KeyValueOption {
+	byKey := make(map[string]KeyValueOption, len(changes))
+	for _, c := range changes {
+		byKey[hexutil.Encode(c.StorageKey)] = c
+	}
+
+	all := make([]KeyValueOption, len(keys))
+	for i, k := range keys {
+		c, ok := byKey[k]
+		if !ok {
+			c = KeyValueOption{StorageKey: hexutil.MustDecode(k)}
+		}
+		all[i] = c
+	}
+	return all
+}
+
 func createMultiXxhash(data []byte, rounds int) []byte {
 	res := make([]byte, 0)
 	for i := 0; i < rounds; i++ {
@@ -4,7 +4,9 @@ package substrate
 
 import (
 	"bytes"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/centrifuge/go-substrate-rpc-client/scale"
 	"github.com/centrifuge/go-substrate-rpc-client/testrpc"
@@ -59,3 +61,281 @@ func TestState_Storage(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(0xffffffffffffff1), nonce)
 }
+
+func TestState_StorageOption(t *testing.T) {
+	s := NewStateRPC(testClient)
+	testServer.AddStorageKey("0x0c", "0x0000")
+	testServer.AddStorageKey("0x0d", "0x")
+
+	// values of zeros and empty values are stored
+	data, ok, err := s.StorageOption(StorageKey{0x0c}, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, StorageData{0x00, 0x00}, data)
+
+	data, ok, err = s.StorageOption(StorageKey{0x0d}, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Empty(t, data)
+
+	_, ok, err = s.StorageOption(StorageKey{0x0e}, nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = s.Storage(StorageKey{0x0e}, nil)
+	assert.Equal(t, ErrStorageNotFound, err)
+}
+
+func TestState_QueryStorageAt(t *testing.T) {
+	s := NewStateRPC(testClient)
+	testServer.AddStorageKey("0x01", "0x0102")
+	testServer.AddStorageKey("0x02", "0x")
+
+	res, err := s.QueryStorageAt([]StorageKey{{1}, {2}, {3}}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, []KeyValueOption{
+		{StorageKey: StorageKey{1}, HasStorageData: true, StorageData: StorageData{1, 2}},
+		{StorageKey: StorageKey{2}, HasStorageData: true, StorageData: StorageData{}},
+		{StorageKey: StorageKey{3}},
+	}, res[0].Changes)
+}
+
+func TestState_SubscribeStorage(t *testing.T) {
+	s := NewStateRPC(testClient)
+	testServer.AddStorageKey("0x0a", "0x0102")
+
+	sub, err := s.SubscribeStorage([]StorageKey{{0x0a}, {0x0b}})
+	assert.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	// the first change set contains the current values of all keys
+	select {
+	case set := <-sub.Chan():
+		assert.Equal(t, Hash(make([]byte, 32)), set.Block)
+		assert.Equal(t, []KeyValueOption{
+			{StorageKey: StorageKey{0x0a}, HasStorageData: true, StorageData: StorageData{1, 2}},
+			{StorageKey: StorageKey{0x0b}},
+		}, set.Changes)
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(time.Second):
+		t.Fatal("no change set")
+	}
+}
+
+func TestStorageKey_Hex(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	key, err := NewStorageKey(*m, "Timestamp", "Now", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xf0c365c3cf59d671eb72da0e7a4113c49f1f0515f462cdcf84e0f1d6045dfcbb", key.Hex())
+
+	parsed, err := NewStorageKeyFromHex(key.Hex())
+	assert.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	_, err = NewStorageKeyFromHex("f0c3")
+	assert.Error(t, err)
+}
+
+func TestNewStorageKeyPrefix(t *testing.T) {
+	m := decodeTestMetadataV11(t)
+	prefix, err := NewStorageKeyPrefix(*m, "System", "BlockHash")
+	assert.NoError(t, err)
+	assert.Len(t, prefix, 32)
+
+	key, err := NewStorageKey(*m, "System", "BlockHash", []byte{0, 0, 0, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, prefix, key[:32])
+
+	// the prefix of a double map is the same as of a map
+	prefix, err = NewStorageKeyPrefix(*m, "Staking", "ErasStakers")
+	assert.NoError(t, err)
+	key, err = NewStorageDoubleMapKey(*m, "Staking", "ErasStakers", []byte{1, 0, 0, 0}, make([]byte, 32))
+	assert.NoError(t, err)
+	assert.Equal(t, prefix, key[:32])
+
+	_, err = NewStorageKeyPrefix(*m, "System", "Unknown")
+	assert.Error(t, err)
+
+	_, err = NewStorageKeyPrefix(MetadataVersioned{Version: 4}, "System", "BlockHash")
+	assert.EqualError(t, err, "storage key prefixes are not supported for metadata v4")
+}
+
+// testAnchorData is the anchor data of the anchor module
+type testAnchorData struct {
+	ID            [32]byte
+	DocRoot       [32]byte
+	AnchoredBlock uint64
+}
+
+func (a *testAnchorData) Decode(decoder scale.Decoder) error {
+	err := decoder.Read(a.ID[:])
+	if err != nil {
+		return err
+	}
+	err = decoder.Read(a.DocRoot[:])
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(&a.AnchoredBlock)
+}
+
+func TestStorageData_Decoder_vec(t *testing.T) {
+	anchor := append(bytes.Repeat([]byte{0x01}, 32), bytes.Repeat([]byte{0x02}, 32)...)
+	data := StorageData(append(append([]byte{0x08}, append(anchor, 5, 0, 0, 0, 0, 0, 0, 0)...),
+		append(anchor, 6, 0, 0, 0, 0, 0, 0, 0)...))
+
+	var anchors []testAnchorData
+	err := data.Decoder().Decode(&anchors)
+	assert.NoError(t, err)
+	assert.Len(t, anchors, 2)
+	assert.Equal(t, byte(0x01), anchors[0].ID[31])
+	assert.Equal(t, byte(0x02), anchors[1].DocRoot[0])
+	assert.Equal(t, uint64(5), anchors[0].AnchoredBlock)
+	assert.Equal(t, uint64(6), anchors[1].AnchoredBlock)
+
+	// a single value has no length prefix
+	var single testAnchorData
+	err = StorageData(data[1:]).Decoder().Decode(&single)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), single.AnchoredBlock)
+
+	err = StorageData(data[:40]).Decoder().Decode(&anchors)
+	assert.Error(t, err)
+}
+
+func TestKeyValueOption_UnmarshalJSON(t *testing.T) {
+	var cs StorageChangeSet
+	err := json.Unmarshal([]byte(`{"block":"0x01","changes":[["0x02","0x0304"],["0x05",null]]}`), &cs)
+	assert.NoError(t, err)
+	assert.Equal(t, Hash{1}, cs.Block)
+	assert.Equal(t, []KeyValueOption{
+		{StorageKey: StorageKey{2}, HasStorageData: true, StorageData: StorageData{3, 4}},
+		{StorageKey: StorageKey{5}},
+	}, cs.Changes)
+
+	// missing keys are added without a value, in the order of the keys
+	changes := withMissingKeys([]string{"0x05", "0x06", "0x02"}, cs.Changes)
+	assert.Equal(t, []KeyValueOption{cs.Changes[1], {StorageKey: StorageKey{6}}, cs.Changes[0]}, changes)
+
+	err = json.Unmarshal([]byte(`["0x02"]`), &KeyValueOption{})
+	assert.Error(t, err)
+}
+
+func TestState_GetKeysPaged(t *testing.T) {
+	s := NewStateRPC(testClient)
+	for _, k := range []string{"0xaa01", "0xaa02", "0xaa03", "0xab01"} {
+		testServer.AddStorageKey(k, "0x01")
+	}
+
+	keys, err := s.GetKeysPaged(StorageKey{0xaa}, 2, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []StorageKey{{0xaa, 0x01}, {0xaa, 0x02}}, keys)
+
+	// last page with less than count keys
+	keys, err = s.GetKeysPaged(StorageKey{0xaa}, 2, &keys[1], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []StorageKey{{0xaa, 0x03}}, keys)
+
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	keys, err = s.GetKeysPaged(StorageKey{0xaa}, 2, &keys[0], &hash)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestState_GetRuntimeVersion(t *testing.T) {
+	testServer.SetRuntimeVersion(testRuntimeVersion)
+	s := NewStateRPC(testClient)
+
+	v, err := s.GetRuntimeVersion(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "kusama", v.SpecName)
+	assert.Equal(t, uint32(2026), v.SpecVersion)
+	assert.Equal(t, uint32(4), v.TransactionVersion)
+
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	v, err = s.GetRuntimeVersion(&hash)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2026), v.SpecVersion)
+}
+
+func TestState_GetStorageSizeHash(t *testing.T) {
+	s := NewStateRPC(testClient)
+	testServer.AddStorageKey("0x0a", "0x010203")
+
+	size, ok, err := s.GetStorageSize(StorageKey{0x0a}, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), size)
+
+	hash, err := s.GetStorageHash(StorageKey{0x0a}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x11c0e79b71c3976ccd0c02d1310e2516c08edc9d8b6f57ccd680d63a4d8e72da", hash.String())
+
+	_, ok, err = s.GetStorageSize(StorageKey{0x0b}, nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	hash, err = s.GetStorageHash(StorageKey{0x0b}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, hash)
+}
+
+func TestState_Timestamp(t *testing.T) {
+	s := NewStateRPC(testClient)
+	m, err := s.MetaData(nil)
+	assert.NoError(t, err)
+
+	key, err := NewStorageKey(*m, "Timestamp", "Now", nil)
+	assert.NoError(t, err)
+	testServer.AddStorageKey(hexutil.Encode(key), "0x534437d071010000")
+
+	ts, err := s.Timestamp(m, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1588336215123), ts.UnixNano()/int64(time.Millisecond))
+}
+
+func TestState_Call(t *testing.T) {
+	testServer.SetRuntimeCall("Core_version", "0x0102")
+	s := NewStateRPC(testClient)
+
+	res, err := s.Call("Core_version", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, res)
+
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	res, err = s.Call("Core_version", []byte{}, &hash)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, res)
+
+	_, err = s.Call("Unknown_method", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestState_ChildStorage(t *testing.T) {
+	s := NewStateRPC(testClient)
+	child := NewChildStorageKey([]byte{0x01, 0x02})
+	assert.Equal(t, ":child_storage:default:\x01\x02", string(child))
+	for _, k := range []string{"0xaa01", "0xaa02", "0xab01"} {
+		testServer.AddChildStorageKey(child.Hex(), k, "0x0102")
+	}
+
+	data, ok, err := s.GetChildStorage(child, StorageKey{0xaa, 0x01}, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, StorageData{0x01, 0x02}, data)
+
+	hash := Hash(hexutil.MustDecode(testBlockHash))
+	_, ok, err = s.GetChildStorage(child, StorageKey{0xac}, &hash)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	keys, err := s.GetChildKeys(child, StorageKey{0xaa}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []StorageKey{{0xaa, 0x01}, {0xaa, 0x02}}, keys)
+
+	keys, err = s.GetChildKeys(NewChildStorageKey([]byte{0x03}), StorageKey{}, &hash)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -7,3 +7,41 @@ type System struct {
 func NewSystemRPC(client Client) *System {
 	return &System{client: client}
 }
+
+// Health is the result of system_health
+type Health struct {
+	Peers           uint64 `json:"peers"`
+	IsSyncing       bool   `json:"isSyncing"`
+	ShouldHavePeers bool   `json:"shouldHavePeers"`
+}
+
+func (s *System) Health() (*Health, error) {
+	var h Health
+	err := s.client.Call(&h, "system_health")
+	if err != nil {
+		return nil, err
+	}
+
+	return &h, nil
+}
+
+// Name returns the name of the node implementation, eg: "Substrate Node"
+func (s *System) Name() (string, error) {
+	var n string
+	err := s.client.Call(&n, "system_name")
+	return n, err
+}
+
+// Version returns the version of the node implementation
+func (s *System) Version() (string, error) {
+	var v string
+	err := s.client.Call(&v, "system_version")
+	return v, err
+}
+
+// Chain returns the name of the chain the node is connected to, eg: "Development"
+func (s *System) Chain() (string, error) {
+	var c string
+	err := s.client.Call(&c, "system_chain")
+	return c, err
+}
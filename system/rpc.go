@@ -7,19 +7,30 @@ import (
 	"github.com/centrifuge/go-substrate-rpc-client/scale"
 )
 
+// AccountNonce returns the nonce of the account, from System.Account on runtimes that store the AccountInfo and from
+// System.AccountNonce on older ones. It is 0 for accounts that nothing is stored for yet.
 func AccountNonce(client substrate.Client, accountPubKey []byte) (uint64, error) {
 	m, err := client.MetaData(true)
 	if err != nil {
 		return 0, err
 	}
+
+	if _, err := m.FindStorageEntry("System", "Account"); err == nil {
+		info, err := AccountInfo(client, accountPubKey)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(info.Nonce), nil
+	}
+
 	key, err := substrate.NewStorageKey(*m, "System", "AccountNonce", accountPubKey)
 	if err != nil {
 		return 0, err
 	}
 
 	s := substrate.NewStateRPC(client)
-	data, err := s.Storage(key, nil)
-	if err != nil {
+	data, ok, err := s.StorageOption(key, nil)
+	if err != nil || !ok {
 		return 0, err
 	}
 
@@ -33,6 +44,48 @@ func AccountNonce(client substrate.Client, accountPubKey []byte) (uint64, error)
 	return nonce, nil
 }
 
+// AccountInfo returns the nonce and balances of the account stored under System.Account, they are all zero for
+// accounts that nothing is stored for, like the runtime's default value. Substrate 2.0 runtimes (V11 metadata) store
+// a single reference counter, it is returned as Consumers.
+func AccountInfo(client substrate.Client, accountPubKey []byte) (*substrate.AccountInfo, error) {
+	m, err := client.MetaData(true)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := substrate.NewStorageKey(*m, "System", "Account", accountPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s := substrate.NewStateRPC(client)
+	data, ok, err := s.StorageOption(key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info substrate.AccountInfo
+	if !ok {
+		return &info, nil
+	}
+
+	if m.Version == 11 {
+		var infoV11 substrate.AccountInfoV11
+		err = data.Decoder().Decode(&infoV11)
+		if err != nil {
+			return nil, err
+		}
+		return &substrate.AccountInfo{Nonce: infoV11.Nonce, Consumers: infoV11.RefCount, Data: infoV11.Data}, nil
+	}
+
+	err = data.Decoder().Decode(&info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
 func BlockHash(client substrate.Client, blockNumber uint64) (substrate.Hash, error) {
 	m, err := client.MetaData(true)
 	if err != nil {
@@ -59,3 +112,23 @@ func BlockHash(client substrate.Client, blockNumber uint64) (substrate.Hash, err
 
 	return substrate.Hash(data), nil
 }
+
+// Health returns the health of the node, it is available as soon as the node accepts connections
+func Health(client substrate.Client) (*substrate.Health, error) {
+	return substrate.NewSystemRPC(client).Health()
+}
+
+// Name returns the name of the node implementation
+func Name(client substrate.Client) (string, error) {
+	return substrate.NewSystemRPC(client).Name()
+}
+
+// Version returns the version of the node implementation
+func Version(client substrate.Client) (string, error) {
+	return substrate.NewSystemRPC(client).Version()
+}
+
+// Chain returns the name of the chain the node is connected to
+func Chain(client substrate.Client) (string, error) {
+	return substrate.NewSystemRPC(client).Chain()
+}
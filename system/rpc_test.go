@@ -38,3 +38,78 @@ func TestBlockHash(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, hexutil.Encode(h), "0xa8e78ad25e03ac0281ec709fd3f128efb7e112239d0a7c3e1c86375109bff338")
 }
+
+func TestHealth(t *testing.T) {
+	testServer.SetHealth(3, true, true)
+	h, err := Health(testClient)
+	assert.NoError(t, err)
+	assert.Equal(t, &substrate.Health{Peers: 3, IsSyncing: true, ShouldHavePeers: true}, h)
+}
+
+func TestNodeInfo(t *testing.T) {
+	testServer.SetNodeInfo("Substrate Node", "2.0.0-rc4", "Development")
+
+	name, err := Name(testClient)
+	assert.NoError(t, err)
+	assert.Equal(t, "Substrate Node", name)
+
+	version, err := Version(testClient)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.0-rc4", version)
+
+	chain, err := Chain(testClient)
+	assert.NoError(t, err)
+	assert.Equal(t, "Development", chain)
+}
+
+func TestAccountNonce(t *testing.T) {
+	pubKey := hexutil.MustDecode("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+	m, err := testClient.MetaData(true)
+	assert.NoError(t, err)
+	key, err := substrate.NewStorageKey(*m, "System", "AccountNonce", pubKey)
+	assert.NoError(t, err)
+
+	nonce, err := AccountNonce(testClient, pubKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), nonce)
+
+	testServer.AddStorageKey(key.Hex(), "0x0700000000000000")
+	defer testServer.RemoveStorageKey(key.Hex())
+	nonce, err = AccountNonce(testClient, pubKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), nonce)
+}
+
+func TestAccountNonce_V11(t *testing.T) {
+	testServer.SetMetadata(testrpc.GetTestMetaDataV11())
+	defer testServer.SetMetadata(testrpc.GetTestMetaData())
+	client, err := substrate.Connect(rpcURL)
+	assert.NoError(t, err)
+
+	pubKey := hexutil.MustDecode("0xd43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d")
+	m, err := client.MetaData(true)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(11), m.Version)
+	key, err := substrate.NewStorageKey(*m, "System", "Account", pubKey)
+	assert.NoError(t, err)
+
+	nonce, err := AccountNonce(client, pubKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), nonce)
+
+	// nonce 7, 1 reference, 10^12 free, the layout of substrate 2.0
+	testServer.AddStorageKey(key.Hex(), "0x07000000"+"01000000"+
+		"0010a5d4e8000000000000000000000000000000000000000000000000000000"+
+		"0000000000000000000000000000000000000000000000000000000000000000")
+	defer testServer.RemoveStorageKey(key.Hex())
+	nonce, err = AccountNonce(client, pubKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), nonce)
+
+	info, err := AccountInfo(client, pubKey)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(7), info.Nonce)
+	assert.Equal(t, uint32(1), info.Consumers)
+	assert.Equal(t, uint32(0), info.Providers)
+	assert.Equal(t, int64(1000000000000), info.Data.Free.Int64())
+}
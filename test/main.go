@@ -4,6 +4,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -30,6 +31,9 @@ const (
 
 	NumAnchorsPerThread = 2
 	Concurrency         = 1
+
+	// FinalizationTimeout is the time an anchor commit has to be finalized
+	FinalizationTimeout = 2 * time.Minute
 )
 
 type PreAnchorParams struct {
@@ -118,12 +122,18 @@ type AnchorData struct {
 }
 
 func (a *AnchorData) Decode(decoder scale.Decoder) error {
-	decoder.Read(a.ID[:])
-	decoder.Read(a.DocRoot[:])
-	decoder.Decode(&a.AnchoredBlock)
-	return nil
+	err := decoder.Read(a.ID[:])
+	if err != nil {
+		return err
+	}
+	err = decoder.Read(a.DocRoot[:])
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(&a.AnchoredBlock)
 }
 
+// Anchors returns the anchor stored for the pre image of the anchor id, it is nil if no anchor is stored
 func Anchors(client substrate.Client, module string, fn string, anchorIDPreImage []byte) (*AnchorData, error) {
 	h := blake2b.Sum256(anchorIDPreImage)
 	m, err := client.MetaData(true)
@@ -137,8 +147,8 @@ func Anchors(client substrate.Client, module string, fn string, anchorIDPreImage
 	}
 
 	s := substrate.NewStateRPC(client)
-	res, err := s.Storage(key, nil)
-	if err != nil {
+	res, ok, err := s.StorageOption(key, nil)
+	if err != nil || !ok {
 		return nil, err
 	}
 
@@ -152,12 +162,53 @@ func Anchors(client substrate.Client, module string, fn string, anchorIDPreImage
 	return &a, nil
 }
 
+// AnchorExists checks if an anchor is stored without fetching it, it only queries the size of the storage value
+func AnchorExists(client substrate.Client, module string, fn string, anchorIDPreImage []byte) (bool, error) {
+	h := blake2b.Sum256(anchorIDPreImage)
+	m, err := client.MetaData(true)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := substrate.NewStorageKey(*m, module, fn, h[:])
+	if err != nil {
+		return false, err
+	}
+
+	_, ok, err := substrate.NewStateRPC(client).GetStorageSize(key, nil)
+	return ok, err
+}
+
+// IsPending returns whether an extrinsic of the signer with the given nonce is in the transaction pool of the node
+func IsPending(authRPC *substrate.Author, signer []byte, nonce uint64) (bool, error) {
+	pending, err := authRPC.PendingExtrinsics()
+	if err != nil {
+		return false, err
+	}
+
+	for _, e := range pending {
+		if !e.IsSigned() {
+			continue
+		}
+
+		sig := e.Signature
+		if e.Version4 {
+			sig.Signer, sig.Nonce = e.SignatureV4.Signer, e.SignatureV4.Nonce
+		}
+		if bytes.Equal(sig.Signer.PubKey[:], signer) && sig.Nonce == nonce {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func main() {
-	// Connect the client.
-	client, err := substrate.Connect(RPCEndPoint)
+	// Connect the client, with a connection per thread
+	client, err := substrate.NewClientPool(RPCEndPoint, Concurrency)
 	if err != nil {
 		panic(err)
 	}
+	defer client.Close()
 	alice, _ := hexutil.Decode(substrate.AlicePubKey)
 	nonce, err := system.AccountNonce(client, alice)
 	if err != nil {
@@ -176,51 +227,76 @@ func main() {
 	var counter uint64
 	for i := 0; i < Concurrency; i++ {
 		go func() {
+			defer wg.Done()
+			// anchors are verified on every new block
+			heads, err := substrate.NewChainRPC(client).SubscribeNewHeads()
+			if err != nil {
+				fmt.Printf("FAIL!!! subscribing to new heads failed with %s\n", err.Error())
+				return
+			}
+			defer heads.Unsubscribe()
+
 			for i := 0; i < NumAnchorsPerThread; i++ {
 				// a := NewAnchorParamsFromHex("0x0000000000000000000000000000000000000000000000000000000000000901", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000")
 				pa, ap := NewRandomAnchorPreAnchorParams()
 				aID := ap.AnchorIDHex()
+				// avoid wasting the nonce on an extrinsic that would be rejected
+				dry, err := authRPC.DryRun(nonce, AnchorPreCommit, pa, nil)
+				if err != nil {
+					fmt.Printf("FAIL!!! dry run of pre commit for anchor ID %s failed with %s\n", aID, err.Error())
+					break
+				}
+				if dry.IsValidityError {
+					fmt.Printf("FAIL!!! pre commit for anchor ID %s would be rejected with %+v\n", aID, dry.AsValidityError)
+					break
+				}
+
 				res, err := authRPC.SubmitExtrinsic(nonce, AnchorPreCommit, pa)
 				if err != nil {
 					fmt.Printf("FAIL!!! pre commit for anchor ID %s failed with %s\n", aID, err.Error())
 					break
 				} else {
 					// verify pre anchor
-					for i := 0; i < 10; i++ {
-						time.Sleep(10 * time.Second)
-						anc, err := Anchors(client, "Anchor", "PreAnchors", ap.AnchorIDPreimage[:])
-						fmt.Println(err)
-						if anc != nil {
-							fmt.Printf("SUCCESS!!! pre anchor %v\n", anc)
-							break
+					stored := false
+					for i := 0; i < 10 && !stored; i++ {
+						if _, ok := <-heads.Chan(); !ok {
+							fmt.Printf("FAIL!!! new heads subscription ended with %v\n", <-heads.Err())
+							return
+						}
+						ok, err := AnchorExists(client, "Anchor", "PreAnchors", ap.AnchorIDPreimage[:])
+						if err != nil {
+							fmt.Println(err)
+						}
+						if ok {
+							fmt.Printf("SUCCESS!!! pre anchor %s stored\n", aID)
+							stored = true
 						}
 					}
+					if !stored {
+						// a queued extrinsic may still be included, a dropped one must be resubmitted
+						pending, err := IsPending(authRPC, alice, nonce)
+						if err != nil {
+							fmt.Println(err)
+						}
+						fmt.Printf("FAIL!!! pre anchor %s not stored yet, still pending: %v\n", aID, pending)
+					}
 					fmt.Printf("SUCCESS!!! pre anchor ID %s , tx hash %s\n", aID, res)
 					atomic.AddUint64(&nonce, 1)
 				}
 
 				// fmt.Println("submitting new anchor with anchor ID", a.AnchorIDHex())
-				res, err = authRPC.SubmitExtrinsic(nonce, AnchorCommit, ap)
+				ctx, cancel := context.WithTimeout(context.Background(), FinalizationTimeout)
+				block, err := authRPC.SubmitAndWaitFinalized(ctx, nonce, AnchorCommit, ap)
+				cancel()
 				if err != nil {
 					fmt.Printf("FAIL!!! commit for anchor ID %s failed with %s\n", aID, err.Error())
 					break
 				} else {
-					// verify anchor
-					for i := 0; i < 10; i++ {
-						time.Sleep(10 * time.Second)
-						anc, err := Anchors(client, "Anchor", "Anchors", ap.AnchorIDPreimage[:])
-						fmt.Println(err)
-						if anc != nil {
-							fmt.Printf("SUCCESS!!! anchor %v\n", anc)
-							break
-						}
-					}
-					fmt.Printf("SUCCESS!!! anchor ID %s , tx hash %s\n", aID, res)
+					fmt.Printf("SUCCESS!!! anchor ID %s finalized in block %s\n", aID, block.String())
 					atomic.AddUint64(&counter, 1)
 					atomic.AddUint64(&nonce, 1)
 				}
 			}
-			wg.Done()
 		}()
 	}
 
@@ -3,55 +3,361 @@
 package testrpc
 
 import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/rand"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/minio/blake2b-simd"
 )
 
 type authorService struct {
+	// pending are the hex encoded extrinsics returned by pendingExtrinsics
+	pending []string
+
+	// statuses are the JSON encoded statuses notified by submitAndWatchExtrinsic
+	statuses []string
+
+	// submitted are the hex encoded extrinsics submitted with submitExtrinsic
+	mu        sync.Mutex
+	submitted []string
+}
+
+// SubmitExtrinsic records the extrinsic and returns its blake2b-256 hash like a node, it is not validated
+func (s *authorService) SubmitExtrinsic(extrinsic string) (string, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(extrinsic, "0x"))
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.submitted = append(s.submitted, extrinsic)
+	s.mu.Unlock()
+
+	h := blake2b.Sum256(b)
+	return "0x" + hex.EncodeToString(h[:]), nil
+}
+
+// SubmitAndWatchExtrinsic serves author_submitAndWatchExtrinsic, it notifies the same statuses for every extrinsic
+func (s *authorService) SubmitAndWatchExtrinsic(ctx context.Context, extrinsic string) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	// the notifications are sent after the subscription id
+	sub := notifier.CreateSubscription()
+	for _, status := range s.statuses {
+		err := notifier.Notify(sub.ID, json.RawMessage(status))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sub, nil
 }
 
-func (s *authorService) SubmitExtrinsic(hex string) string {
-	// TODO make this more dynamic and have proper tests when subkey is included in the build
-	return hex
+func (s *authorService) PendingExtrinsics() []string {
+	if s.pending == nil {
+		return []string{}
+	}
+	return s.pending
 }
 
 type stateService struct {
 	metadata string
 
+	// runtimeVersion is the JSON encoded result of getRuntimeVersion
+	runtimeVersion string
+
 	storage map[string]string
 
 	storageForBlock map[string]map[string]string
+
+	// calls are the hex encoded results of state_call by runtime api method
+	calls map[string]string
 }
 
 func newStateService(metadata string) *stateService {
-	return &stateService{metadata: metadata, storageForBlock: make(map[string]map[string]string), storage: make(map[string]string)}
+	return &stateService{metadata: metadata, storageForBlock: make(map[string]map[string]string), storage: make(map[string]string),
+		calls: make(map[string]string)}
+}
+
+// Call returns the result set for the runtime api method, data and block are ignored
+func (s *stateService) Call(method, data string, block *string) (string, error) {
+	res, ok := s.calls[method]
+	if !ok {
+		return "", fmt.Errorf("unknown runtime api method %s", method)
+	}
+	return res, nil
 }
 
 func (s *stateService) GetMetadata(blocknum *string) string {
 	return s.metadata
 }
 
-func (s *stateService) GetStorage(key *string, blocknum *string) string {
+// GetRuntimeVersion returns the same version for every block, block is ignored
+func (s *stateService) GetRuntimeVersion(block *string) json.RawMessage {
+	return rawOrNull(s.runtimeVersion)
+}
+
+// GetStorage returns the value stored at key or null
+func (s *stateService) GetStorage(key *string, blocknum *string) *string {
+	var v string
+	var ok bool
 	if key != nil && blocknum != nil {
-		return s.storageForBlock[*key][*blocknum]
+		v, ok = s.storageForBlock[*key][*blocknum]
 	} else if key != nil {
-		return s.storage[*key]
+		v, ok = s.storage[*key]
+	}
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// GetStorageSize returns the size of the value stored at key or null, block is ignored
+func (s *stateService) GetStorageSize(key string, block *string) *uint64 {
+	v, ok := s.storage[key]
+	if !ok {
+		return nil
+	}
+	size := uint64(len(strings.TrimPrefix(v, "0x")) / 2)
+	return &size
+}
+
+// GetStorageHash returns the blake2b-256 hash of the value stored at key or null, block is ignored
+func (s *stateService) GetStorageHash(key string, block *string) *string {
+	v, ok := s.storage[key]
+	if !ok {
+		return nil
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(v, "0x"))
+	if err != nil {
+		return nil
+	}
+	h := blake2b.Sum256(b)
+	hash := "0x" + hex.EncodeToString(h[:])
+	return &hash
+}
+
+type storageChangeSet struct {
+	Block   string      `json:"block"`
+	Changes [][]*string `json:"changes"`
+}
+
+// QueryStorageAt returns the values of keys from the storage, block is ignored
+func (s *stateService) QueryStorageAt(keys []string, block *string) []storageChangeSet {
+	return []storageChangeSet{s.changeSet(keys)}
+}
+
+// Storage serves state_subscribeStorage, it notifies the current values of keys once
+func (s *stateService) Storage(ctx context.Context, keys []string) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+
+	// the notification is sent after the subscription id
+	sub := notifier.CreateSubscription()
+	err := notifier.Notify(sub.ID, s.changeSet(keys))
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// changeSet returns the values of keys from the storage as change set of the zero block hash
+func (s *stateService) changeSet(keys []string) storageChangeSet {
+	changes := make([][]*string, len(keys))
+	for i, k := range keys {
+		key := k
+		var value *string
+		if v, ok := s.storage[k]; ok {
+			value = &v
+		}
+		changes[i] = []*string{&key, value}
+	}
+
+	return storageChangeSet{Block: "0x0000000000000000000000000000000000000000000000000000000000000000", Changes: changes}
+}
+
+// GetKeysPaged returns the keys of the storage with the given prefix in lexicographic order, block is ignored
+func (s *stateService) GetKeysPaged(prefix string, count uint32, startKey *string, block *string) []string {
+	var keys []string
+	for k := range s.storage {
+		if strings.HasPrefix(k, prefix) && (startKey == nil || k > *startKey) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	if len(keys) > int(count) {
+		keys = keys[:count]
+	}
+	return keys
+}
+
+type childStateService struct {
+	// storage are the hex encoded values by hex encoded child trie key and key
+	storage map[string]map[string]string
+}
+
+// GetStorage returns the value stored at key in the child trie or null, block is ignored
+func (s *childStateService) GetStorage(childKey, key string, block *string) *string {
+	v, ok := s.storage[childKey][key]
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// GetKeys returns the keys of the child trie with the given prefix in lexicographic order, block is ignored
+func (s *childStateService) GetKeys(childKey, prefix string, block *string) []string {
+	keys := []string{}
+	for k := range s.storage[childKey] {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type chainService struct {
+	// headers and blocks are the JSON encoded headers and signed blocks by block hash
+	headers map[string]string
+	blocks  map[string]string
+	// hashes are the hex encoded block hashes by block number
+	hashes map[uint64]string
+
+	head          string
+	finalizedHead string
+}
+
+func newChainService() *chainService {
+	return &chainService{headers: make(map[string]string), blocks: make(map[string]string),
+		hashes: make(map[uint64]string)}
+}
+
+func (s *chainService) GetHeader(hash *string) json.RawMessage {
+	if hash == nil {
+		hash = &s.head
+	}
+	return rawOrNull(s.headers[*hash])
+}
+
+func (s *chainService) GetBlock(hash *string) json.RawMessage {
+	if hash == nil {
+		hash = &s.head
+	}
+	return rawOrNull(s.blocks[*hash])
+}
+
+// GetBlockHash returns the hash of the block with the given number, or of the head if number is nil
+func (s *chainService) GetBlockHash(number *uint64) *string {
+	if number == nil {
+		return &s.head
+	}
+	h, ok := s.hashes[*number]
+	if !ok {
+		return nil
+	}
+	return &h
+}
+
+func (s *chainService) GetFinalizedHead() string {
+	return s.finalizedHead
+}
+
+type systemService struct {
+	health SystemHealth
+
+	name, version, chain string
+
+	// dryRun is the hex encoded result of dryRun
+	dryRun string
+}
+
+// SystemHealth is the result of system_health. It is exported, as the rpc server doesn't register methods with
+// unexported result types.
+type SystemHealth struct {
+	Peers           uint64 `json:"peers"`
+	IsSyncing       bool   `json:"isSyncing"`
+	ShouldHavePeers bool   `json:"shouldHavePeers"`
+}
+
+func (s *systemService) Health() SystemHealth {
+	return s.health
+}
+
+func (s *systemService) Name() string {
+	return s.name
+}
+
+func (s *systemService) Version() string {
+	return s.version
+}
+
+func (s *systemService) Chain() string {
+	return s.chain
+}
+
+// DryRun returns the same result for every extrinsic, at is ignored
+func (s *systemService) DryRun(extrinsic string, at *string) (string, error) {
+	if s.dryRun == "" {
+		return "", errors.New("no dry run result set")
 	}
-	return ""
+	return s.dryRun, nil
+}
+
+type paymentService struct {
+	// queryInfo is the JSON encoded result of queryInfo
+	queryInfo string
+}
+
+// QueryInfo returns the same info for every extrinsic, at is ignored
+func (s *paymentService) QueryInfo(extrinsic string, at *string) json.RawMessage {
+	return rawOrNull(s.queryInfo)
+}
+
+type grandpaService struct {
+	// roundState is the JSON encoded result of roundState
+	roundState string
+}
+
+func (s *grandpaService) RoundState() json.RawMessage {
+	return rawOrNull(s.roundState)
+}
+
+func rawOrNull(s string) json.RawMessage {
+	if s == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s)
 }
 
 type Server struct {
-	author *authorService
-	state  *stateService
+	author     *authorService
+	state      *stateService
+	childState *childStateService
+	chain      *chainService
+	system     *systemService
+	payment    *paymentService
+	grandpa    *grandpaService
 
 	server *rpc.Server
 }
 
-// Following 4 methods are not go routine safe
+// Following methods are not go routine safe
 
 func (s *Server) AddStorageKey(key, value string) {
 	s.state.storage[key] = value
@@ -69,10 +375,97 @@ func (s *Server) RemoveStorageKeyForBlock(key, blocknum string) {
 	delete(s.state.storageForBlock[key], blocknum)
 }
 
+// AddChildStorageKey adds the value to the child trie with the given key, all values are hex encoded
+func (s *Server) AddChildStorageKey(childKey, key, value string) {
+	if s.childState.storage[childKey] == nil {
+		s.childState.storage[childKey] = make(map[string]string)
+	}
+	s.childState.storage[childKey][key] = value
+}
+
+// AddBlock adds the JSON encoded header and signed block under the given hash and makes it the head of the chain
+func (s *Server) AddBlock(hash, header, block string) {
+	s.chain.headers[hash] = header
+	s.chain.blocks[hash] = block
+	s.chain.head = hash
+}
+
+// AddBlockHash adds the hex encoded hash of the block with the given number
+func (s *Server) AddBlockHash(number uint64, hash string) {
+	s.chain.hashes[number] = hash
+}
+
+func (s *Server) SetFinalizedHead(hash string) {
+	s.chain.finalizedHead = hash
+}
+
+func (s *Server) SetHealth(peers uint64, isSyncing, shouldHavePeers bool) {
+	s.system.health = SystemHealth{Peers: peers, IsSyncing: isSyncing, ShouldHavePeers: shouldHavePeers}
+}
+
+// SetNodeInfo sets the results of system_name, system_version and system_chain
+func (s *Server) SetNodeInfo(name, version, chain string) {
+	s.system.name = name
+	s.system.version = version
+	s.system.chain = chain
+}
+
+// SetDryRun sets the hex encoded ApplyExtrinsicResult returned by system_dryRun
+func (s *Server) SetDryRun(result string) {
+	s.system.dryRun = result
+}
+
+// SetPendingExtrinsics sets the hex encoded extrinsics returned by author_pendingExtrinsics
+func (s *Server) SetPendingExtrinsics(extrinsics ...string) {
+	s.author.pending = extrinsics
+}
+
+// SubmittedExtrinsics returns the hex encoded extrinsics submitted with author_submitExtrinsic, in order
+func (s *Server) SubmittedExtrinsics() []string {
+	s.author.mu.Lock()
+	defer s.author.mu.Unlock()
+	return append([]string(nil), s.author.submitted...)
+}
+
+// SetExtrinsicStatuses sets the JSON encoded statuses notified by author_submitAndWatchExtrinsic
+func (s *Server) SetExtrinsicStatuses(statuses ...string) {
+	s.author.statuses = statuses
+}
+
+// SetMetadata sets the hex encoded result of state_getMetadata
+func (s *Server) SetMetadata(metadata string) {
+	s.state.metadata = metadata
+}
+
+// SetRuntimeVersion sets the JSON encoded result of state_getRuntimeVersion
+func (s *Server) SetRuntimeVersion(version string) {
+	s.state.runtimeVersion = version
+}
+
+// SetRuntimeCall sets the hex encoded result of state_call for the runtime api method
+func (s *Server) SetRuntimeCall(method, result string) {
+	s.state.calls[method] = result
+}
+
+// SetQueryInfo sets the JSON encoded result of payment_queryInfo
+func (s *Server) SetQueryInfo(info string) {
+	s.payment.queryInfo = info
+}
+
+// SetRoundState sets the JSON encoded result of grandpa_roundState
+func (s *Server) SetRoundState(state string) {
+	s.grandpa.roundState = state
+}
+
 // Init inits the testrpc server. rpcURL is the rpc url, eg: localhost:8080
 func (ts *Server) Init(metadata string, rpcURL *string) (string, error) {
 	ts.author = new(authorService)
 	ts.state = newStateService(metadata)
+	ts.childState = &childStateService{storage: make(map[string]map[string]string)}
+	ts.chain = newChainService()
+	ts.system = new(systemService)
+	ts.payment = new(paymentService)
+	ts.grandpa = new(grandpaService)
 	server := rpc.NewServer()
 	err := server.RegisterName("author", ts.author)
 	if err != nil {
@@ -84,7 +477,32 @@ func (ts *Server) Init(metadata string, rpcURL *string) (string, error) {
 		return "", err
 	}
 
-	http.Handle("/", server.WebsocketHandler([]string{"*"}))
+	err = server.RegisterName("childstate", ts.childState)
+	if err != nil {
+		return "", err
+	}
+
+	err = server.RegisterName("chain", ts.chain)
+	if err != nil {
+		return "", err
+	}
+
+	err = server.RegisterName("system", ts.system)
+	if err != nil {
+		return "", err
+	}
+
+	err = server.RegisterName("payment", ts.payment)
+	if err != nil {
+		return "", err
+	}
+
+	err = server.RegisterName("grandpa", ts.grandpa)
+	if err != nil {
+		return "", err
+	}
+
+	http.Handle("/", websocketHandler(server))
 	port := randomPort()
 	url := ""
 	if rpcURL == nil {
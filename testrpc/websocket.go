@@ -0,0 +1,89 @@
+// +build tests
+
+package testrpc
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/net/websocket"
+)
+
+// subscription is how the rpc server serves a substrate subscription, with the namespace_subscribe method and the
+// name of the subscription as first param
+type subscription struct {
+	namespace, name string
+}
+
+// subscribeMethods are the substrate subscribe methods the test server supports by method
+var subscribeMethods = map[string]subscription{
+	"state_subscribeStorage":         {"state", "storage"},
+	"author_submitAndWatchExtrinsic": {"author", "submitAndWatchExtrinsic"},
+}
+
+// unsubscribeMethods are the substrate unsubscribe methods by method, they are served by namespace_unsubscribe
+var unsubscribeMethods = map[string]string{
+	"state_unsubscribeStorage": "state",
+	"author_unwatchExtrinsic":  "author",
+}
+
+// websocketHandler serves the rpc server to websocket connections like rpc.Server.WebsocketHandler, but accepts the
+// substrate subscribe and unsubscribe methods
+func websocketHandler(server *rpc.Server) websocket.Server {
+	return websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			encode := func(v interface{}) error {
+				return websocket.JSON.Send(conn, v)
+			}
+			decode := func(v interface{}) error {
+				var msg []byte
+				err := websocket.Message.Receive(conn, &msg)
+				if err != nil {
+					return err
+				}
+				return json.Unmarshal(rewriteSubscription(msg), v)
+			}
+			server.ServeCodec(rpc.NewCodec(conn, encode, decode), rpc.OptionMethodInvocation|rpc.OptionSubscriptions)
+		},
+	}
+}
+
+// rewriteSubscription rewrites a request of a substrate subscribe or unsubscribe method into the request of the rpc
+// server, other messages are returned unchanged
+func rewriteSubscription(msg []byte) []byte {
+	var req map[string]json.RawMessage
+	err := json.Unmarshal(msg, &req)
+	if err != nil {
+		// batches are not rewritten
+		return msg
+	}
+
+	var method string
+	err = json.Unmarshal(req["method"], &method)
+	if err != nil {
+		return msg
+	}
+
+	if sub, ok := subscribeMethods[method]; ok {
+		var params []json.RawMessage
+		if len(req["params"]) > 0 {
+			err = json.Unmarshal(req["params"], &params)
+			if err != nil {
+				return msg
+			}
+		}
+		name, _ := json.Marshal(sub.name)
+		req["params"], _ = json.Marshal(append([]json.RawMessage{name}, params...))
+		req["method"], _ = json.Marshal(sub.namespace + "_subscribe")
+	} else if namespace, ok := unsubscribeMethods[method]; ok {
+		req["method"], _ = json.Marshal(namespace + "_unsubscribe")
+	} else {
+		return msg
+	}
+
+	rewritten, err := json.Marshal(req)
+	if err != nil {
+		return msg
+	}
+	return rewritten
+}